@@ -0,0 +1,315 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"alpha.com/configuration"
+	"alpha.com/internal/alpha.com/application/handler/session"
+	"alpha.com/internal/alpha.com/application/handler/user"
+	"alpha.com/internal/alpha.com/application/query"
+	"alpha.com/internal/alpha.com/application/repository"
+	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/metrics"
+	"alpha.com/internal/alpha.com/pkg/server/services"
+	"alpha.com/internal/alpha.com/pkg/validation"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// runCreateAdmin runs "alpha create-admin", it creates the user when the email
+// is unknown and grants it the admin role.
+func runCreateAdmin(args []string) int {
+	flagSet := newFlagSet("create-admin", "", "Creates an admin account, or grants the admin role to the existing user with the email.\n"+
+		"A new account gets a generated password that is printed once, unless -password-stdin is set.")
+	email := flagSet.String("email", "", "email of the admin, required")
+	firstName := flagSet.String("first-name", "Admin", "first name of a new account")
+	lastName := flagSet.String("last-name", "Admin", "last name of a new account")
+	passwordStdin := flagSet.Bool("password-stdin", false, "read the password of a new account from the first line of stdin")
+
+	config := loadConfig(flagSet, args)
+
+	if *email == "" {
+		flagSet.Usage()
+		return 2
+	}
+
+	ctx, stop := commandContext()
+	defer stop()
+
+	mongoClient, disconnect, err := connectMongo(ctx, config.Mongo)
+	if err != nil {
+		slog.Error("Cannot connect to MongoDB", "error", err)
+		return 1
+	}
+	defer disconnect()
+
+	userRepository := repository.NewUserRepository(mongoClient, config.Mongo)
+	userQueryService := query.NewUserQueryService(userRepository)
+	userCommandHandler, err := newCliUserCommandHandler(mongoClient, userRepository, config)
+	if err != nil {
+		slog.Error("Cannot create the user command handler", "error", err)
+		return 1
+	}
+
+	existingUser, err := userQueryService.GetUserByEmail(ctx, *email)
+	if err != nil && !errors.Is(err, query.ErrUserNotFound) {
+		slog.Error("User could not be read", "error", err)
+		return 1
+	}
+
+	var userID string
+
+	if existingUser != nil {
+		userID = existingUser.Id.Hex()
+		slog.Info("User already exists, its password is left unchanged", "user_id", userID)
+	} else {
+		password, generated, err := readPassword(*passwordStdin, newPasswordPolicy(config.Password), *email, *firstName, *lastName)
+		if err != nil {
+			slog.Error("Invalid password", "error", err)
+			return 2
+		}
+
+		userID, err = userCommandHandler.Save(ctx, user.Command{
+			FirstName: *firstName,
+			LastName:  *lastName,
+			Email:     *email,
+			Password:  password,
+		})
+		if err != nil {
+			slog.Error("User could not be created", "error", err)
+			return 1
+		}
+
+		if generated {
+			fmt.Printf("password: %s\n", password)
+		}
+	}
+
+	if err := userCommandHandler.GrantRole(ctx, userID, domain.RoleAdmin); err != nil {
+		slog.Error("Admin role could not be granted", "user_id", userID, "error", err)
+		return 1
+	}
+
+	slog.Info("Admin role granted", "user_id", userID)
+	fmt.Printf("admin: %s %s\n", userID, *email)
+
+	return 0
+}
+
+// runResetPassword runs "alpha reset-password". Every session of the user is
+// revoked, whoever knew the old password is signed out.
+func runResetPassword(args []string) int {
+	flagSet := newFlagSet("reset-password", "", "Sets a new password, lifts a lockout of the account and revokes every session of the user.\n"+
+		"The password is generated and printed once, unless -password-stdin is set.")
+	email := flagSet.String("email", "", "email of the user, required")
+	passwordStdin := flagSet.Bool("password-stdin", false, "read the password from the first line of stdin")
+
+	config := loadConfig(flagSet, args)
+
+	if *email == "" {
+		flagSet.Usage()
+		return 2
+	}
+
+	ctx, stop := commandContext()
+	defer stop()
+
+	mongoClient, disconnect, err := connectMongo(ctx, config.Mongo)
+	if err != nil {
+		slog.Error("Cannot connect to MongoDB", "error", err)
+		return 1
+	}
+	defer disconnect()
+
+	userRepository := repository.NewUserRepository(mongoClient, config.Mongo)
+	userQueryService := query.NewUserQueryService(userRepository)
+	userCommandHandler, err := newCliUserCommandHandler(mongoClient, userRepository, config)
+	if err != nil {
+		slog.Error("Cannot create the user command handler", "error", err)
+		return 1
+	}
+
+	sessionRepository := repository.NewSessionRepository(mongoClient, config.Mongo)
+	jwtRepository := repository.NewJwtRepository(mongoClient, config.Mongo)
+	sessionCommandHandler := session.NewCommandHandler(sessionRepository, jwtRepository, query.NewSessionQueryService(sessionRepository))
+
+	existingUser, err := userQueryService.GetUserByEmail(ctx, *email)
+	if err != nil {
+		slog.Error("User could not be read", "email", *email, "error", err)
+		return 1
+	}
+
+	userID := existingUser.Id.Hex()
+
+	password, generated, err := readPassword(*passwordStdin, newPasswordPolicy(config.Password), existingUser.Email, existingUser.FirstName, existingUser.LastName)
+	if err != nil {
+		slog.Error("Invalid password", "error", err)
+		return 2
+	}
+
+	if err := userCommandHandler.SetPassword(ctx, userID, password); err != nil {
+		slog.Error("Password could not be set", "user_id", userID, "error", err)
+		return 1
+	}
+
+	if err := sessionCommandHandler.RevokeAll(ctx, userID); err != nil {
+		slog.Error("Password was set but sessions could not be revoked", "user_id", userID, "error", err)
+		return 1
+	}
+
+	slog.Info("Password reset", "user_id", userID)
+
+	if generated {
+		fmt.Printf("password: %s\n", password)
+	}
+
+	return 0
+}
+
+// runRotateKeys runs "alpha rotate-keys", the only writer of the key
+// directory. It writes the new key there, the replicas sign with it once it
+// has been published for the activation delay. With -if-due it rotates only
+// once the latest key is older than the rotation interval, for a scheduled
+// job, and otherwise only prunes the retired keys.
+func runRotateKeys(args []string) int {
+	flagSet := newFlagSet("rotate-keys", "", "Generates a new JWT signing key in the key directory. Running replicas pick it up\n"+
+		"within the key reload interval and sign with it once every verifier knows it,\n"+
+		"tokens signed with the previous key stay valid.")
+	ifDue := flagSet.Bool("if-due", false, "rotate only when the active key is older than JWT_KEY_ROTATION_INTERVAL")
+
+	config := loadConfig(flagSet, args)
+
+	// an empty directory gets its first key from NewKeyRing already
+	existingKeys, err := filepath.Glob(filepath.Join(config.Jwt.KeysDir, "*.pem"))
+	if err != nil {
+		slog.Error("Key directory could not be read", "error", err)
+		return 1
+	}
+
+	keyRing, err := services.NewKeyRing(config.Jwt.KeysDir, keyRetention(config.Jwt), keyActivationDelay(config.Jwt), true)
+	if err != nil {
+		slog.Error("Key ring could not be loaded", "error", err)
+		return 1
+	}
+
+	latestKey, err := keyRing.LatestKey()
+	if err != nil {
+		slog.Error("Signing key could not be read", "error", err)
+		return 1
+	}
+
+	// a key still waiting for its activation is not due
+	due := !*ifDue || time.Since(latestKey.ActivatedAt) >= config.Jwt.KeyRotationInterval.Duration()
+
+	if len(existingKeys) > 0 && due {
+		err = keyRing.Rotate()
+	} else {
+		err = keyRing.Prune()
+	}
+
+	if err != nil {
+		slog.Error("Signing keys could not be rotated", "error", err)
+		return 1
+	}
+
+	signingKey, err := keyRing.SigningKey()
+	if err != nil {
+		slog.Error("Signing key could not be read", "error", err)
+		return 1
+	}
+
+	fmt.Printf("active key: %s\n", signingKey.ID)
+
+	if latestKey, err = keyRing.LatestKey(); err == nil && latestKey != signingKey {
+		fmt.Printf("next key: %s, signs from %s\n", latestKey.ID, latestKey.ActivatedAt.Format(time.RFC3339))
+	}
+
+	return 0
+}
+
+// newCliUserCommandHandler wires the user command handler without a JWT
+// service, the commands only create users and set passwords, they never
+// sign anybody in.
+func newCliUserCommandHandler(mongoClient *mongo.Client, userRepository repository.IUserRepository, config *configuration.Config) (user.ICommandHandler, error) {
+	return user.NewCommandHandler(
+		userRepository,
+		repository.NewLoginAttemptRepository(mongoClient, config.Mongo),
+		services.NewUserService(config.Password),
+		services.NewMailService(config.Mail),
+		nil,
+		config.Login,
+		config.Server.BackendURL,
+		metrics.NewBusinessMetrics(metrics.NewRegistry()),
+	)
+}
+
+// readPassword reads the password from stdin or generates one, generated
+// reports which. A password read from stdin has to pass the password policy.
+func readPassword(fromStdin bool, passwordPolicy validation.IPasswordPolicy, personalInfo ...string) (password string, generated bool, err error) {
+	if !fromStdin {
+		password, err := generatePassword()
+		return password, true, err
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", false, err
+	}
+
+	password = strings.TrimRight(line, "\r\n")
+
+	if violations := passwordPolicy.Check(password, personalInfo...); violations != nil {
+		rules := make([]string, 0, len(violations))
+		for _, violation := range violations {
+			rules = append(rules, strings.TrimSpace(violation.Tag+" "+violation.Param))
+		}
+
+		return "", false, fmt.Errorf("password does not pass the policy: %s", strings.Join(rules, ", "))
+	}
+
+	return password, false, nil
+}
+
+// generatePassword draws 24 characters with at least one of every class the
+// password policy can require.
+func generatePassword() (string, error) {
+	classes := []string{"ABCDEFGHJKLMNPQRSTUVWXYZ", "abcdefghijkmnopqrstuvwxyz", "23456789", "!#%+-=?@_"}
+	all := strings.Join(classes, "")
+
+	password := make([]byte, 0, 24)
+	for len(password) < 24 {
+		class := all
+		if len(password) < len(classes) {
+			class = classes[len(password)]
+		}
+
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(class))))
+		if err != nil {
+			return "", err
+		}
+
+		password = append(password, class[n.Int64()])
+	}
+
+	// the first characters are one per class, shuffle them in
+	for i := len(password) - 1; i > 0; i-- {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+
+		j := n.Int64()
+		password[i], password[j] = password[j], password[i]
+	}
+
+	return string(password), nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"alpha.com/configuration"
+	"alpha.com/internal/alpha.com/pkg/metrics"
+	"alpha.com/internal/alpha.com/pkg/mongodb"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type command struct {
+	name        string
+	description string
+	run         func(args []string) int
+}
+
+// commands run in the same binary as the server and are wired from the same
+// repositories and command handlers, so that data fixed from the command line
+// goes through the rules the HTTP layer applies.
+var commands = []command{
+	{name: "serve", description: "start the HTTP server", run: runServe},
+	{name: "migrate", description: "apply, revert or list database migrations", run: runMigrate},
+	{name: "seed", description: "fill the database with fake users, businesses, jobs and applications", run: runSeed},
+	{name: "create-admin", description: "create an admin, or make an existing user one", run: runCreateAdmin},
+	{name: "reset-password", description: "set a new password and sign the user out everywhere", run: runResetPassword},
+	{name: "rotate-keys", description: "generate a new JWT signing key", run: runRotateKeys},
+	{name: "export", description: "write collections to JSON lines files", run: runExport},
+	{name: "import", description: "read collections from JSON lines files", run: runImport},
+}
+
+// runCommand dispatches to the command named by the first argument. Without
+// one, or when the first argument is a flag, the server is started as it was
+// before there were commands.
+func runCommand(args []string) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runServe(args)
+	}
+
+	for _, command := range commands {
+		if command.name == args[0] {
+			return command.run(args[1:])
+		}
+	}
+
+	if args[0] != "help" {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+	}
+
+	printUsage()
+
+	if args[0] == "help" {
+		return 0
+	}
+
+	return 2
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "usage: alpha <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+
+	for _, command := range commands {
+		fmt.Fprintf(os.Stderr, "  %-16s%s\n", command.name, command.description)
+	}
+
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, `Every command takes the configuration flags as well, "alpha <command> -h" lists them.`)
+}
+
+// newFlagSet is the flag set of a command, positional describes its
+// arguments in the usage line.
+func newFlagSet(name, positional, description string) *flag.FlagSet {
+	flagSet := flag.NewFlagSet(name, flag.ContinueOnError)
+	flagSet.Usage = func() {
+		fmt.Fprintf(flagSet.Output(), "usage: alpha %s %s[flags]\n\n%s\n\nflags:\n", name, positional, description)
+		flagSet.PrintDefaults()
+	}
+
+	return flagSet
+}
+
+// commandContext is cancelled on SIGINT or SIGTERM so that a command stops
+// between two writes rather than in the middle of one.
+func commandContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// connectMongo connects the commands, which have no /metrics to report to.
+// disconnect waits a few seconds at most for the operations in progress.
+func connectMongo(ctx context.Context, mongoConfig configuration.MongoConfig) (client *mongo.Client, disconnect func(), err error) {
+	client, err = mongodb.ConnectMongoDB(ctx, mongoConfig, metrics.NewRegistry())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	disconnect = func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if err := mongodb.DisconnectMongoDB(ctx, client); err != nil {
+			slog.Error("DisconnectMongoDB failed", "error", err)
+		}
+	}
+
+	return client, disconnect, nil
+}
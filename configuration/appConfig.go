@@ -1,7 +1,308 @@
 package configuration
 
-// Server
-var Env = "default"
-var Port = "8080"
+// Config is the whole application configuration. Load fills it from Default,
+// then a YAML file, then environment variables, then command line flags.
+//
+// Fields are addressed by their yaml key in the file, by the env tag as an
+// environment variable and by the env tag in lower case with dashes as a
+// flag, e.g. MONGO_URI and -mongo-uri. Fields tagged secret are redacted when
+// the configuration is printed.
+type Config struct {
+	Env       string          `yaml:"env" env:"ENV"`
+	Log       LogConfig       `yaml:"log"`
+	Server    ServerConfig    `yaml:"server"`
+	Mongo     MongoConfig     `yaml:"mongo"`
+	Jwt       JwtConfig       `yaml:"jwt"`
+	Password  PasswordConfig  `yaml:"password"`
+	Login     LoginConfig     `yaml:"login"`
+	Mfa       MfaConfig       `yaml:"mfa"`
+	MagicLink MagicLinkConfig `yaml:"magicLink"`
+	Oidc      OidcConfig      `yaml:"oidc"`
+	Mail      MailConfig      `yaml:"mail"`
+	RateLimit RateLimitConfig `yaml:"rateLimit"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+}
 
-var BACKEND_URL = "http://localhost:8080"
+// LogConfig, Format is "text" for humans or "json" for log collectors and
+// Level one of debug, info, warn or error.
+type LogConfig struct {
+	Format string `yaml:"format" env:"LOG_FORMAT"`
+	Level  string `yaml:"level" env:"LOG_LEVEL"`
+}
+
+// ServerConfig, ReadinessTimeout bounds the dependency checks of /readyz and
+// ShutdownDelay is how long /readyz fails before the server stops accepting
+// requests on shutdown, it should exceed the readiness probe period.
+// ShutdownTimeout is the deadline of the whole shutdown, delay included, past
+// which components are stopped by force. It must be shorter than the grace
+// period of the orchestrator. RequestTimeout is the deadline of a request,
+// past it the queries still running are cancelled and it fails with 504.
+//
+// Behind a load balancer the client address is read from ProxyHeader, but
+// only on requests whose peer is one of TrustedProxies, addresses or CIDR
+// ranges. The proxies must set the header to the address they were connected
+// from rather than append to one the client sent, the first address of the
+// header is taken. Without ProxyHeader the peer is the client.
+//
+// /metrics is served on MetricsPort only, a port for the scraper that must
+// not be exposed through the load balancer.
+type ServerConfig struct {
+	Port             string   `yaml:"port" env:"PORT"`
+	MetricsPort      string   `yaml:"metricsPort" env:"METRICS_PORT"`
+	BackendURL       string   `yaml:"backendUrl" env:"BACKEND_URL"`
+	ReadinessTimeout Duration `yaml:"readinessTimeout" env:"READINESS_TIMEOUT"`
+	ShutdownDelay    Duration `yaml:"shutdownDelay" env:"SHUTDOWN_DELAY"`
+	ShutdownTimeout  Duration `yaml:"shutdownTimeout" env:"SHUTDOWN_TIMEOUT"`
+	RequestTimeout   Duration `yaml:"requestTimeout" env:"REQUEST_TIMEOUT"`
+	ProxyHeader      string   `yaml:"proxyHeader" env:"PROXY_HEADER"`
+	TrustedProxies   List     `yaml:"trustedProxies" env:"TRUSTED_PROXIES"`
+}
+
+// MongoConfig, with MigrateOnStartup the server applies pending migrations
+// itself, otherwise /readyz fails until "migrate up" has been run.
+// OperationTimeout bounds every repository call, within the deadline of the
+// request it is made for. ConnectTimeout bounds opening a connection and the
+// wait for MongoDB at startup.
+type MongoConfig struct {
+	URI              string           `yaml:"uri" env:"MONGO_URI" secret:"true"`
+	Database         string           `yaml:"database" env:"MONGO_DB_NAME"`
+	MigrateOnStartup bool             `yaml:"migrateOnStartup" env:"MONGO_MIGRATE_ON_STARTUP"`
+	OperationTimeout Duration         `yaml:"operationTimeout" env:"MONGO_OPERATION_TIMEOUT"`
+	ConnectTimeout   Duration         `yaml:"connectTimeout" env:"MONGO_CONNECT_TIMEOUT"`
+	Collections      MongoCollections `yaml:"collections"`
+}
+
+type MongoCollections struct {
+	Users              string `yaml:"users" env:"MONGO_USERS_DB_NAME"`
+	Jwts               string `yaml:"jwts" env:"MONGO_JWT_DB_NAME"`
+	BusinessAccounts   string `yaml:"businessAccounts" env:"MONGO_BUSINESS_ACCOUNT_DB_NAME"`
+	Jobs               string `yaml:"jobs" env:"MONGO_JOBS_DB_NAME"`
+	JobApplies         string `yaml:"jobApplies" env:"MONGO_JOB_APPLIES_DB_NAME"`
+	Reports            string `yaml:"reports" env:"MONGO_REPORTS_DB_NAME"`
+	AdminActions       string `yaml:"adminActions" env:"MONGO_ADMIN_ACTIONS_DB_NAME"`
+	Sessions           string `yaml:"sessions" env:"MONGO_SESSIONS_DB_NAME"`
+	LoginAttempts      string `yaml:"loginAttempts" env:"MONGO_LOGIN_ATTEMPTS_DB_NAME"`
+	MagicLinks         string `yaml:"magicLinks" env:"MONGO_MAGIC_LINKS_DB_NAME"`
+	ExternalIdentities string `yaml:"externalIdentities" env:"MONGO_EXTERNAL_IDENTITIES_DB_NAME"`
+	OidcStates         string `yaml:"oidcStates" env:"MONGO_OIDC_STATES_DB_NAME"`
+	ApiKeys            string `yaml:"apiKeys" env:"MONGO_API_KEYS_DB_NAME"`
+	RateLimits         string `yaml:"rateLimits" env:"MONGO_RATE_LIMITS_DB_NAME"`
+	Migrations         string `yaml:"migrations" env:"MONGO_MIGRATIONS_DB_NAME"`
+}
+
+// JwtConfig, refresh tokens are signed by the key ring as well so retired keys
+// are kept for RefreshTokenTime plus KeyReloadInterval. KeysDir is shared by
+// all replicas, KeyRotationInterval is the age at which "rotate-keys -if-due"
+// replaces the active key. A new key only signs after KeyReloadInterval plus
+// the max-age of the published key set.
+type JwtConfig struct {
+	AccessTokenTime     Duration `yaml:"accessTokenTime" env:"ACCESS_TOKEN_TIME"`
+	RefreshTokenTime    Duration `yaml:"refreshTokenTime" env:"REFRESH_TOKEN_TIME"`
+	KeysDir             string   `yaml:"keysDir" env:"JWT_KEYS_DIR"`
+	KeyRotationInterval Duration `yaml:"keyRotationInterval" env:"JWT_KEY_ROTATION_INTERVAL"`
+	KeyReloadInterval   Duration `yaml:"keyReloadInterval" env:"JWT_KEY_RELOAD_INTERVAL"`
+	Issuer              string   `yaml:"issuer" env:"JWT_ISSUER"`
+	Audience            string   `yaml:"audience" env:"JWT_AUDIENCE"`
+	ClockSkew           Duration `yaml:"clockSkew" env:"JWT_CLOCK_SKEW"`
+}
+
+// PasswordConfig, argon2id memory is in KiB. Stored hashes made with other
+// parameters, or with bcrypt, are rehashed on the next successful sign-in.
+// BreachedDir holds the SHA-1 prefix files of the breached-password list,
+// empty turns the check off.
+type PasswordConfig struct {
+	Argon2Memory       uint32 `yaml:"argon2Memory" env:"PASSWORD_ARGON2_MEMORY"`
+	Argon2Time         uint32 `yaml:"argon2Time" env:"PASSWORD_ARGON2_TIME"`
+	Argon2Threads      uint8  `yaml:"argon2Threads" env:"PASSWORD_ARGON2_THREADS"`
+	MinLength          int    `yaml:"minLength" env:"PASSWORD_MIN_LENGTH"`
+	RequireUppercase   bool   `yaml:"requireUppercase" env:"PASSWORD_REQUIRE_UPPERCASE"`
+	RequireLowercase   bool   `yaml:"requireLowercase" env:"PASSWORD_REQUIRE_LOWERCASE"`
+	RequireDigit       bool   `yaml:"requireDigit" env:"PASSWORD_REQUIRE_DIGIT"`
+	RequireSymbol      bool   `yaml:"requireSymbol" env:"PASSWORD_REQUIRE_SYMBOL"`
+	ForbidPersonalInfo bool   `yaml:"forbidPersonalInfo" env:"PASSWORD_FORBID_PERSONAL_INFO"`
+	BreachedDir        string `yaml:"breachedDir" env:"PASSWORD_BREACHED_DIR"`
+}
+
+type LoginConfig struct {
+	FreeAttempts       int      `yaml:"freeAttempts" env:"LOGIN_FREE_ATTEMPTS"`
+	BackoffBase        Duration `yaml:"backoffBase" env:"LOGIN_BACKOFF_BASE"`
+	BackoffMax         Duration `yaml:"backoffMax" env:"LOGIN_BACKOFF_MAX"`
+	MaxAccountFailures int      `yaml:"maxAccountFailures" env:"LOGIN_MAX_ACCOUNT_FAILURES"`
+	MaxIPFailures      int      `yaml:"maxIpFailures" env:"LOGIN_MAX_IP_FAILURES"`
+	LockoutTime        Duration `yaml:"lockoutTime" env:"LOGIN_LOCKOUT_TIME"`
+	AttemptWindow      Duration `yaml:"attemptWindow" env:"LOGIN_ATTEMPT_WINDOW"`
+}
+
+// MfaConfig, EncryptionKey is the base64 encoded 32 byte key TOTP secrets are
+// encrypted with.
+type MfaConfig struct {
+	Issuer            string   `yaml:"issuer" env:"MFA_ISSUER"`
+	EncryptionKey     string   `yaml:"encryptionKey" env:"MFA_ENCRYPTION_KEY" secret:"true"`
+	ChallengeTime     Duration `yaml:"challengeTime" env:"MFA_CHALLENGE_TIME"`
+	RecoveryCodeCount int      `yaml:"recoveryCodeCount" env:"MFA_RECOVERY_CODE_COUNT"`
+	MaxFailures       int      `yaml:"maxFailures" env:"MFA_MAX_FAILURES"`
+}
+
+type MagicLinkConfig struct {
+	Time         Duration `yaml:"time" env:"MAGIC_LINK_TIME"`
+	DeviceCookie string   `yaml:"deviceCookie" env:"MAGIC_LINK_DEVICE_COOKIE"`
+}
+
+// OidcConfig, providers are keyed by the name used in /auth/oidc/:provider and
+// can only be set in the YAML file. No provider is configured by default.
+type OidcConfig struct {
+	Providers   map[string]OidcProvider `yaml:"providers"`
+	StateTime   Duration                `yaml:"stateTime" env:"OIDC_STATE_TIME"`
+	StateCookie string                  `yaml:"stateCookie" env:"OIDC_STATE_COOKIE"`
+}
+
+type OidcProvider struct {
+	IssuerURL    string   `yaml:"issuerUrl"`
+	ClientID     string   `yaml:"clientId"`
+	ClientSecret string   `yaml:"clientSecret" secret:"true"`
+	Scopes       []string `yaml:"scopes"`
+}
+
+// MailConfig, messages are only logged when SmtpAddr is empty.
+type MailConfig struct {
+	SmtpAddr     string `yaml:"smtpAddr" env:"SMTP_ADDR"`
+	SmtpUsername string `yaml:"smtpUsername" env:"SMTP_USERNAME"`
+	SmtpPassword string `yaml:"smtpPassword" env:"SMTP_PASSWORD" secret:"true"`
+	From         string `yaml:"from" env:"MAIL_FROM"`
+}
+
+// RateLimitConfig, Store is "memory" for a single replica or "mongo" to share
+// the buckets across replicas. Policies can only be set in the YAML file.
+type RateLimitConfig struct {
+	Store    string                     `yaml:"store" env:"RATE_LIMIT_STORE"`
+	Policies map[string]RateLimitPolicy `yaml:"policies"`
+}
+
+// RateLimitPolicy allows Capacity requests per Period to every client, counted
+// per "ip", "user" or "apiKey".
+type RateLimitPolicy struct {
+	Capacity int      `yaml:"capacity"`
+	Period   Duration `yaml:"period"`
+	KeyBy    string   `yaml:"keyBy"`
+}
+
+// TracingConfig, Exporter is "none", "stdout" to print spans as JSON lines or
+// "otlp" to post them to an OpenTelemetry collector at OtlpEndpoint.
+// SampleRatio is the share of new traces recorded, traces continued from a
+// traceparent header keep the caller's decision.
+type TracingConfig struct {
+	Exporter     string  `yaml:"exporter" env:"TRACING_EXPORTER"`
+	OtlpEndpoint string  `yaml:"otlpEndpoint" env:"TRACING_OTLP_ENDPOINT"`
+	ServiceName  string  `yaml:"serviceName" env:"TRACING_SERVICE_NAME"`
+	SampleRatio  float64 `yaml:"sampleRatio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+// Default is the configuration of a local development setup. Secrets have no
+// default and must be provided.
+func Default() Config {
+	return Config{
+		Env: "default",
+		Log: LogConfig{
+			Format: "text",
+			Level:  "info",
+		},
+		Server: ServerConfig{
+			Port:             "8080",
+			MetricsPort:      "9090",
+			BackendURL:       "http://localhost:8080",
+			ReadinessTimeout: Seconds(2),
+			ShutdownDelay:    Seconds(0),
+			ShutdownTimeout:  Seconds(30),
+			RequestTimeout:   Seconds(15),
+		},
+		Mongo: MongoConfig{
+			URI:              "mongodb://localhost:27017",
+			Database:         "alpha",
+			MigrateOnStartup: true,
+			OperationTimeout: Seconds(5),
+			ConnectTimeout:   Seconds(5),
+			Collections: MongoCollections{
+				Users:              "users",
+				Jwts:               "jwts",
+				BusinessAccounts:   "businessAccounts",
+				Jobs:               "jobs",
+				JobApplies:         "jobApplies",
+				Reports:            "reports",
+				AdminActions:       "adminActions",
+				Sessions:           "sessions",
+				LoginAttempts:      "loginAttempts",
+				MagicLinks:         "magicLinks",
+				ExternalIdentities: "externalIdentities",
+				OidcStates:         "oidcStates",
+				ApiKeys:            "apiKeys",
+				RateLimits:         "rateLimits",
+				Migrations:         "migrations",
+			},
+		},
+		Jwt: JwtConfig{
+			AccessTokenTime:     Minutes(15),
+			RefreshTokenTime:    Days(7),
+			KeysDir:             "keys",
+			KeyRotationInterval: Days(30),
+			KeyReloadInterval:   Minutes(5),
+			Issuer:              "alpha",
+			Audience:            "alpha-api",
+			ClockSkew:           Seconds(30),
+		},
+		Password: PasswordConfig{
+			Argon2Memory:       64 * 1024,
+			Argon2Time:         3,
+			Argon2Threads:      2,
+			MinLength:          8,
+			RequireUppercase:   true,
+			RequireLowercase:   true,
+			RequireDigit:       true,
+			RequireSymbol:      false,
+			ForbidPersonalInfo: true,
+			BreachedDir:        "breached-passwords",
+		},
+		Login: LoginConfig{
+			FreeAttempts:       3,
+			BackoffBase:        Seconds(1),
+			BackoffMax:         Seconds(30),
+			MaxAccountFailures: 10,
+			MaxIPFailures:      50,
+			LockoutTime:        Minutes(15),
+			AttemptWindow:      Hours(1),
+		},
+		Mfa: MfaConfig{
+			Issuer:            "Alpha",
+			ChallengeTime:     Minutes(5),
+			RecoveryCodeCount: 10,
+			MaxFailures:       5,
+		},
+		MagicLink: MagicLinkConfig{
+			Time:         Minutes(15),
+			DeviceCookie: "magic_link_device",
+		},
+		Oidc: OidcConfig{
+			Providers:   map[string]OidcProvider{},
+			StateTime:   Minutes(10),
+			StateCookie: "oidc_state",
+		},
+		Mail: MailConfig{
+			From: "no-reply@alpha.com",
+		},
+		RateLimit: RateLimitConfig{
+			Store: "memory",
+			Policies: map[string]RateLimitPolicy{
+				"sign-up": {Capacity: 5, Period: Hours(1), KeyBy: "ip"},
+				"sign-in": {Capacity: 10, Period: Minutes(1), KeyBy: "ip"},
+				"apply":   {Capacity: 30, Period: Hours(1), KeyBy: "user"},
+				"search":  {Capacity: 60, Period: Minutes(1), KeyBy: "ip"},
+				"api":     {Capacity: 120, Period: Minutes(1), KeyBy: "apiKey"},
+			},
+		},
+		Tracing: TracingConfig{
+			Exporter:     "none",
+			OtlpEndpoint: "http://localhost:4318",
+			ServiceName:  "alpha",
+			SampleRatio:  1,
+		},
+	}
+}
@@ -0,0 +1,88 @@
+package configuration
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration is written as a number followed by one of s, m, h or d, e.g. "15m"
+// or "7d", in the YAML file, environment variables and flags.
+type Duration time.Duration
+
+func Seconds(n int) Duration { return Duration(time.Duration(n) * time.Second) }
+func Minutes(n int) Duration { return Duration(time.Duration(n) * time.Minute) }
+func Hours(n int) Duration   { return Duration(time.Duration(n) * time.Hour) }
+func Days(n int) Duration    { return Duration(time.Duration(n) * 24 * time.Hour) }
+
+func (d Duration) Duration() time.Duration {
+	return time.Duration(d)
+}
+
+func (d Duration) String() string {
+	duration := time.Duration(d)
+
+	for _, unit := range []struct {
+		suffix string
+		size   time.Duration
+	}{
+		{"d", 24 * time.Hour},
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+	} {
+		if duration != 0 && duration%unit.size == 0 {
+			return fmt.Sprintf("%d%s", duration/unit.size, unit.suffix)
+		}
+	}
+
+	return duration.String()
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(duration)
+	return nil
+}
+
+// ParseDuration accepts the configuration format, a number followed by one of
+// s, m, h or d.
+func ParseDuration(durationStr string) (time.Duration, error) {
+	var duration time.Duration
+
+	if len(durationStr) < 2 {
+		return duration, fmt.Errorf("invalid duration format: %q", durationStr)
+	}
+
+	numStr := durationStr[:len(durationStr)-1]
+	unitStr := strings.ToLower(string(durationStr[len(durationStr)-1]))
+
+	num, err := strconv.Atoi(numStr)
+	if err != nil {
+		return duration, fmt.Errorf("invalid duration format: %v", err)
+	}
+
+	switch unitStr {
+	case "s":
+		duration = time.Duration(num) * time.Second
+	case "m":
+		duration = time.Duration(num) * time.Minute
+	case "h":
+		duration = time.Duration(num) * time.Hour
+	case "d":
+		duration = time.Duration(num) * 24 * time.Hour
+	default:
+		return duration, fmt.Errorf("unknown duration unit: %v", unitStr)
+	}
+
+	return duration, nil
+}
@@ -0,0 +1,23 @@
+package configuration
+
+import "strings"
+
+// List is written as a YAML sequence in the file and comma separated in
+// environment variables and flags, e.g. "10.0.0.0/8,192.168.0.1".
+type List []string
+
+func (l List) MarshalText() ([]byte, error) {
+	return []byte(strings.Join(l, ",")), nil
+}
+
+func (l *List) UnmarshalText(text []byte) error {
+	*l = nil
+
+	for _, item := range strings.Split(string(text), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,235 @@
+package configuration
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv names the YAML file when the -config flag is not given.
+const ConfigFileEnv = "CONFIG_FILE"
+
+const redacted = "[REDACTED]"
+
+// Load builds the configuration from Default, the YAML file, environment
+// variables and the flags in args, each overriding the one before, and
+// validates the result.
+func Load(args []string) (*Config, error) {
+	return LoadFlags(flag.NewFlagSet("alpha", flag.ContinueOnError), args)
+}
+
+// LoadFlags is Load for subcommands, flagSet may hold flags of their own that
+// are parsed along with the configuration flags.
+func LoadFlags(flagSet *flag.FlagSet, args []string) (*Config, error) {
+	config := Default()
+
+	configFile := flagSet.String("config", os.Getenv(ConfigFileEnv), "path of the YAML configuration file")
+
+	flagValues := map[string]*string{}
+	walkFields(reflect.ValueOf(&config).Elem(), func(field reflect.StructField, _ reflect.Value) {
+		name := flagName(field.Tag.Get("env"))
+		flagValues[name] = flagSet.String(name, "", "overrides "+field.Tag.Get("env"))
+	})
+
+	if err := flagSet.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *configFile != "" {
+		if err := loadFile(&config, *configFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := loadEnv(&config); err != nil {
+		return nil, err
+	}
+
+	var flagErrs []error
+	flagSet.Visit(func(f *flag.Flag) {
+		walkFields(reflect.ValueOf(&config).Elem(), func(field reflect.StructField, value reflect.Value) {
+			if flagName(field.Tag.Get("env")) != f.Name {
+				return
+			}
+
+			if err := setFromString(value, *flagValues[f.Name]); err != nil {
+				flagErrs = append(flagErrs, fmt.Errorf("flag -%s: %w", f.Name, err))
+			}
+		})
+	})
+
+	if err := errors.Join(flagErrs...); err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+func loadFile(config *Config, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot read configuration file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(config); err != nil {
+		return fmt.Errorf("cannot parse configuration file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func loadEnv(config *Config) error {
+	var errs []error
+
+	walkFields(reflect.ValueOf(config).Elem(), func(field reflect.StructField, value reflect.Value) {
+		envName := field.Tag.Get("env")
+
+		envValue, ok := os.LookupEnv(envName)
+		if !ok {
+			return
+		}
+
+		if err := setFromString(value, envValue); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", envName, err))
+		}
+	})
+
+	return errors.Join(errs...)
+}
+
+// walkFields calls fn for every field with an env tag, descending into nested
+// structs.
+func walkFields(value reflect.Value, fn func(field reflect.StructField, value reflect.Value)) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		fieldValue := value.Field(i)
+
+		if field.Tag.Get("env") != "" {
+			fn(field, fieldValue)
+			continue
+		}
+
+		if fieldValue.Kind() == reflect.Struct {
+			walkFields(fieldValue, fn)
+		}
+	}
+}
+
+func flagName(envName string) string {
+	return strings.ReplaceAll(strings.ToLower(envName), "_", "-")
+}
+
+func setFromString(value reflect.Value, s string) error {
+	if unmarshaler, ok := value.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(s))
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", s)
+		}
+		value.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.ParseInt(s, 10, 0)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", s)
+		}
+		value.SetInt(n)
+	case reflect.Uint8, reflect.Uint32:
+		n, err := strconv.ParseUint(s, 10, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", s)
+		}
+		value.SetUint(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+		value.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", value.Type())
+	}
+
+	return nil
+}
+
+// Redacted returns a copy with every secret field that is set replaced, it is
+// what may be logged.
+func (c Config) Redacted() Config {
+	copied := reflect.New(reflect.TypeOf(c)).Elem()
+	copied.Set(redactValue(reflect.ValueOf(c), false))
+
+	return copied.Interface().(Config)
+}
+
+// LogValue makes slog log the redacted copy, the JSON handler would marshal
+// the fields as they are otherwise.
+func (c Config) LogValue() slog.Value {
+	return slog.AnyValue(loggedConfig(c.Redacted()))
+}
+
+// loggedConfig is Config without its methods, logging it does not call
+// LogValue again.
+type loggedConfig Config
+
+func (c Config) String() string {
+	content, err := yaml.Marshal(c.Redacted())
+	if err != nil {
+		return err.Error()
+	}
+
+	return string(content)
+}
+
+func redactValue(value reflect.Value, secret bool) reflect.Value {
+	switch value.Kind() {
+	case reflect.Struct:
+		copied := reflect.New(value.Type()).Elem()
+		copied.Set(value)
+
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			copied.Field(i).Set(redactValue(value.Field(i), field.Tag.Get("secret") == "true"))
+		}
+
+		return copied
+	case reflect.Map:
+		if value.IsNil() {
+			return value
+		}
+
+		copied := reflect.MakeMapWithSize(value.Type(), value.Len())
+		for _, key := range value.MapKeys() {
+			copied.SetMapIndex(key, redactValue(value.MapIndex(key), secret))
+		}
+
+		return copied
+	case reflect.String:
+		if secret && value.String() != "" {
+			return reflect.ValueOf(redacted).Convert(value.Type())
+		}
+	}
+
+	return value
+}
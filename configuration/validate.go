@@ -0,0 +1,219 @@
+package configuration
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ValidationError lists every invalid setting so that a deployment can be
+// fixed in one go.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Validate checks the values that would otherwise fail later, or worse, not
+// fail at all.
+func (c *Config) Validate() error {
+	var problems []string
+
+	problem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if c.Env == "" {
+		problem("ENV is required")
+	}
+
+	if c.Log.Format != "text" && c.Log.Format != "json" {
+		problem("LOG_FORMAT must be text or json, got %q", c.Log.Format)
+	}
+
+	switch strings.ToLower(c.Log.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		problem("LOG_LEVEL must be debug, info, warn or error, got %q", c.Log.Level)
+	}
+
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		problem("PORT must be a number between 1 and 65535, got %q", c.Server.Port)
+	}
+
+	if port, err := strconv.Atoi(c.Server.MetricsPort); err != nil || port < 1 || port > 65535 {
+		problem("METRICS_PORT must be a number between 1 and 65535, got %q", c.Server.MetricsPort)
+	} else if c.Server.MetricsPort == c.Server.Port {
+		problem("METRICS_PORT must differ from PORT, /metrics must not be public")
+	}
+
+	if backendURL, err := url.Parse(c.Server.BackendURL); err != nil || backendURL.Scheme == "" || backendURL.Host == "" {
+		problem("BACKEND_URL must be an absolute url, got %q", c.Server.BackendURL)
+	}
+
+	if c.Server.ProxyHeader != "" && len(c.Server.TrustedProxies) == 0 {
+		problem("TRUSTED_PROXIES is required when PROXY_HEADER is set")
+	}
+
+	for _, proxy := range c.Server.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			problem("TRUSTED_PROXIES must hold addresses or CIDR ranges, got %q", proxy)
+		}
+	}
+
+	if !strings.HasPrefix(c.Mongo.URI, "mongodb://") && !strings.HasPrefix(c.Mongo.URI, "mongodb+srv://") {
+		problem("MONGO_URI must start with mongodb:// or mongodb+srv://")
+	}
+
+	if c.Mongo.Database == "" {
+		problem("MONGO_DB_NAME is required")
+	}
+
+	collections := reflect.ValueOf(c.Mongo.Collections)
+	for i := 0; i < collections.NumField(); i++ {
+		if collections.Field(i).String() == "" {
+			problem("%s is required", collections.Type().Field(i).Tag.Get("env"))
+		}
+	}
+
+	for _, setting := range []struct {
+		name     string
+		duration Duration
+	}{
+		{"READINESS_TIMEOUT", c.Server.ReadinessTimeout},
+		{"SHUTDOWN_TIMEOUT", c.Server.ShutdownTimeout},
+		{"REQUEST_TIMEOUT", c.Server.RequestTimeout},
+		{"MONGO_OPERATION_TIMEOUT", c.Mongo.OperationTimeout},
+		{"MONGO_CONNECT_TIMEOUT", c.Mongo.ConnectTimeout},
+		{"ACCESS_TOKEN_TIME", c.Jwt.AccessTokenTime},
+		{"REFRESH_TOKEN_TIME", c.Jwt.RefreshTokenTime},
+		{"JWT_KEY_ROTATION_INTERVAL", c.Jwt.KeyRotationInterval},
+		{"JWT_KEY_RELOAD_INTERVAL", c.Jwt.KeyReloadInterval},
+		{"LOGIN_BACKOFF_BASE", c.Login.BackoffBase},
+		{"LOGIN_BACKOFF_MAX", c.Login.BackoffMax},
+		{"LOGIN_LOCKOUT_TIME", c.Login.LockoutTime},
+		{"LOGIN_ATTEMPT_WINDOW", c.Login.AttemptWindow},
+		{"MFA_CHALLENGE_TIME", c.Mfa.ChallengeTime},
+		{"MAGIC_LINK_TIME", c.MagicLink.Time},
+		{"OIDC_STATE_TIME", c.Oidc.StateTime},
+	} {
+		if setting.duration <= 0 {
+			problem("%s must be positive", setting.name)
+		}
+	}
+
+	if c.Server.ShutdownDelay < 0 || c.Server.ShutdownDelay >= c.Server.ShutdownTimeout {
+		problem("SHUTDOWN_DELAY must not be negative and must be shorter than SHUTDOWN_TIMEOUT")
+	}
+
+	if c.Jwt.ClockSkew < 0 {
+		problem("JWT_CLOCK_SKEW must not be negative")
+	}
+
+	if c.Jwt.KeysDir == "" {
+		problem("JWT_KEYS_DIR is required")
+	}
+
+	if c.Jwt.Issuer == "" || c.Jwt.Audience == "" {
+		problem("JWT_ISSUER and JWT_AUDIENCE are required")
+	}
+
+	if c.Password.Argon2Memory < 8*uint32(c.Password.Argon2Threads) || c.Password.Argon2Time < 1 || c.Password.Argon2Threads < 1 {
+		problem("PASSWORD_ARGON2_TIME and PASSWORD_ARGON2_THREADS must be at least 1 and PASSWORD_ARGON2_MEMORY at least 8 KiB per thread")
+	}
+
+	if c.Password.MinLength < 1 {
+		problem("PASSWORD_MIN_LENGTH must be at least 1")
+	}
+
+	if c.Login.FreeAttempts < 0 || c.Login.MaxAccountFailures < 1 || c.Login.MaxIPFailures < 1 {
+		problem("LOGIN_FREE_ATTEMPTS must not be negative, LOGIN_MAX_ACCOUNT_FAILURES and LOGIN_MAX_IP_FAILURES must be at least 1")
+	}
+
+	if key, err := base64.StdEncoding.DecodeString(c.Mfa.EncryptionKey); c.Mfa.EncryptionKey == "" {
+		problem("MFA_ENCRYPTION_KEY is required")
+	} else if err != nil || len(key) != 32 {
+		problem("MFA_ENCRYPTION_KEY must be a base64 encoded 32 byte key")
+	}
+
+	if c.Mfa.RecoveryCodeCount < 1 || c.Mfa.MaxFailures < 1 {
+		problem("MFA_RECOVERY_CODE_COUNT and MFA_MAX_FAILURES must be at least 1")
+	}
+
+	if c.MagicLink.DeviceCookie == "" {
+		problem("MAGIC_LINK_DEVICE_COOKIE is required")
+	}
+
+	if c.Oidc.StateCookie == "" {
+		problem("OIDC_STATE_COOKIE is required")
+	}
+
+	for _, name := range sortedKeys(c.Oidc.Providers) {
+		provider := c.Oidc.Providers[name]
+
+		if provider.IssuerURL == "" || provider.ClientID == "" {
+			problem("oidc provider %s needs issuerUrl and clientId", name)
+		}
+	}
+
+	if c.Mail.SmtpAddr != "" && c.Mail.From == "" {
+		problem("MAIL_FROM is required when SMTP_ADDR is set")
+	}
+
+	if c.RateLimit.Store != "memory" && c.RateLimit.Store != "mongo" {
+		problem("RATE_LIMIT_STORE must be memory or mongo, got %q", c.RateLimit.Store)
+	}
+
+	for _, name := range sortedKeys(c.RateLimit.Policies) {
+		policy := c.RateLimit.Policies[name]
+
+		if policy.Capacity < 1 || policy.Period <= 0 {
+			problem("rate limit policy %s needs a positive capacity and period", name)
+		}
+
+		if policy.KeyBy != "ip" && policy.KeyBy != "user" && policy.KeyBy != "apiKey" {
+			problem("rate limit policy %s must be keyed by ip, user or apiKey, got %q", name, policy.KeyBy)
+		}
+	}
+
+	switch c.Tracing.Exporter {
+	case "none", "stdout":
+	case "otlp":
+		if endpoint, err := url.Parse(c.Tracing.OtlpEndpoint); err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+			problem("TRACING_OTLP_ENDPOINT must be an absolute url, got %q", c.Tracing.OtlpEndpoint)
+		}
+	default:
+		problem("TRACING_EXPORTER must be none, stdout or otlp, got %q", c.Tracing.Exporter)
+	}
+
+	if c.Tracing.ServiceName == "" {
+		problem("TRACING_SERVICE_NAME is required")
+	}
+
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		problem("TRACING_SAMPLE_RATIO must be between 0 and 1, got %v", c.Tracing.SampleRatio)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -288,43 +288,6 @@ const docTemplate = `{
                         "description": "Internal Server Error"
                     }
                 }
-            },
-            "post": {
-                "description": "saving new jwt",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "JWT"
-                ],
-                "summary": "This method used for saving new jwt",
-                "parameters": [
-                    {
-                        "description": "Handle Request Body",
-                        "name": "requestBody",
-                        "in": "body",
-                        "schema": {
-                            "$ref": "#/definitions/request.JwtCreateRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK"
-                    },
-                    "400": {
-                        "description": "Bad Request"
-                    },
-                    "404": {
-                        "description": "Not Found"
-                    },
-                    "500": {
-                        "description": "Internal Server Error"
-                    }
-                }
             }
         },
         "/api/v1/alpha/jwt/refresh": {
@@ -585,14 +548,6 @@ const docTemplate = `{
                 }
             }
         },
-        "request.JwtCreateRequest": {
-            "type": "object",
-            "properties": {
-                "userID": {
-                    "type": "string"
-                }
-            }
-        },
         "request.UserCreateRequest": {
             "type": "object",
             "required": [
@@ -618,8 +573,7 @@ const docTemplate = `{
                 },
                 "password": {
                     "type": "string",
-                    "maxLength": 16,
-                    "minLength": 8
+                    "maxLength": 128
                 }
             }
         },
@@ -635,8 +589,7 @@ const docTemplate = `{
                 },
                 "password": {
                     "type": "string",
-                    "maxLength": 16,
-                    "minLength": 8
+                    "maxLength": 128
                 }
             }
         },
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"alpha.com/configuration"
+	"alpha.com/internal/alpha.com/application/repository"
+	"alpha.com/internal/alpha.com/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// maxDumpLine bounds a document of a dump file, Mongo documents are 16 MiB
+// at most and their extended JSON is a little larger.
+const maxDumpLine = 32 * 1024 * 1024
+
+// dataSet is a collection that can be exported and imported. Documents are
+// written as canonical extended JSON, one per line, so that ids, dates and
+// number types survive the round trip and a dump can be fixed in an editor.
+type dataSet struct {
+	name    string
+	export  func(ctx context.Context) ([]any, error)
+	insert  func(ctx context.Context, line []byte) error
+	replace func(ctx context.Context, line []byte) error
+}
+
+func newDataSet[T any](name string,
+	get func(ctx context.Context) ([]*T, error),
+	insert func(ctx context.Context, document *T) error,
+	replace func(ctx context.Context, document *T) error,
+) dataSet {
+	decode := func(line []byte, write func(ctx context.Context, document *T) error) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			var document T
+			if err := bson.UnmarshalExtJSON(line, true, &document); err != nil {
+				return err
+			}
+
+			return write(ctx, &document)
+		}
+	}
+
+	return dataSet{
+		name: name,
+		export: func(ctx context.Context) ([]any, error) {
+			documents, err := get(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			result := make([]any, 0, len(documents))
+			for _, document := range documents {
+				result = append(result, document)
+			}
+
+			return result, nil
+		},
+		insert: func(ctx context.Context, line []byte) error {
+			return decode(line, insert)(ctx)
+		},
+		replace: func(ctx context.Context, line []byte) error {
+			return decode(line, replace)(ctx)
+		},
+	}
+}
+
+// newDataSets lists what export and import cover, through the repositories
+// the HTTP layer uses. Tokens, sessions and other short lived documents are
+// left out, they are worthless on another database.
+func newDataSets(mongoClient *mongo.Client, mongoConfig configuration.MongoConfig) []dataSet {
+	userRepository := repository.NewUserRepository(mongoClient, mongoConfig)
+	businessAccountRepository := repository.NewBusinessAccountRepository(mongoClient, mongoConfig)
+	jobRepository := repository.NewJobRepository(mongoClient, mongoConfig)
+	jobApplyRepository := repository.NewJobApplyRepository(mongoClient, mongoConfig)
+
+	return []dataSet{
+		newDataSet("users", userRepository.Get, func(ctx context.Context, user *domain.User) error {
+			_, err := userRepository.Upsert(ctx, user)
+			return err
+		}, userRepository.Replace),
+		newDataSet("businessAccounts", businessAccountRepository.Get, businessAccountRepository.Upsert, businessAccountRepository.Replace),
+		newDataSet("jobs", jobRepository.Get, jobRepository.Upsert, jobRepository.Replace),
+		newDataSet("jobApplies", jobApplyRepository.Get, jobApplyRepository.Upsert, jobApplyRepository.Replace),
+	}
+}
+
+// runExport runs "alpha export", it writes one <collection>.jsonl file per
+// collection to the directory.
+func runExport(args []string) int {
+	flagSet := newFlagSet("export", "", "Writes collections to <dir>/<collection>.jsonl, one extended JSON document per line.\n"+
+		"Users are exported with their password hashes, keep the files private.")
+	dir := flagSet.String("dir", "dump", "directory the files are written to")
+	only := flagSet.String("collections", "", "comma separated collections to export, all by default")
+
+	config := loadConfig(flagSet, args)
+
+	ctx, stop := commandContext()
+	defer stop()
+
+	mongoClient, disconnect, err := connectMongo(ctx, config.Mongo)
+	if err != nil {
+		slog.Error("Cannot connect to MongoDB", "error", err)
+		return 1
+	}
+	defer disconnect()
+
+	dataSets, err := selectDataSets(newDataSets(mongoClient, config.Mongo), *only)
+	if err != nil {
+		slog.Error("Invalid -collections", "error", err)
+		return 2
+	}
+
+	if err := os.MkdirAll(*dir, 0700); err != nil {
+		slog.Error("Export directory could not be created", "error", err)
+		return 1
+	}
+
+	for _, dataSet := range dataSets {
+		count, err := exportDataSet(ctx, dataSet, filepath.Join(*dir, dataSet.name+".jsonl"))
+		if err != nil {
+			slog.Error("Collection could not be exported", "collection", dataSet.name, "error", err)
+			return 1
+		}
+
+		slog.Info("Collection exported", "collection", dataSet.name, "documents", count)
+	}
+
+	return 0
+}
+
+// runImport runs "alpha import". Documents whose id already exists are skipped
+// unless -replace is set, so that importing a dump twice changes nothing.
+func runImport(args []string) int {
+	flagSet := newFlagSet("import", "", "Reads collections from <dir>/<collection>.jsonl as written by export. Documents whose\n"+
+		"id exists are skipped, with -replace they overwrite the stored document instead.")
+	dir := flagSet.String("dir", "dump", "directory the files are read from")
+	only := flagSet.String("collections", "", "comma separated collections to import, all files found by default")
+	replace := flagSet.Bool("replace", false, "overwrite documents whose id already exists")
+
+	config := loadConfig(flagSet, args)
+
+	ctx, stop := commandContext()
+	defer stop()
+
+	mongoClient, disconnect, err := connectMongo(ctx, config.Mongo)
+	if err != nil {
+		slog.Error("Cannot connect to MongoDB", "error", err)
+		return 1
+	}
+	defer disconnect()
+
+	dataSets, err := selectDataSets(newDataSets(mongoClient, config.Mongo), *only)
+	if err != nil {
+		slog.Error("Invalid -collections", "error", err)
+		return 2
+	}
+
+	for _, dataSet := range dataSets {
+		path := filepath.Join(*dir, dataSet.name+".jsonl")
+
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) && *only == "" {
+			slog.Info("No file for collection, skipped", "collection", dataSet.name, "path", path)
+			continue
+		}
+
+		written, skipped, err := importDataSet(ctx, dataSet, path, *replace)
+		if err != nil {
+			slog.Error("Collection could not be imported", "collection", dataSet.name, "written", written, "error", err)
+			return 1
+		}
+
+		slog.Info("Collection imported", "collection", dataSet.name, "written", written, "skipped", skipped)
+	}
+
+	return 0
+}
+
+func selectDataSets(dataSets []dataSet, only string) ([]dataSet, error) {
+	if only == "" {
+		return dataSets, nil
+	}
+
+	var selected []dataSet
+
+	for _, name := range strings.Split(only, ",") {
+		found := false
+
+		for _, dataSet := range dataSets {
+			if dataSet.name == strings.TrimSpace(name) {
+				selected = append(selected, dataSet)
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("unknown collection %q", name)
+		}
+	}
+
+	return selected, nil
+}
+
+func exportDataSet(ctx context.Context, dataSet dataSet, path string) (int, error) {
+	documents, err := dataSet.export(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	for _, document := range documents {
+		line, err := bson.MarshalExtJSON(document, true, false)
+		if err != nil {
+			return 0, err
+		}
+
+		writer.Write(line)
+		writer.WriteByte('\n')
+	}
+
+	if err := writer.Flush(); err != nil {
+		return 0, err
+	}
+
+	return len(documents), file.Close()
+}
+
+func importDataSet(ctx context.Context, dataSet dataSet, path string, replace bool) (written, skipped int, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxDumpLine)
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		if err := ctx.Err(); err != nil {
+			return written, skipped, err
+		}
+
+		line := scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+
+		if replace {
+			err = dataSet.replace(ctx, line)
+		} else {
+			err = dataSet.insert(ctx, line)
+		}
+
+		if !replace && mongo.IsDuplicateKeyError(err) {
+			skipped++
+			continue
+		}
+
+		if err != nil {
+			return written, skipped, fmt.Errorf("%s line %d: %w", path, lineNumber, err)
+		}
+
+		written++
+	}
+
+	return written, skipped, scanner.Err()
+}
@@ -0,0 +1,350 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"alpha.com/internal/alpha.com/application/controller/request"
+	"alpha.com/internal/alpha.com/application/controller/response"
+	"alpha.com/internal/alpha.com/application/handler/admin"
+	"alpha.com/internal/alpha.com/application/query"
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"alpha.com/internal/alpha.com/pkg/utils"
+	"alpha.com/internal/alpha.com/pkg/validation"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type IAdminController interface {
+	SearchUsers(ctx *fiber.Ctx) error
+	SuspendUser(ctx *fiber.Ctx) error
+	BanUser(ctx *fiber.Ctx) error
+	UnbanUser(ctx *fiber.Ctx) error
+	UnpublishJob(ctx *fiber.Ctx) error
+	DeleteJob(ctx *fiber.Ctx) error
+	GetModerationQueue(ctx *fiber.Ctx) error
+	ResolveReport(ctx *fiber.Ctx) error
+	GetAdminActions(ctx *fiber.Ctx) error
+}
+
+type AdminController struct {
+	adminQueryService   query.IAdminQueryService
+	adminCommandHandler admin.ICommandHandler
+	customValidator     validation.ICustomValidator
+}
+
+func NewAdminController(
+	adminQueryService query.IAdminQueryService,
+	adminCommandHandler admin.ICommandHandler,
+	customValidator validation.ICustomValidator,
+) IAdminController {
+	return &AdminController{
+		adminQueryService:   adminQueryService,
+		adminCommandHandler: adminCommandHandler,
+		customValidator:     customValidator,
+	}
+}
+
+// SearchUsers godoc
+//
+//	@Summary		This method used for searching users by name or email
+//	@Description	search users
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			search	query		string	false	"part of email, first name or last name"
+//	@Param			status	query		string	false	"active, suspended or banned"
+//
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200 {object} []response.UserResponse
+//
+//	@Failure		401
+//	@Failure		403
+//	@Failure		500
+//	@Router			/api/v1/alpha/admin/users [get]
+func (u *AdminController) SearchUsers(ctx *fiber.Ctx) error {
+	users, err := u.adminQueryService.SearchUsers(ctx.UserContext(), ctx.Query("search"), ctx.Query("status"))
+
+	if err != nil {
+		logger.FromContext(ctx.UserContext()).Error("adminController.SearchUsers error while searching users", "error", err)
+		return errorResponse(err, fiber.NewError(http.StatusInternalServerError, err.Error()))
+	}
+
+	return ctx.Status(http.StatusOK).JSON(response.ToUserResponseList(users))
+}
+
+// SuspendUser godoc
+//
+//	@Summary		This method used for suspending a user, optionally until a given date
+//	@Description	suspend user
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			userId	path		string	true	"userId"
+//
+// @Param requestBody body request.AdminUserStatusRequest nil "Handle Request Body"
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200
+//
+//	@Failure		400
+//	@Failure		401
+//	@Failure		403
+//	@Failure		404
+//	@Router			/api/v1/alpha/admin/users/{userId}/suspend [post]
+func (u *AdminController) SuspendUser(ctx *fiber.Ctx) error {
+	return u.changeUserStatus(ctx, "SuspendUser", u.adminCommandHandler.SuspendUser, "User Successfully Suspended")
+}
+
+// BanUser godoc
+//
+//	@Summary		This method used for banning a user
+//	@Description	ban user
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			userId	path		string	true	"userId"
+//
+// @Param requestBody body request.AdminUserStatusRequest nil "Handle Request Body"
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200
+//
+//	@Failure		400
+//	@Failure		401
+//	@Failure		403
+//	@Failure		404
+//	@Router			/api/v1/alpha/admin/users/{userId}/ban [post]
+func (u *AdminController) BanUser(ctx *fiber.Ctx) error {
+	return u.changeUserStatus(ctx, "BanUser", u.adminCommandHandler.BanUser, "User Successfully Banned")
+}
+
+// UnbanUser godoc
+//
+//	@Summary		This method used for lifting a ban or suspension
+//	@Description	unban user
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			userId	path		string	true	"userId"
+//
+// @Param requestBody body request.AdminUserStatusRequest nil "Handle Request Body"
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200
+//
+//	@Failure		400
+//	@Failure		401
+//	@Failure		403
+//	@Failure		404
+//	@Router			/api/v1/alpha/admin/users/{userId}/unban [post]
+func (u *AdminController) UnbanUser(ctx *fiber.Ctx) error {
+	return u.changeUserStatus(ctx, "UnbanUser", u.adminCommandHandler.UnbanUser, "User Successfully Unbanned")
+}
+
+func (u *AdminController) changeUserStatus(ctx *fiber.Ctx, name string, handle func(ctx context.Context, command admin.CommandUserStatus, adminID string) error, message string) error {
+	var req request.AdminUserStatusRequest
+	err := ctx.BodyParser(&req)
+
+	if err != nil {
+		logger.FromContext(ctx.UserContext()).Warn("adminController."+name+" error while binding json", "error", err)
+		return fiber.NewError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := u.customValidator.Validate(req); err != nil {
+		logger.FromContext(ctx.UserContext()).Warn("adminController."+name+" invalid request", "request", req, "errors", err)
+		return ctx.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	userCtx := ctx.UserContext().Value("user").(*utils.UserContext)
+
+	err = handle(ctx.UserContext(), req.ToCommand(ctx.Params("userId")), userCtx.UserID)
+
+	if err != nil {
+		return moderationError(err)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(
+		map[string]interface{}{
+			"message": message,
+		},
+	)
+}
+
+// UnpublishJob godoc
+//
+//	@Summary		This method used for hiding a job from the public listing
+//	@Description	unpublish job
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			jobId	path		string	true	"jobId"
+//
+// @Param requestBody body request.AdminJobModerationRequest nil "Handle Request Body"
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200
+//
+//	@Failure		400
+//	@Failure		401
+//	@Failure		403
+//	@Failure		404
+//	@Router			/api/v1/alpha/admin/jobs/{jobId}/unpublish [post]
+func (u *AdminController) UnpublishJob(ctx *fiber.Ctx) error {
+	return u.moderateJob(ctx, "UnpublishJob", u.adminCommandHandler.UnpublishJob, "Job Successfully Unpublished")
+}
+
+// DeleteJob godoc
+//
+//	@Summary		This method used for deleting any job
+//	@Description	delete job
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			jobId	path		string	true	"jobId"
+//
+// @Param requestBody body request.AdminJobModerationRequest nil "Handle Request Body"
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200
+//
+//	@Failure		400
+//	@Failure		401
+//	@Failure		403
+//	@Failure		404
+//	@Router			/api/v1/alpha/admin/jobs/{jobId} [delete]
+func (u *AdminController) DeleteJob(ctx *fiber.Ctx) error {
+	return u.moderateJob(ctx, "DeleteJob", u.adminCommandHandler.DeleteJob, "Job Successfully Deleted")
+}
+
+func (u *AdminController) moderateJob(ctx *fiber.Ctx, name string, handle func(ctx context.Context, command admin.CommandJobModeration, adminID string) error, message string) error {
+	var req request.AdminJobModerationRequest
+	err := ctx.BodyParser(&req)
+
+	if err != nil {
+		logger.FromContext(ctx.UserContext()).Warn("adminController."+name+" error while binding json", "error", err)
+		return fiber.NewError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := u.customValidator.Validate(req); err != nil {
+		logger.FromContext(ctx.UserContext()).Warn("adminController."+name+" invalid request", "request", req, "errors", err)
+		return ctx.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	userCtx := ctx.UserContext().Value("user").(*utils.UserContext)
+
+	err = handle(ctx.UserContext(), req.ToCommand(ctx.Params("jobId")), userCtx.UserID)
+
+	if err != nil {
+		return moderationError(err)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(
+		map[string]interface{}{
+			"message": message,
+		},
+	)
+}
+
+// GetModerationQueue godoc
+//
+//	@Summary		This method used for listing open reports, oldest first
+//	@Description	get moderation queue
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200 {object} []response.ReportResponse
+//
+//	@Failure		401
+//	@Failure		403
+//	@Failure		500
+//	@Router			/api/v1/alpha/admin/moderation-queue [get]
+func (u *AdminController) GetModerationQueue(ctx *fiber.Ctx) error {
+	reports, err := u.adminQueryService.GetModerationQueue(ctx.UserContext())
+
+	if err != nil {
+		logger.FromContext(ctx.UserContext()).Error("adminController.GetModerationQueue error while getting reports", "error", err)
+		return errorResponse(err, fiber.NewError(http.StatusInternalServerError, err.Error()))
+	}
+
+	return ctx.Status(http.StatusOK).JSON(response.ToReportResponseList(reports))
+}
+
+// ResolveReport godoc
+//
+//	@Summary		This method used for closing a report as resolved or dismissed
+//	@Description	resolve report
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			reportId	path		string	true	"reportId"
+//
+// @Param requestBody body request.AdminResolveReportRequest nil "Handle Request Body"
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200
+//
+//	@Failure		400
+//	@Failure		401
+//	@Failure		403
+//	@Failure		404
+//	@Router			/api/v1/alpha/admin/reports/{reportId}/resolve [post]
+func (u *AdminController) ResolveReport(ctx *fiber.Ctx) error {
+	var req request.AdminResolveReportRequest
+	err := ctx.BodyParser(&req)
+
+	if err != nil {
+		logger.FromContext(ctx.UserContext()).Warn("adminController.ResolveReport error while binding json", "error", err)
+		return fiber.NewError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := u.customValidator.Validate(req); err != nil {
+		logger.FromContext(ctx.UserContext()).Warn("adminController.ResolveReport invalid request", "request", req, "errors", err)
+		return ctx.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	userCtx := ctx.UserContext().Value("user").(*utils.UserContext)
+
+	err = u.adminCommandHandler.ResolveReport(ctx.UserContext(), req.ToCommand(ctx.Params("reportId")), userCtx.UserID)
+
+	if err != nil {
+		return moderationError(err)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(
+		map[string]interface{}{
+			"message": "Report Successfully Closed",
+		},
+	)
+}
+
+// GetAdminActions godoc
+//
+//	@Summary		This method used for listing the moderation audit log, newest first
+//	@Description	get admin actions
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200 {object} []response.AdminActionResponse
+//
+//	@Failure		401
+//	@Failure		403
+//	@Failure		500
+//	@Router			/api/v1/alpha/admin/actions [get]
+func (u *AdminController) GetAdminActions(ctx *fiber.Ctx) error {
+	adminActions, err := u.adminQueryService.GetAdminActions(ctx.UserContext())
+
+	if err != nil {
+		logger.FromContext(ctx.UserContext()).Error("adminController.GetAdminActions error while getting admin actions", "error", err)
+		return errorResponse(err, fiber.NewError(http.StatusInternalServerError, err.Error()))
+	}
+
+	return ctx.Status(http.StatusOK).JSON(response.ToAdminActionResponseList(adminActions))
+}
+
+func moderationError(err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return fiber.NewError(http.StatusNotFound, "Resource not found with given id")
+	}
+
+	return errorResponse(err, fiber.NewError(http.StatusBadRequest, err.Error()))
+}
@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"alpha.com/internal/alpha.com/application/controller/request"
+	"alpha.com/internal/alpha.com/application/controller/response"
+	"alpha.com/internal/alpha.com/application/handler/apiKey"
+	"alpha.com/internal/alpha.com/application/query"
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"alpha.com/internal/alpha.com/pkg/utils"
+	"alpha.com/internal/alpha.com/pkg/validation"
+	"github.com/gofiber/fiber/v2"
+)
+
+type IApiKeyController interface {
+	Save(ctx *fiber.Ctx) error
+	GetApiKeys(ctx *fiber.Ctx) error
+	Revoke(ctx *fiber.Ctx) error
+}
+
+type ApiKeyController struct {
+	apiKeyQueryService   query.IApiKeyQueryService
+	apiKeyCommandHandler apiKey.ICommandHandler
+	customValidator      validation.ICustomValidator
+}
+
+func NewApiKeyController(apiKeyQueryService query.IApiKeyQueryService,
+	apiKeyCommandHandler apiKey.ICommandHandler,
+	customValidator validation.ICustomValidator,
+) IApiKeyController {
+	return &ApiKeyController{
+		apiKeyQueryService:   apiKeyQueryService,
+		apiKeyCommandHandler: apiKeyCommandHandler,
+		customValidator:      customValidator,
+	}
+}
+
+// Save godoc
+//
+//	@Summary		This method used for creating an api key for a business account
+//	@Description	the key is only returned once
+//	@Tags			Business Account
+//	@Accept			json
+//	@Produce		json
+//	@Param			businessAccountId	path	string	true	"businessAccountId"
+//
+// @Param Authorization header string true "Bearer {token}"
+// @Param requestBody body request.ApiKeyCreateRequest nil "Handle Request Body"
+// @Success 201
+//
+//	@Failure		400
+//	@Failure		401
+//	@Failure		404
+//	@Failure		500
+//	@Router			/api/v1/alpha/business-account/{businessAccountId}/api-keys [post]
+func (u *ApiKeyController) Save(ctx *fiber.Ctx) error {
+	var req request.ApiKeyCreateRequest
+	err := ctx.BodyParser(&req)
+
+	if err != nil {
+		logger.FromContext(ctx.UserContext()).Warn("apiKeyController.Save error while binding json", "error", err)
+		return fiber.NewError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := u.customValidator.Validate(req); err != nil {
+		logger.FromContext(ctx.UserContext()).Warn("apiKeyController.Save invalid request", "request", req, "errors", err)
+		return ctx.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	userCtx := ctx.UserContext().Value("user").(*utils.UserContext)
+
+	apiKeyID, key, err := u.apiKeyCommandHandler.Create(ctx.UserContext(), req.ToCommand(ctx.Params("businessAccountId"), userCtx.UserID))
+
+	if err != nil {
+		return apiKeyError(ctx, "Save", err)
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(
+		map[string]interface{}{
+			"message": "Api Key Successfully Created, It Will Not Be Shown Again",
+			"response": map[string]interface{}{
+				"_id": apiKeyID,
+				"key": key,
+			},
+		},
+	)
+}
+
+// GetApiKeys godoc
+//
+//	@Summary		This method used for listing the api keys of a business account
+//	@Description	get api keys
+//	@Tags			Business Account
+//	@Produce		json
+//	@Param			businessAccountId	path	string	true	"businessAccountId"
+//
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200 {object} []response.ApiKeyResponse
+//
+//	@Failure		401
+//	@Failure		404
+//	@Failure		500
+//	@Router			/api/v1/alpha/business-account/{businessAccountId}/api-keys [get]
+func (u *ApiKeyController) GetApiKeys(ctx *fiber.Ctx) error {
+	userCtx := ctx.UserContext().Value("user").(*utils.UserContext)
+
+	apiKeys, err := u.apiKeyQueryService.GetByBusinessAccountID(ctx.UserContext(), ctx.Params("businessAccountId"), userCtx.UserID)
+
+	if err != nil {
+		return apiKeyError(ctx, "GetApiKeys", err)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(response.ToApiKeyResponseList(apiKeys))
+}
+
+// Revoke godoc
+//
+//	@Summary		This method used for revoking an api key
+//	@Description	revoke api key
+//	@Tags			Business Account
+//	@Produce		json
+//	@Param			businessAccountId	path	string	true	"businessAccountId"
+//	@Param			apiKeyId			path	string	true	"apiKeyId"
+//
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200
+//
+//	@Failure		401
+//	@Failure		404
+//	@Failure		500
+//	@Router			/api/v1/alpha/business-account/{businessAccountId}/api-keys/{apiKeyId} [delete]
+func (u *ApiKeyController) Revoke(ctx *fiber.Ctx) error {
+	userCtx := ctx.UserContext().Value("user").(*utils.UserContext)
+
+	err := u.apiKeyCommandHandler.Revoke(ctx.UserContext(), apiKey.CommandRevoke{
+		BusinessAccountID: ctx.Params("businessAccountId"),
+		ApiKeyID:          ctx.Params("apiKeyId"),
+		UserID:            userCtx.UserID,
+	})
+
+	if err != nil {
+		return apiKeyError(ctx, "Revoke", err)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(
+		map[string]interface{}{
+			"message": "Api Key Successfully Revoked",
+		},
+	)
+}
+
+func apiKeyError(ctx *fiber.Ctx, method string, err error) error {
+	switch {
+	case errors.Is(err, query.ErrBusinessAccountNotFound), errors.Is(err, apiKey.ErrApiKeyNotFound):
+		return fiber.NewError(http.StatusNotFound, err.Error())
+	case errors.Is(err, apiKey.ErrInvalidScope), errors.Is(err, apiKey.ErrExpiryInPast):
+		return fiber.NewError(http.StatusBadRequest, err.Error())
+	}
+
+	logger.FromContext(ctx.UserContext()).Error("apiKeyController."+method+" failed", "error", err)
+	return errorResponse(err, fiber.NewError(http.StatusInternalServerError, "Internal Server Error"))
+}
@@ -0,0 +1,248 @@
+package controller
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"alpha.com/configuration"
+	"alpha.com/internal/alpha.com/application/handler/externalIdentity"
+	"alpha.com/internal/alpha.com/application/handler/jwt"
+	"alpha.com/internal/alpha.com/application/handler/magicLink"
+	"alpha.com/internal/alpha.com/application/handler/mfa"
+	"alpha.com/internal/alpha.com/application/handler/user"
+	"alpha.com/internal/alpha.com/application/query"
+	"alpha.com/internal/alpha.com/application/repository"
+	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/server/services"
+	"alpha.com/internal/alpha.com/pkg/validation"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type fakeUserQueryService struct {
+	query.IUserQueryService
+	user *domain.User
+}
+
+func (q *fakeUserQueryService) GetUserById(ctx context.Context, userId string) (*domain.User, error) {
+	return q.user, nil
+}
+
+type fakeSessionRepository struct {
+	repository.ISessionRepository
+	sessions int
+}
+
+func (r *fakeSessionRepository) Upsert(ctx context.Context, session *domain.Session) error {
+	r.sessions++
+	return nil
+}
+
+func (r *fakeSessionRepository) Touch(ctx context.Context, sessionId string, ip string, expiresAt time.Time) error {
+	return nil
+}
+
+type fakeJwtRepository struct {
+	repository.IJwtRepository
+	jwts map[string]*domain.Jwt
+}
+
+func (r *fakeJwtRepository) Upsert(ctx context.Context, jwt *domain.Jwt) error {
+	r.jwts[jwt.RefreshTokenHash] = jwt
+	return nil
+}
+
+func (r *fakeJwtRepository) GetByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (*domain.Jwt, error) {
+	return r.jwts[refreshTokenHash], nil
+}
+
+func (r *fakeJwtRepository) MarkRotated(ctx context.Context, refreshTokenHash string) (bool, error) {
+	jwt := r.jwts[refreshTokenHash]
+	if jwt.RotatedAt != nil {
+		return false, nil
+	}
+
+	now := time.Now()
+	jwt.RotatedAt = &now
+
+	return true, nil
+}
+
+type fakeMagicLinkCommandHandler struct {
+	magicLink.ICommandHandler
+	userID string
+}
+
+func (h *fakeMagicLinkCommandHandler) Consume(ctx context.Context, command magicLink.CommandConsume) (user.SignInResult, error) {
+	return user.SignInResult{UserID: h.userID}, nil
+}
+
+type fakeMfaCommandHandler struct {
+	mfa.ICommandHandler
+	userID string
+}
+
+func (h *fakeMfaCommandHandler) Verify(ctx context.Context, command mfa.CommandVerify) (string, error) {
+	return h.userID, nil
+}
+
+type fakeExternalIdentityCommandHandler struct {
+	externalIdentity.ICommandHandler
+	userID string
+}
+
+func (h *fakeExternalIdentityCommandHandler) Callback(ctx context.Context, command externalIdentity.CommandCallback) (externalIdentity.CallbackResult, error) {
+	return externalIdentity.CallbackResult{SignIn: user.SignInResult{UserID: h.userID}}, nil
+}
+
+// blockedUserTest signs a user in through the real jwt command handler, the
+// ways of signing in only differ in how they authenticated the user.
+type blockedUserTest struct {
+	user              *domain.User
+	sessionRepository *fakeSessionRepository
+	jwtCommandHandler jwt.ICommandHandler
+}
+
+func newBlockedUserTest(t *testing.T) *blockedUserTest {
+	t.Helper()
+
+	keyRing, err := services.NewKeyRing(t.TempDir(), time.Hour, 0, true)
+	if err != nil {
+		t.Fatalf("NewKeyRing failed: %v", err)
+	}
+
+	test := &blockedUserTest{
+		user:              &domain.User{Id: primitive.NewObjectID(), Email: "user@example.com", Status: domain.UserStatusActive},
+		sessionRepository: &fakeSessionRepository{},
+	}
+
+	test.jwtCommandHandler = jwt.NewCommandHandler(
+		&fakeJwtRepository{jwts: make(map[string]*domain.Jwt)},
+		test.sessionRepository,
+		services.NewJwtService(keyRing, configuration.Default().Jwt, time.Minute),
+		&fakeUserQueryService{user: test.user},
+		nil,
+	)
+
+	return test
+}
+
+func (test *blockedUserTest) block(status domain.UserStatus) {
+	test.user.Status = status
+	if status == domain.UserStatusSuspended {
+		suspendedUntil := time.Now().Add(time.Hour)
+		test.user.SuspendedUntil = &suspendedUntil
+	}
+}
+
+func (test *blockedUserTest) request(t *testing.T, app *fiber.App, method string, target string, body string, headers map[string]string) int {
+	t.Helper()
+
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := app.Test(request)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	return response.StatusCode
+}
+
+func TestSignInPathsRefuseBlockedUsers(t *testing.T) {
+	paths := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		route  func(test *blockedUserTest, app *fiber.App)
+	}{
+		{
+			name:   "magic link",
+			method: fiber.MethodGet,
+			target: "/auth/magic-link/consume?token=token",
+			route: func(test *blockedUserTest, app *fiber.App) {
+				controller := NewMagicLinkController(&fakeMagicLinkCommandHandler{userID: test.user.Id.Hex()}, test.jwtCommandHandler, nil, configuration.Default().MagicLink, false)
+				app.Get("/auth/magic-link/consume", controller.Consume)
+			},
+		},
+		{
+			name:   "oidc",
+			method: fiber.MethodGet,
+			target: "/auth/oidc/google/callback?code=code&state=state",
+			route: func(test *blockedUserTest, app *fiber.App) {
+				controller := NewExternalIdentityController(nil, &fakeExternalIdentityCommandHandler{userID: test.user.Id.Hex()}, test.jwtCommandHandler, configuration.Default().Oidc, false)
+				app.Get("/auth/oidc/:provider/callback", controller.Callback)
+			},
+		},
+		{
+			name:   "mfa verify",
+			method: fiber.MethodPost,
+			target: "/auth/mfa/verify",
+			body:   `{"challengeToken":"challenge","code":"123456"}`,
+			route: func(test *blockedUserTest, app *fiber.App) {
+				controller := NewMfaController(&fakeMfaCommandHandler{userID: test.user.Id.Hex()}, test.jwtCommandHandler, validation.NewCustomValidator(validator.New()))
+				app.Post("/auth/mfa/verify", controller.Verify)
+			},
+		},
+	}
+
+	for _, path := range paths {
+		for _, status := range []domain.UserStatus{domain.UserStatusActive, domain.UserStatusBanned, domain.UserStatusSuspended} {
+			t.Run(path.name+" "+string(status), func(t *testing.T) {
+				test := newBlockedUserTest(t)
+				test.block(status)
+
+				app := fiber.New()
+				path.route(test, app)
+
+				want, sessions := fiber.StatusOK, 1
+				if status != domain.UserStatusActive {
+					want, sessions = fiber.StatusUnauthorized, 0
+				}
+
+				if got := test.request(t, app, path.method, path.target, path.body, nil); got != want {
+					t.Errorf("%s answered %d, want %d", path.name, got, want)
+				}
+
+				if test.sessionRepository.sessions != sessions {
+					t.Errorf("%s started %d sessions, want %d", path.name, test.sessionRepository.sessions, sessions)
+				}
+			})
+		}
+	}
+}
+
+func TestRefreshRefusesBlockedUsers(t *testing.T) {
+	for _, status := range []domain.UserStatus{domain.UserStatusActive, domain.UserStatusBanned, domain.UserStatusSuspended} {
+		t.Run(string(status), func(t *testing.T) {
+			test := newBlockedUserTest(t)
+
+			_, refreshToken, err := test.jwtCommandHandler.Create(context.Background(), jwt.Command{UserID: test.user.Id.Hex()})
+			if err != nil {
+				t.Fatalf("Create failed: %v", err)
+			}
+
+			test.block(status)
+
+			app := fiber.New()
+			app.Post("/jwt/refresh", NewJwtController(nil, test.jwtCommandHandler, nil).Refresh)
+
+			want := fiber.StatusOK
+			if status != domain.UserStatusActive {
+				want = fiber.StatusUnauthorized
+			}
+
+			if got := test.request(t, app, fiber.MethodPost, "/jwt/refresh", "", map[string]string{"X-Refresh": refreshToken}); got != want {
+				t.Errorf("refresh answered %d, want %d", got, want)
+			}
+		})
+	}
+}
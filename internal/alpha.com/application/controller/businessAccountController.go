@@ -1,13 +1,13 @@
 package controller
 
 import (
-	"fmt"
 	"net/http"
 
 	"alpha.com/internal/alpha.com/application/controller/request"
 	"alpha.com/internal/alpha.com/application/controller/response"
 	"alpha.com/internal/alpha.com/application/handler/businessAccount"
 	"alpha.com/internal/alpha.com/application/query"
+	"alpha.com/internal/alpha.com/pkg/logger"
 	"alpha.com/internal/alpha.com/pkg/utils"
 	"alpha.com/internal/alpha.com/pkg/validation"
 	"github.com/gofiber/fiber/v2"
@@ -60,14 +60,14 @@ func (u *BusinessAccountController) Save(ctx *fiber.Ctx) error {
 	err := ctx.BodyParser(&req)
 
 	if err != nil {
-		fmt.Printf("BusinessAccountController.Save ERROR -> There was an error while binding json - ERROR: %v\n", err.Error())
+		logger.FromContext(ctx.UserContext()).Warn("BusinessAccountController.Save error while binding json", "error", err)
 		return fiber.NewError(http.StatusBadRequest, err.Error())
 	}
 
-	fmt.Printf("BusinessAccountController.Save STARTED with request: %#v\n", req)
+	logger.FromContext(ctx.UserContext()).Info("BusinessAccountController.Save started", "request", req)
 
 	if err := u.customValidator.Validate(req); err != nil {
-		fmt.Printf("BusinessAccountController.Save INVALID request: %#v - ERROR: %#v\n", req, err)
+		logger.FromContext(ctx.UserContext()).Warn("BusinessAccountController.Save invalid request", "request", req, "errors", err)
 		return ctx.Status(http.StatusBadRequest).JSON(err)
 	}
 
@@ -76,7 +76,7 @@ func (u *BusinessAccountController) Save(ctx *fiber.Ctx) error {
 	errOfCommandHandler := u.businessAccountCommandHandler.Save(ctx.UserContext(), req.ToCommand(), userCtx.UserID)
 
 	if errOfCommandHandler != nil {
-		return fiber.NewError(http.StatusBadRequest, errOfCommandHandler.Error())
+		return errorResponse(errOfCommandHandler, fiber.NewError(http.StatusBadRequest, errOfCommandHandler.Error()))
 	}
 
 	return ctx.Status(http.StatusOK).JSON(
@@ -104,8 +104,8 @@ func (u *BusinessAccountController) GetAllBusinessAccounts(ctx *fiber.Ctx) error
 	businessAccounts, err := u.businessAccountQueryService.GetAllBusinessAccounts(ctx.UserContext())
 
 	if err != nil {
-		fmt.Printf("businessAccountController.GetAllBusinessAccounts ERROR -> There was an error while getting businessAccounts - ERROR: %v\n", err.Error())
-		return fiber.NewError(http.StatusInternalServerError, err.Error())
+		logger.FromContext(ctx.UserContext()).Error("businessAccountController.GetAllBusinessAccounts error while getting businessAccounts", "error", err)
+		return errorResponse(err, fiber.NewError(http.StatusInternalServerError, err.Error()))
 	}
 
 	return ctx.Status(http.StatusOK).JSON(response.ToBusinessAccountResponseList(businessAccounts))
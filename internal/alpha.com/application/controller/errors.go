@@ -0,0 +1,31 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"alpha.com/internal/alpha.com/application/handler/jwt"
+	"github.com/gofiber/fiber/v2"
+)
+
+// errorResponse answers err with response. A deadline that passed, of the
+// request or of one repository call, is returned as it is instead, the error
+// handler answers it with 504.
+func errorResponse(err error, response *fiber.Error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	return response
+}
+
+// tokenError answers the errors of jwt.ICommandHandler.Create, a banned or
+// suspended user is refused like by the JWT middleware.
+func tokenError(err error) error {
+	if errors.Is(err, jwt.ErrUserBlocked) {
+		return fiber.NewError(http.StatusUnauthorized, err.Error())
+	}
+
+	return errorResponse(err, fiber.NewError(http.StatusInternalServerError, err.Error()))
+}
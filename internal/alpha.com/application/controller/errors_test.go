@@ -0,0 +1,24 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestErrorResponseKeepsDeadline(t *testing.T) {
+	timedOut := fmt.Errorf("userRepository.GetById: %w", context.DeadlineExceeded)
+
+	if err := errorResponse(timedOut, fiber.NewError(http.StatusInternalServerError, timedOut.Error())); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("errorResponse returned %v, want the deadline for the 504 mapping", err)
+	}
+
+	response := fiber.NewError(http.StatusBadRequest, "invalid")
+	if err := errorResponse(errors.New("invalid"), response); err != response {
+		t.Errorf("errorResponse returned %v, want the response", err)
+	}
+}
@@ -0,0 +1,289 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"alpha.com/configuration"
+	"alpha.com/internal/alpha.com/application/controller/response"
+	"alpha.com/internal/alpha.com/application/handler/externalIdentity"
+	"alpha.com/internal/alpha.com/application/handler/jwt"
+	"alpha.com/internal/alpha.com/application/query"
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"alpha.com/internal/alpha.com/pkg/server/services"
+	"alpha.com/internal/alpha.com/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type IExternalIdentityController interface {
+	Login(ctx *fiber.Ctx) error
+	Callback(ctx *fiber.Ctx) error
+	Link(ctx *fiber.Ctx) error
+	Unlink(ctx *fiber.Ctx) error
+	GetIdentities(ctx *fiber.Ctx) error
+}
+
+type ExternalIdentityController struct {
+	externalIdentityQueryService   query.IExternalIdentityQueryService
+	externalIdentityCommandHandler externalIdentity.ICommandHandler
+	jwtCommandHandler              jwt.ICommandHandler
+	oidcConfig                     configuration.OidcConfig
+	secureCookies                  bool
+}
+
+func NewExternalIdentityController(externalIdentityQueryService query.IExternalIdentityQueryService,
+	externalIdentityCommandHandler externalIdentity.ICommandHandler,
+	jwtCommandHandler jwt.ICommandHandler,
+	oidcConfig configuration.OidcConfig,
+	secureCookies bool,
+) IExternalIdentityController {
+	return &ExternalIdentityController{
+		externalIdentityQueryService:   externalIdentityQueryService,
+		externalIdentityCommandHandler: externalIdentityCommandHandler,
+		jwtCommandHandler:              jwtCommandHandler,
+		oidcConfig:                     oidcConfig,
+		secureCookies:                  secureCookies,
+	}
+}
+
+// Login godoc
+//
+//	@Summary		This method used for signing in with an OpenID Connect provider
+//	@Description	redirects to the provider
+//	@Tags			Auth
+//	@Param			provider	path	string	true	"provider"
+//	@Success		302
+//	@Failure		404
+//	@Failure		502
+//	@Router			/api/v1/alpha/auth/oidc/{provider}/login [get]
+func (u *ExternalIdentityController) Login(ctx *fiber.Ctx) error {
+	beginResult, err := u.externalIdentityCommandHandler.Begin(ctx.UserContext(), externalIdentity.CommandBegin{
+		Provider: ctx.Params("provider"),
+	})
+
+	if err != nil {
+		return externalIdentityError(ctx, "Login", err)
+	}
+
+	u.setStateCookie(ctx, beginResult.State)
+
+	return ctx.Redirect(beginResult.AuthURL, http.StatusFound)
+}
+
+// Callback godoc
+//
+//	@Summary		This method used for completing a sign in or a link with an OpenID Connect provider
+//	@Description	the provider redirects here, sign ins return tokens
+//	@Tags			Auth
+//	@Produce		json
+//	@Param			provider	path	string	true	"provider"
+//	@Param			code		query	string	true	"code"
+//	@Param			state		query	string	true	"state"
+//	@Success		200
+//	@Failure		400
+//	@Failure		401
+//	@Failure		409
+//	@Failure		502
+//	@Router			/api/v1/alpha/auth/oidc/{provider}/callback [get]
+func (u *ExternalIdentityController) Callback(ctx *fiber.Ctx) error {
+	if providerError := ctx.Query("error"); providerError != "" {
+		return fiber.NewError(http.StatusUnauthorized, fmt.Sprintf("identity provider returned an error: %s", providerError))
+	}
+
+	if ctx.Query("code") == "" || ctx.Query("state") == "" {
+		return fiber.NewError(http.StatusBadRequest, "code and state are required")
+	}
+
+	browserState := ctx.Cookies(u.oidcConfig.StateCookie)
+	u.setStateCookie(ctx, "")
+
+	result, err := u.externalIdentityCommandHandler.Callback(ctx.UserContext(), externalIdentity.CommandCallback{
+		Provider:     ctx.Params("provider"),
+		Code:         ctx.Query("code"),
+		State:        ctx.Query("state"),
+		BrowserState: browserState,
+	})
+
+	if err != nil {
+		return externalIdentityError(ctx, "Callback", err)
+	}
+
+	if result.Linked {
+		return ctx.Status(http.StatusOK).JSON(
+			map[string]interface{}{
+				"message": "Account Successfully Linked",
+			},
+		)
+	}
+
+	if result.SignIn.MfaChallengeToken != "" {
+		return ctx.Status(http.StatusOK).JSON(
+			map[string]interface{}{
+				"message": "Two-Factor Authentication Required",
+				"response": map[string]interface{}{
+					"mfaRequired":    true,
+					"challengeToken": result.SignIn.MfaChallengeToken,
+				},
+			},
+		)
+	}
+
+	accessToken, refreshToken, err := u.jwtCommandHandler.Create(ctx.UserContext(), jwt.Command{
+		UserID:    result.SignIn.UserID,
+		IP:        ctx.IP(),
+		UserAgent: ctx.Get(fiber.HeaderUserAgent),
+	})
+
+	if err != nil {
+		logger.FromContext(ctx.UserContext()).Error("externalIdentityController.Callback error while creating jwt tokens", "error", err)
+		return tokenError(err)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(
+		map[string]interface{}{
+			"message": "User Successfully Sign In",
+			"response": map[string]interface{}{
+				"accessToken":  accessToken,
+				"refreshToken": refreshToken,
+			},
+		},
+	)
+}
+
+// Link godoc
+//
+//	@Summary		This method used for linking an OpenID Connect provider to the current user
+//	@Description	returns the provider url to open, the provider redirects to the callback
+//	@Tags			Auth
+//	@Produce		json
+//	@Param			provider	path	string	true	"provider"
+//
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200
+//
+//	@Failure		401
+//	@Failure		404
+//	@Failure		502
+//	@Router			/api/v1/alpha/auth/oidc/{provider}/link [post]
+func (u *ExternalIdentityController) Link(ctx *fiber.Ctx) error {
+	userCtx := ctx.UserContext().Value("user").(*utils.UserContext)
+
+	beginResult, err := u.externalIdentityCommandHandler.Begin(ctx.UserContext(), externalIdentity.CommandBegin{
+		Provider: ctx.Params("provider"),
+		UserID:   userCtx.UserID,
+	})
+
+	if err != nil {
+		return externalIdentityError(ctx, "Link", err)
+	}
+
+	u.setStateCookie(ctx, beginResult.State)
+
+	return ctx.Status(http.StatusOK).JSON(
+		map[string]interface{}{
+			"message": "Open The Authorization Url To Link The Account",
+			"response": map[string]interface{}{
+				"authorizationUrl": beginResult.AuthURL,
+			},
+		},
+	)
+}
+
+// Unlink godoc
+//
+//	@Summary		This method used for unlinking an OpenID Connect provider from the current user
+//	@Description	unlink provider
+//	@Tags			Auth
+//	@Produce		json
+//	@Param			provider	path	string	true	"provider"
+//
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200
+//
+//	@Failure		401
+//	@Failure		404
+//	@Failure		500
+//	@Router			/api/v1/alpha/auth/oidc/{provider}/link [delete]
+func (u *ExternalIdentityController) Unlink(ctx *fiber.Ctx) error {
+	userCtx := ctx.UserContext().Value("user").(*utils.UserContext)
+
+	err := u.externalIdentityCommandHandler.Unlink(ctx.UserContext(), externalIdentity.CommandUnlink{
+		Provider: ctx.Params("provider"),
+		UserID:   userCtx.UserID,
+	})
+
+	if err != nil {
+		return externalIdentityError(ctx, "Unlink", err)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(
+		map[string]interface{}{
+			"message": "Account Successfully Unlinked",
+		},
+	)
+}
+
+// GetIdentities godoc
+//
+//	@Summary		This method used for listing the providers linked to the current user
+//	@Description	get linked identities
+//	@Tags			Auth
+//	@Produce		json
+//
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200 {object} []response.ExternalIdentityResponse
+//
+//	@Failure		401
+//	@Failure		500
+//	@Router			/api/v1/alpha/me/identities [get]
+func (u *ExternalIdentityController) GetIdentities(ctx *fiber.Ctx) error {
+	userCtx := ctx.UserContext().Value("user").(*utils.UserContext)
+
+	identities, err := u.externalIdentityQueryService.GetByUserID(ctx.UserContext(), userCtx.UserID)
+
+	if err != nil {
+		logger.FromContext(ctx.UserContext()).Error("externalIdentityController.GetIdentities failed", "error", err)
+		return errorResponse(err, fiber.NewError(http.StatusInternalServerError, err.Error()))
+	}
+
+	return ctx.Status(http.StatusOK).JSON(response.ToExternalIdentityResponseList(identities))
+}
+
+// setStateCookie keeps the state in the browser that begins a flow, only that
+// browser can complete it on the callback. An empty state clears the cookie.
+// SameSite Lax still sends it on the top level redirect from the provider.
+func (u *ExternalIdentityController) setStateCookie(ctx *fiber.Ctx, state string) {
+	expires := time.Now().Add(u.oidcConfig.StateTime.Duration())
+	if state == "" {
+		expires = time.Unix(0, 0)
+	}
+
+	ctx.Cookie(&fiber.Cookie{
+		Name:     u.oidcConfig.StateCookie,
+		Value:    state,
+		Path:     "/api/v1/alpha/auth/oidc/" + ctx.Params("provider"),
+		Expires:  expires,
+		HTTPOnly: true,
+		Secure:   u.secureCookies,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+}
+
+func externalIdentityError(ctx *fiber.Ctx, method string, err error) error {
+	switch {
+	case errors.Is(err, externalIdentity.ErrUnknownProvider), errors.Is(err, externalIdentity.ErrIdentityNotFound):
+		return fiber.NewError(http.StatusNotFound, err.Error())
+	case errors.Is(err, externalIdentity.ErrInvalidState), errors.Is(err, externalIdentity.ErrEmailNotVerified),
+		errors.Is(err, services.ErrOidcExchange), errors.Is(err, services.ErrOidcInvalidToken):
+		return fiber.NewError(http.StatusUnauthorized, err.Error())
+	case errors.Is(err, externalIdentity.ErrIdentityLinkedElsewhere), errors.Is(err, externalIdentity.ErrProviderAlreadyLinked):
+		return fiber.NewError(http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrOidcDiscovery):
+		return fiber.NewError(http.StatusBadGateway, err.Error())
+	}
+
+	logger.FromContext(ctx.UserContext()).Error("externalIdentityController."+method+" failed", "error", err)
+	return errorResponse(err, fiber.NewError(http.StatusInternalServerError, "Internal Server Error"))
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"net/http"
+
+	"alpha.com/internal/alpha.com/pkg/health"
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"github.com/gofiber/fiber/v2"
+)
+
+type IHealthController interface {
+	Livez(ctx *fiber.Ctx) error
+	Readyz(ctx *fiber.Ctx) error
+}
+
+type HealthController struct {
+	readiness *health.Readiness
+}
+
+func NewHealthController(readiness *health.Readiness) IHealthController {
+	return &HealthController{
+		readiness: readiness,
+	}
+}
+
+// Livez godoc
+//
+//	@Summary		This method used for checking that the process is up
+//	@Description	liveness probe, dependencies are not checked so an outage of MongoDB does not restart every replica
+//	@Tags			Health
+//	@Produce		json
+//
+// @Success 200 {object} health.Report
+//
+//	@Router			/livez [get]
+func (u *HealthController) Livez(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+
+	return ctx.Status(http.StatusOK).JSON(health.Report{Status: health.StatusOk, Checks: map[string]health.CheckReport{}})
+}
+
+// Readyz godoc
+//
+//	@Summary		This method used for checking that the replica can serve requests
+//	@Description	readiness probe with the result of every dependency check
+//	@Tags			Health
+//	@Produce		json
+//
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+//
+//	@Router			/readyz [get]
+func (u *HealthController) Readyz(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+
+	report := u.readiness.Check(ctx.UserContext())
+	if !report.Ok() {
+		logger.FromContext(ctx.UserContext()).Warn("HealthController.Readyz not ready", "checks", report.Checks)
+		return ctx.Status(http.StatusServiceUnavailable).JSON(report)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(report)
+}
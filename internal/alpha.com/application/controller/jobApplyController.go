@@ -1,13 +1,15 @@
 package controller
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
 
 	"alpha.com/internal/alpha.com/application/controller/request"
 	"alpha.com/internal/alpha.com/application/controller/response"
 	"alpha.com/internal/alpha.com/application/handler/jobApply"
 	"alpha.com/internal/alpha.com/application/query"
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"alpha.com/internal/alpha.com/pkg/utils"
 	"alpha.com/internal/alpha.com/pkg/validation"
 	"github.com/gofiber/fiber/v2"
 )
@@ -15,6 +17,7 @@ import (
 type IJobApplyController interface {
 	Save(ctx *fiber.Ctx) error
 	GetAllJobApplies(ctx *fiber.Ctx) error
+	GetJobApplications(ctx *fiber.Ctx) error
 }
 
 type JobApplyController struct {
@@ -59,21 +62,21 @@ func (u *JobApplyController) Save(ctx *fiber.Ctx) error {
 	err := ctx.BodyParser(&req)
 
 	if err != nil {
-		fmt.Printf("JobApplyController.Save ERROR -> There was an error while binding json - ERROR: %v\n", err.Error())
+		logger.FromContext(ctx.UserContext()).Warn("JobApplyController.Save error while binding json", "error", err)
 		return fiber.NewError(http.StatusBadRequest, err.Error())
 	}
 
-	fmt.Printf("JobApplyController.Save STARTED with request: %#v\n", req)
+	logger.FromContext(ctx.UserContext()).Info("JobApplyController.Save started", "request", req)
 
 	if err := u.customValidator.Validate(req); err != nil {
-		fmt.Printf("JobApplyController.Save INVALID request: %#v - ERROR: %#v\n", req, err)
+		logger.FromContext(ctx.UserContext()).Warn("JobApplyController.Save invalid request", "request", req, "errors", err)
 		return ctx.Status(http.StatusBadRequest).JSON(err)
 	}
 
 	errOfCommandHandler := u.jobApplyCommandHandler.Save(ctx.UserContext(), req.ToCommand())
 
 	if errOfCommandHandler != nil {
-		return fiber.NewError(http.StatusBadRequest, errOfCommandHandler.Error())
+		return errorResponse(errOfCommandHandler, fiber.NewError(http.StatusBadRequest, errOfCommandHandler.Error()))
 	}
 
 	return ctx.Status(http.StatusOK).JSON(
@@ -86,14 +89,17 @@ func (u *JobApplyController) Save(ctx *fiber.Ctx) error {
 // GetUser godoc
 //
 //	@Summary		This method used for get all job applies
-//	@Description	get all job applies
+//	@Description	get all job applies, for admins only
 //	@Tags			Job Applies
 //	@Accept			json
 //	@Produce		json
 //
+// @Param Authorization header string true "Bearer {token}"
 // @Success 200 {object} []response.JobApplyResponse
 //
 //	@Failure		400
+//	@Failure		401
+//	@Failure		403
 //	@Failure		404
 //	@Failure		500
 //	@Router			/api/v1/alpha/job-apply [get]
@@ -101,8 +107,48 @@ func (u *JobApplyController) GetAllJobApplies(ctx *fiber.Ctx) error {
 	jobApplies, err := u.jobApplyQueryService.GetAllJobApplies(ctx.UserContext())
 
 	if err != nil {
-		fmt.Printf("jobApplyController.GetAllJobApplies ERROR -> There was an error while getting jobApplys - ERROR: %v\n", err.Error())
-		return fiber.NewError(http.StatusInternalServerError, err.Error())
+		logger.FromContext(ctx.UserContext()).Error("jobApplyController.GetAllJobApplies error while getting jobApplys", "error", err)
+		return errorResponse(err, fiber.NewError(http.StatusInternalServerError, err.Error()))
+	}
+
+	return ctx.Status(http.StatusOK).JSON(response.ToJobApplyResponseList(jobApplies))
+}
+
+// GetJobApplications godoc
+//
+//	@Summary		This method used for getting the applications to a job of a business account
+//	@Description	accepts a JWT of the owner or an api key with the applications:read scope
+//	@Tags			Job Applies
+//	@Produce		json
+//	@Param			businessAccountId	path	string	true	"businessAccountId"
+//	@Param			jobId				path	string	true	"jobId"
+//
+// @Param Authorization header string false "Bearer {token}"
+// @Param X-Api-Key header string false "api key"
+// @Success 200 {object} []response.JobApplyResponse
+//
+//	@Failure		401
+//	@Failure		403
+//	@Failure		404
+//	@Failure		500
+//	@Router			/api/v1/alpha/business-account/{businessAccountId}/jobs/{jobId}/applications [get]
+func (u *JobApplyController) GetJobApplications(ctx *fiber.Ctx) error {
+	userCtx := ctx.UserContext().Value("user").(*utils.UserContext)
+	businessAccountID := ctx.Params("businessAccountId")
+
+	if userCtx.IsApiKey() && userCtx.BusinessAccountID != businessAccountID {
+		return fiber.NewError(http.StatusForbidden, "Api key does not belong to this business account")
+	}
+
+	jobApplies, err := u.jobApplyQueryService.GetByBusinessAccountJob(ctx.UserContext(), businessAccountID, ctx.Params("jobId"), userCtx.UserID)
+
+	if errors.Is(err, query.ErrBusinessAccountNotFound) || errors.Is(err, query.ErrJobNotFound) {
+		return fiber.NewError(http.StatusNotFound, err.Error())
+	}
+
+	if err != nil {
+		logger.FromContext(ctx.UserContext()).Error("jobApplyController.GetJobApplications error while getting job applies", "error", err)
+		return errorResponse(err, fiber.NewError(http.StatusInternalServerError, err.Error()))
 	}
 
 	return ctx.Status(http.StatusOK).JSON(response.ToJobApplyResponseList(jobApplies))
@@ -1,13 +1,13 @@
 package controller
 
 import (
-	"fmt"
 	"net/http"
 
 	"alpha.com/internal/alpha.com/application/controller/request"
 	"alpha.com/internal/alpha.com/application/controller/response"
 	"alpha.com/internal/alpha.com/application/handler/job"
 	"alpha.com/internal/alpha.com/application/query"
+	"alpha.com/internal/alpha.com/pkg/logger"
 	"alpha.com/internal/alpha.com/pkg/utils"
 	"alpha.com/internal/alpha.com/pkg/validation"
 	"github.com/gofiber/fiber/v2"
@@ -47,11 +47,13 @@ func NewJobController(
 //
 // @Param requestBody body request.JobCreateRequest nil "Handle Request Body"
 //
-// @Param Authorization header string true "Bearer {token}"
+// @Param Authorization header string false "Bearer {token}"
+// @Param X-Api-Key header string false "api key"
 //
 // @Success 200
 //
 //	@Failure		400
+//	@Failure		403
 //	@Failure		404
 //	@Failure		500
 //	@Router			/api/v1/alpha/job [post]
@@ -60,23 +62,27 @@ func (u *JobController) Save(ctx *fiber.Ctx) error {
 	err := ctx.BodyParser(&req)
 
 	if err != nil {
-		fmt.Printf("JobController.Save ERROR -> There was an error while binding json - ERROR: %v\n", err.Error())
+		logger.FromContext(ctx.UserContext()).Warn("JobController.Save error while binding json", "error", err)
 		return fiber.NewError(http.StatusBadRequest, err.Error())
 	}
 
-	fmt.Printf("JobController.Save STARTED with request: %#v\n", req)
+	logger.FromContext(ctx.UserContext()).Info("JobController.Save started", "request", req)
 
 	if err := u.customValidator.Validate(req); err != nil {
-		fmt.Printf("JobController.Save INVALID request: %#v - ERROR: %#v\n", req, err)
+		logger.FromContext(ctx.UserContext()).Warn("JobController.Save invalid request", "request", req, "errors", err)
 		return ctx.Status(http.StatusBadRequest).JSON(err)
 	}
 
 	userCtx := ctx.UserContext().Value("user").(*utils.UserContext)
 
+	if userCtx.IsApiKey() && userCtx.BusinessAccountID != req.BusinessAccountID {
+		return fiber.NewError(http.StatusForbidden, "Api key does not belong to this business account")
+	}
+
 	errOfCommandHandler := u.jobCommandHandler.Save(ctx.UserContext(), req.ToCommand(), userCtx.UserID)
 
 	if errOfCommandHandler != nil {
-		return fiber.NewError(http.StatusBadRequest, errOfCommandHandler.Error())
+		return errorResponse(errOfCommandHandler, fiber.NewError(http.StatusBadRequest, errOfCommandHandler.Error()))
 	}
 
 	return ctx.Status(http.StatusOK).JSON(
@@ -104,8 +110,8 @@ func (u *JobController) GetAllJobs(ctx *fiber.Ctx) error {
 	jobs, err := u.jobQueryService.GetAllJobs(ctx.UserContext())
 
 	if err != nil {
-		fmt.Printf("jobController.GetAllJobs ERROR -> There was an error while getting jobs - ERROR: %v\n", err.Error())
-		return fiber.NewError(http.StatusInternalServerError, err.Error())
+		logger.FromContext(ctx.UserContext()).Error("jobController.GetAllJobs error while getting jobs", "error", err)
+		return errorResponse(err, fiber.NewError(http.StatusInternalServerError, err.Error()))
 	}
 
 	return ctx.Status(http.StatusOK).JSON(response.ToJobResponseList(jobs))
@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"alpha.com/internal/alpha.com/pkg/server/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type IJwksController interface {
+	GetJwks(ctx *fiber.Ctx) error
+}
+
+type JwksController struct {
+	keyRing services.IKeyRing
+}
+
+func NewJwksController(keyRing services.IKeyRing) IJwksController {
+	return &JwksController{
+		keyRing: keyRing,
+	}
+}
+
+// GetJwks godoc
+//
+//	@Summary		This method used for publishing the public keys that verify access tokens
+//	@Description	get json web key set
+//	@Tags			JWT
+//	@Produce		json
+//
+// @Success 200 {object} services.JSONWebKeySet
+//
+//	@Router			/.well-known/jwks.json [get]
+func (u *JwksController) GetJwks(ctx *fiber.Ctx) error {
+	// verifiers cache the set, new keys are published for longer than the
+	// max-age before they sign
+	ctx.Set(fiber.HeaderCacheControl, fmt.Sprintf("public, max-age=%d", int(services.JWKSMaxAge.Seconds())))
+
+	return ctx.Status(http.StatusOK).JSON(u.keyRing.JWKS())
+}
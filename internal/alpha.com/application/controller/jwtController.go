@@ -1,19 +1,19 @@
 package controller
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
 
-	"alpha.com/internal/alpha.com/application/controller/request"
 	"alpha.com/internal/alpha.com/application/controller/response"
 	"alpha.com/internal/alpha.com/application/handler/jwt"
 	"alpha.com/internal/alpha.com/application/query"
+	"alpha.com/internal/alpha.com/pkg/logger"
 	"alpha.com/internal/alpha.com/pkg/validation"
 	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type IJwtController interface {
-	Create(ctx *fiber.Ctx) error
 	GetJwt(ctx *fiber.Ctx) error
 	Refresh(ctx *fiber.Ctx) error
 }
@@ -32,48 +32,6 @@ func NewJwtController(jwtQueryService query.IJwtQueryService, jwtCommandHandler
 	}
 }
 
-// Save godoc
-
-//	@Summary		This method used for saving new jwt
-//	@Description	saving new jwt
-//
-// @Param requestBody body request.JwtCreateRequest nil "Handle Request Body"
-//
-//	@Tags			JWT
-//	@Accept			json
-//	@Produce		json
-//
-// @Success 200
-//
-//	@Failure		400
-//	@Failure		404
-//	@Failure		500
-//	@Router			/api/v1/alpha/jwt [post]
-func (u *JwtController) Create(ctx *fiber.Ctx) error {
-	var req request.JwtCreateRequest
-	err := ctx.BodyParser(&req)
-
-	fmt.Printf("jwtController.Save INFO -> Request Body: %v\n", req)
-
-	if err != nil {
-		fmt.Printf("jwtController.Save ERROR -> There was an error while binding json - ERROR: %v\n", err.Error())
-		return fiber.NewError(http.StatusInternalServerError, err.Error())
-	}
-
-	accessToken, refreshToken, err := u.jwtCommandHandler.Create(ctx.Context(), req.ToCommand())
-
-	if err != nil {
-		return fiber.NewError(http.StatusInternalServerError, err.Error())
-	}
-
-	return ctx.Status(http.StatusOK).JSON(
-		map[string]interface{}{
-			"accessToken":  accessToken,
-			"refreshToken": refreshToken,
-		},
-	)
-}
-
 // GetJwt godoc
 //
 //	@Summary		This method used for get all jwts
@@ -82,17 +40,20 @@ func (u *JwtController) Create(ctx *fiber.Ctx) error {
 //	@Accept			json
 //	@Produce		json
 //
+// @Param Authorization header string true "Bearer {token}"
+//
 // @Success 200 {object} []response.JwtResponse
 //
 //	@Failure		400
-//	@Failure		404
+//	@Failure		401
+//	@Failure		403
 //	@Failure		500
 //	@Router			/api/v1/alpha/jwt [get]
 func (u *JwtController) GetJwt(ctx *fiber.Ctx) error {
-	jwts, err := u.jwtQueryService.Get(ctx.Context())
+	jwts, err := u.jwtQueryService.Get(ctx.UserContext())
 
 	if err != nil {
-		return fiber.NewError(http.StatusInternalServerError, err.Error())
+		return errorResponse(err, fiber.NewError(http.StatusInternalServerError, err.Error()))
 	}
 
 	return ctx.Status(http.StatusOK).JSON(
@@ -102,8 +63,8 @@ func (u *JwtController) GetJwt(ctx *fiber.Ctx) error {
 
 // Save godoc
 
-//	@Summary		This method used for saving new jwt
-//	@Description	saving new jwt
+//	@Summary		This method used for exchanging a refresh token for a new token pair
+//	@Description	the presented refresh token is rotated and can not be used again
 //
 // @Param x-refresh header string true "{token}"
 //
@@ -114,6 +75,7 @@ func (u *JwtController) GetJwt(ctx *fiber.Ctx) error {
 // @Success 200
 //
 //	@Failure		400
+//	@Failure		401
 //	@Failure		404
 //	@Failure		500
 //	@Router			/api/v1/alpha/jwt/refresh [post]
@@ -124,41 +86,29 @@ func (u *JwtController) Refresh(ctx *fiber.Ctx) error {
 		return fiber.NewError(http.StatusBadRequest, "Refresh token is required")
 	}
 
-	fmt.Printf("jwtController.Refresh INFO -> Refresh Token: %v\n", refreshToken)
-
-	userID, err := u.jwtQueryService.ParseRefreshToken(ctx.Context(), refreshToken)
-
-	if err != nil {
-		fmt.Printf("jwtController.Refresh ERROR -> There was an error while parsing refresh token - ERROR: %v\n", err.Error())
-		return fiber.NewError(http.StatusInternalServerError, err.Error())
-	}
-
-	user, err := u.jwtQueryService.GetUserById(ctx.Context(), userID)
+	accessToken, newRefreshToken, err := u.jwtCommandHandler.Refresh(ctx.UserContext(), jwt.CommandRefresh{
+		RefreshToken: refreshToken,
+		IP:           ctx.IP(),
+	})
 
 	if err != nil {
-		fmt.Printf("jwtController.Refresh ERROR -> There was an error while binding json - ERROR: %v\n", err.Error())
-		return fiber.NewError(http.StatusInternalServerError, err.Error())
-	}
+		logger.FromContext(ctx.UserContext()).Error("jwtController.Refresh error while refreshing tokens", "error", err)
 
-	if user == nil {
-		return fiber.NewError(http.StatusNotFound, "User not found with given refresh token")
-	}
+		if errors.Is(err, jwt.ErrInvalidRefreshToken) || errors.Is(err, jwt.ErrRefreshTokenReused) || errors.Is(err, jwt.ErrUserBlocked) {
+			return fiber.NewError(http.StatusUnauthorized, err.Error())
+		}
 
-	accessToken, err := u.jwtCommandHandler.Refresh(ctx.Context(), userID)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fiber.NewError(http.StatusNotFound, "User not found with given refresh token")
+		}
 
-	if err != nil {
-		return fiber.NewError(http.StatusInternalServerError, err.Error())
-	}
-
-	err = u.jwtCommandHandler.Update(ctx.Context(), userID, accessToken, refreshToken)
-
-	if err != nil {
-		return fiber.NewError(http.StatusInternalServerError, err.Error())
+		return errorResponse(err, fiber.NewError(http.StatusInternalServerError, err.Error()))
 	}
 
 	return ctx.Status(http.StatusOK).JSON(
 		map[string]interface{}{
-			"accessToken": accessToken,
+			"accessToken":  accessToken,
+			"refreshToken": newRefreshToken,
 		},
 	)
 }
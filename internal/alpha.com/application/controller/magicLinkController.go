@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"alpha.com/configuration"
+	"alpha.com/internal/alpha.com/application/controller/request"
+	"alpha.com/internal/alpha.com/application/handler/jwt"
+	"alpha.com/internal/alpha.com/application/handler/magicLink"
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"alpha.com/internal/alpha.com/pkg/server/helpers"
+	"alpha.com/internal/alpha.com/pkg/validation"
+	"github.com/gofiber/fiber/v2"
+)
+
+type IMagicLinkController interface {
+	Request(ctx *fiber.Ctx) error
+	Consume(ctx *fiber.Ctx) error
+}
+
+type MagicLinkController struct {
+	magicLinkCommandHandler magicLink.ICommandHandler
+	jwtCommandHandler       jwt.ICommandHandler
+	customValidator         validation.ICustomValidator
+	magicLinkConfig         configuration.MagicLinkConfig
+	secureCookies           bool
+}
+
+func NewMagicLinkController(magicLinkCommandHandler magicLink.ICommandHandler,
+	jwtCommandHandler jwt.ICommandHandler,
+	customValidator validation.ICustomValidator,
+	magicLinkConfig configuration.MagicLinkConfig,
+	secureCookies bool,
+) IMagicLinkController {
+	return &MagicLinkController{
+		magicLinkCommandHandler: magicLinkCommandHandler,
+		jwtCommandHandler:       jwtCommandHandler,
+		customValidator:         customValidator,
+		magicLinkConfig:         magicLinkConfig,
+		secureCookies:           secureCookies,
+	}
+}
+
+// Request godoc
+//
+//	@Summary		This method used for requesting a passwordless sign-in link
+//	@Description	mails a single-use link that only works in the requesting browser
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//
+// @Param requestBody body request.MagicLinkRequest nil "Handle Request Body"
+// @Success 202
+//
+//	@Failure		400
+//	@Failure		500
+//	@Router			/api/v1/alpha/auth/magic-link [post]
+func (u *MagicLinkController) Request(ctx *fiber.Ctx) error {
+	var req request.MagicLinkRequest
+	err := ctx.BodyParser(&req)
+
+	if err != nil {
+		logger.FromContext(ctx.UserContext()).Warn("magicLinkController.Request error while binding json", "error", err)
+		return fiber.NewError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := u.customValidator.Validate(req); err != nil {
+		logger.FromContext(ctx.UserContext()).Warn("magicLinkController.Request invalid request", "errors", err)
+		return ctx.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	// the link is bound to this browser through a cookie only it receives
+	deviceID := ctx.Cookies(u.magicLinkConfig.DeviceCookie)
+	if deviceID == "" {
+		deviceID, err = helpers.NewRandomToken()
+		if err != nil {
+			logger.FromContext(ctx.UserContext()).Error("magicLinkController.Request error while creating device id", "error", err)
+			return fiber.NewError(http.StatusInternalServerError, "Internal Server Error")
+		}
+	}
+
+	if err := u.magicLinkCommandHandler.Request(ctx.UserContext(), req.ToCommand(deviceID, ctx.IP())); err != nil {
+		logger.FromContext(ctx.UserContext()).Error("magicLinkController.Request error while sending magic link", "error", err)
+		return errorResponse(err, fiber.NewError(http.StatusInternalServerError, "Internal Server Error"))
+	}
+
+	ctx.Cookie(&fiber.Cookie{
+		Name:     u.magicLinkConfig.DeviceCookie,
+		Value:    deviceID,
+		Path:     "/api/v1/alpha/auth/magic-link",
+		Expires:  time.Now().Add(u.magicLinkConfig.Time.Duration()),
+		HTTPOnly: true,
+		Secure:   u.secureCookies,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+
+	return ctx.Status(http.StatusAccepted).JSON(
+		map[string]interface{}{
+			"message": "If The Email Can Sign In, A Sign-In Link Has Been Sent",
+		},
+	)
+}
+
+// Consume godoc
+//
+//	@Summary		This method used for signing in with a magic link
+//	@Description	consumes the link from the mail and returns tokens
+//	@Param			token	query	string	true	"Magic link token"
+//	@Tags			Auth
+//	@Produce		json
+//	@Success		200
+//	@Failure		401
+//	@Failure		500
+//	@Router			/api/v1/alpha/auth/magic-link/consume [get]
+func (u *MagicLinkController) Consume(ctx *fiber.Ctx) error {
+	signInResult, err := u.magicLinkCommandHandler.Consume(ctx.UserContext(), magicLink.CommandConsume{
+		Token:    ctx.Query("token"),
+		DeviceID: ctx.Cookies(u.magicLinkConfig.DeviceCookie),
+	})
+
+	if errors.Is(err, magicLink.ErrInvalidMagicLink) {
+		return fiber.NewError(http.StatusUnauthorized, err.Error())
+	}
+
+	if err != nil {
+		logger.FromContext(ctx.UserContext()).Error("magicLinkController.Consume error while consuming magic link", "error", err)
+		return errorResponse(err, fiber.NewError(http.StatusInternalServerError, "Internal Server Error"))
+	}
+
+	if signInResult.MfaChallengeToken != "" {
+		return ctx.Status(http.StatusOK).JSON(
+			map[string]interface{}{
+				"message": "Two-Factor Authentication Required",
+				"response": map[string]interface{}{
+					"mfaRequired":    true,
+					"challengeToken": signInResult.MfaChallengeToken,
+				},
+			},
+		)
+	}
+
+	accessToken, refreshToken, err := u.jwtCommandHandler.Create(ctx.UserContext(), jwt.Command{
+		UserID:    signInResult.UserID,
+		IP:        ctx.IP(),
+		UserAgent: ctx.Get(fiber.HeaderUserAgent),
+	})
+
+	if err != nil {
+		logger.FromContext(ctx.UserContext()).Error("magicLinkController.Consume error while creating jwt tokens", "error", err)
+		return tokenError(err)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(
+		map[string]interface{}{
+			"message": "User Successfully Sign In",
+			"response": map[string]interface{}{
+				"accessToken":  accessToken,
+				"refreshToken": refreshToken,
+			},
+		},
+	)
+}
@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"bytes"
+	"net/http"
+
+	"alpha.com/internal/alpha.com/pkg/metrics"
+	"github.com/gofiber/fiber/v2"
+)
+
+type IMetricsController interface {
+	GetMetrics(ctx *fiber.Ctx) error
+}
+
+type MetricsController struct {
+	registry *metrics.Registry
+}
+
+func NewMetricsController(registry *metrics.Registry) IMetricsController {
+	return &MetricsController{
+		registry: registry,
+	}
+}
+
+// GetMetrics godoc
+//
+//	@Summary		This method used for scraping the metrics of this replica
+//	@Description	prometheus text exposition format
+//	@Tags			Metrics
+//	@Produce		plain
+//
+// @Success 200
+//
+//	@Router			/metrics [get]
+func (u *MetricsController) GetMetrics(ctx *fiber.Ctx) error {
+	var body bytes.Buffer
+	if _, err := u.registry.WriteTo(&body); err != nil {
+		return fiber.NewError(http.StatusInternalServerError, err.Error())
+	}
+
+	ctx.Set(fiber.HeaderContentType, metrics.ContentType)
+	return ctx.Status(http.StatusOK).Send(body.Bytes())
+}
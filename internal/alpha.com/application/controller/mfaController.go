@@ -0,0 +1,283 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"alpha.com/internal/alpha.com/application/controller/request"
+	"alpha.com/internal/alpha.com/application/handler/jwt"
+	"alpha.com/internal/alpha.com/application/handler/mfa"
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"alpha.com/internal/alpha.com/pkg/utils"
+	"alpha.com/internal/alpha.com/pkg/validation"
+	"github.com/gofiber/fiber/v2"
+)
+
+type IMfaController interface {
+	Enroll(ctx *fiber.Ctx) error
+	Confirm(ctx *fiber.Ctx) error
+	Disable(ctx *fiber.Ctx) error
+	RegenerateRecoveryCodes(ctx *fiber.Ctx) error
+	Verify(ctx *fiber.Ctx) error
+}
+
+type MfaController struct {
+	mfaCommandHandler mfa.ICommandHandler
+	jwtCommandHandler jwt.ICommandHandler
+	customValidator   validation.ICustomValidator
+}
+
+func NewMfaController(mfaCommandHandler mfa.ICommandHandler,
+	jwtCommandHandler jwt.ICommandHandler,
+	customValidator validation.ICustomValidator,
+) IMfaController {
+	return &MfaController{
+		mfaCommandHandler: mfaCommandHandler,
+		jwtCommandHandler: jwtCommandHandler,
+		customValidator:   customValidator,
+	}
+}
+
+// Enroll godoc
+//
+//	@Summary		This method used for starting two-factor authentication enrollment
+//	@Description	returns a new TOTP secret and its otpauth uri for QR display
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200
+//
+//	@Failure		401
+//	@Failure		409
+//	@Failure		500
+//	@Router			/api/v1/alpha/auth/mfa/enroll [post]
+func (u *MfaController) Enroll(ctx *fiber.Ctx) error {
+	userCtx := ctx.UserContext().Value("user").(*utils.UserContext)
+
+	secret, uri, err := u.mfaCommandHandler.Enroll(ctx.UserContext(), userCtx.UserID)
+
+	if err != nil {
+		return mfaError(ctx, "Enroll", err)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(
+		map[string]interface{}{
+			"message": "Two-Factor Authentication Enrollment Started",
+			"response": map[string]interface{}{
+				"secret":     secret,
+				"otpauthUri": uri,
+			},
+		},
+	)
+}
+
+// Confirm godoc
+//
+//	@Summary		This method used for enabling two-factor authentication with a first code
+//	@Description	returns the recovery codes, they are shown only once
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//
+// @Param Authorization header string true "Bearer {token}"
+// @Param requestBody body request.MfaCodeRequest nil "Handle Request Body"
+// @Success 200
+//
+//	@Failure		400
+//	@Failure		401
+//	@Failure		409
+//	@Failure		429
+//	@Failure		500
+//	@Router			/api/v1/alpha/auth/mfa/confirm [post]
+func (u *MfaController) Confirm(ctx *fiber.Ctx) error {
+	userCtx := ctx.UserContext().Value("user").(*utils.UserContext)
+
+	var req request.MfaCodeRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		logger.FromContext(ctx.UserContext()).Warn("mfaController.Confirm error while binding json", "error", err)
+		return fiber.NewError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := u.customValidator.Validate(req); err != nil {
+		logger.FromContext(ctx.UserContext()).Warn("mfaController.Confirm invalid request", "errors", err)
+		return ctx.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	recoveryCodes, err := u.mfaCommandHandler.Confirm(ctx.UserContext(), req.ToCommand(userCtx.UserID))
+
+	if err != nil {
+		return mfaError(ctx, "Confirm", err)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(
+		map[string]interface{}{
+			"message": "Two-Factor Authentication Successfully Enabled",
+			"response": map[string]interface{}{
+				"recoveryCodes": recoveryCodes,
+			},
+		},
+	)
+}
+
+// Disable godoc
+//
+//	@Summary		This method used for disabling two-factor authentication
+//	@Description	needs a current code or a recovery code
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//
+// @Param Authorization header string true "Bearer {token}"
+// @Param requestBody body request.MfaCodeRequest nil "Handle Request Body"
+// @Success 200
+//
+//	@Failure		400
+//	@Failure		401
+//	@Failure		429
+//	@Failure		500
+//	@Router			/api/v1/alpha/auth/mfa/disable [post]
+func (u *MfaController) Disable(ctx *fiber.Ctx) error {
+	userCtx := ctx.UserContext().Value("user").(*utils.UserContext)
+
+	var req request.MfaCodeRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		logger.FromContext(ctx.UserContext()).Warn("mfaController.Disable error while binding json", "error", err)
+		return fiber.NewError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := u.customValidator.Validate(req); err != nil {
+		logger.FromContext(ctx.UserContext()).Warn("mfaController.Disable invalid request", "errors", err)
+		return ctx.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	if err := u.mfaCommandHandler.Disable(ctx.UserContext(), req.ToCommand(userCtx.UserID)); err != nil {
+		return mfaError(ctx, "Disable", err)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(
+		map[string]interface{}{
+			"message": "Two-Factor Authentication Successfully Disabled",
+		},
+	)
+}
+
+// RegenerateRecoveryCodes godoc
+//
+//	@Summary		This method used for replacing the recovery codes
+//	@Description	needs a current code or a recovery code, old recovery codes stop working
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//
+// @Param Authorization header string true "Bearer {token}"
+// @Param requestBody body request.MfaCodeRequest nil "Handle Request Body"
+// @Success 200
+//
+//	@Failure		400
+//	@Failure		401
+//	@Failure		429
+//	@Failure		500
+//	@Router			/api/v1/alpha/auth/mfa/recovery-codes [post]
+func (u *MfaController) RegenerateRecoveryCodes(ctx *fiber.Ctx) error {
+	userCtx := ctx.UserContext().Value("user").(*utils.UserContext)
+
+	var req request.MfaCodeRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		logger.FromContext(ctx.UserContext()).Warn("mfaController.RegenerateRecoveryCodes error while binding json", "error", err)
+		return fiber.NewError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := u.customValidator.Validate(req); err != nil {
+		logger.FromContext(ctx.UserContext()).Warn("mfaController.RegenerateRecoveryCodes invalid request", "errors", err)
+		return ctx.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	recoveryCodes, err := u.mfaCommandHandler.RegenerateRecoveryCodes(ctx.UserContext(), req.ToCommand(userCtx.UserID))
+
+	if err != nil {
+		return mfaError(ctx, "RegenerateRecoveryCodes", err)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(
+		map[string]interface{}{
+			"message": "Recovery Codes Successfully Regenerated",
+			"response": map[string]interface{}{
+				"recoveryCodes": recoveryCodes,
+			},
+		},
+	)
+}
+
+// Verify godoc
+//
+//	@Summary		This method used for completing a sign in with two-factor authentication
+//	@Description	exchanges the sign in challenge token and a code for tokens
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//
+// @Param requestBody body request.MfaVerifyRequest nil "Handle Request Body"
+// @Success 200
+//
+//	@Failure		400
+//	@Failure		401
+//	@Failure		429
+//	@Failure		500
+//	@Router			/api/v1/alpha/auth/mfa/verify [post]
+func (u *MfaController) Verify(ctx *fiber.Ctx) error {
+	var req request.MfaVerifyRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		logger.FromContext(ctx.UserContext()).Warn("mfaController.Verify error while binding json", "error", err)
+		return fiber.NewError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := u.customValidator.Validate(req); err != nil {
+		logger.FromContext(ctx.UserContext()).Warn("mfaController.Verify invalid request", "errors", err)
+		return ctx.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	userID, err := u.mfaCommandHandler.Verify(ctx.UserContext(), req.ToCommand())
+
+	if err != nil {
+		return mfaError(ctx, "Verify", err)
+	}
+
+	accessToken, refreshToken, err := u.jwtCommandHandler.Create(ctx.UserContext(), jwt.Command{
+		UserID:    userID,
+		IP:        ctx.IP(),
+		UserAgent: ctx.Get(fiber.HeaderUserAgent),
+	})
+
+	if err != nil {
+		logger.FromContext(ctx.UserContext()).Error("mfaController.Verify error while creating jwt tokens", "error", err)
+		return tokenError(err)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(
+		map[string]interface{}{
+			"message": "User Successfully Sign In",
+			"response": map[string]interface{}{
+				"accessToken":  accessToken,
+				"refreshToken": refreshToken,
+			},
+		},
+	)
+}
+
+func mfaError(ctx *fiber.Ctx, method string, err error) error {
+	switch {
+	case errors.Is(err, mfa.ErrInvalidMfaCode), errors.Is(err, mfa.ErrInvalidChallenge):
+		return fiber.NewError(http.StatusUnauthorized, err.Error())
+	case errors.Is(err, mfa.ErrTooManyMfaAttempts):
+		return fiber.NewError(http.StatusTooManyRequests, err.Error())
+	case errors.Is(err, mfa.ErrMfaAlreadyEnabled):
+		return fiber.NewError(http.StatusConflict, err.Error())
+	case errors.Is(err, mfa.ErrMfaNotEnabled), errors.Is(err, mfa.ErrMfaNotEnrolled):
+		return fiber.NewError(http.StatusBadRequest, err.Error())
+	}
+
+	logger.FromContext(ctx.UserContext()).Error("mfaController."+method+" failed", "error", err)
+	return errorResponse(err, fiber.NewError(http.StatusInternalServerError, "Internal Server Error"))
+}
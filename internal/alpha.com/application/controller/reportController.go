@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"net/http"
+
+	"alpha.com/internal/alpha.com/application/controller/request"
+	"alpha.com/internal/alpha.com/application/handler/report"
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"alpha.com/internal/alpha.com/pkg/utils"
+	"alpha.com/internal/alpha.com/pkg/validation"
+	"github.com/gofiber/fiber/v2"
+)
+
+type IReportController interface {
+	Save(ctx *fiber.Ctx) error
+}
+
+type ReportController struct {
+	reportCommandHandler report.ICommandHandler
+	customValidator      validation.ICustomValidator
+}
+
+func NewReportController(reportCommandHandler report.ICommandHandler, customValidator validation.ICustomValidator) IReportController {
+	return &ReportController{
+		reportCommandHandler: reportCommandHandler,
+		customValidator:      customValidator,
+	}
+}
+
+// Save godoc
+
+//	@Summary		This method used for reporting a job or user to moderators
+//	@Description	saving new report
+//
+//	@Tags			Reports
+//	@Accept			json
+//	@Produce		json
+//
+// @Param requestBody body request.ReportCreateRequest nil "Handle Request Body"
+//
+// @Param Authorization header string true "Bearer {token}"
+//
+// @Success 200
+//
+//	@Failure		400
+//	@Failure		401
+//	@Failure		500
+//	@Router			/api/v1/alpha/report [post]
+func (u *ReportController) Save(ctx *fiber.Ctx) error {
+	var req request.ReportCreateRequest
+	err := ctx.BodyParser(&req)
+
+	if err != nil {
+		logger.FromContext(ctx.UserContext()).Warn("ReportController.Save error while binding json", "error", err)
+		return fiber.NewError(http.StatusBadRequest, err.Error())
+	}
+
+	logger.FromContext(ctx.UserContext()).Info("ReportController.Save started", "request", req)
+
+	if err := u.customValidator.Validate(req); err != nil {
+		logger.FromContext(ctx.UserContext()).Warn("ReportController.Save invalid request", "request", req, "errors", err)
+		return ctx.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	userCtx := ctx.UserContext().Value("user").(*utils.UserContext)
+
+	errOfCommandHandler := u.reportCommandHandler.Save(ctx.UserContext(), req.ToCommand(), userCtx.UserID)
+
+	if errOfCommandHandler != nil {
+		return errorResponse(errOfCommandHandler, fiber.NewError(http.StatusBadRequest, errOfCommandHandler.Error()))
+	}
+
+	return ctx.Status(http.StatusOK).JSON(
+		map[string]interface{}{
+			"message": "Report Successfully Created",
+		},
+	)
+}
@@ -0,0 +1,44 @@
+package request
+
+import (
+	"time"
+
+	"alpha.com/internal/alpha.com/application/handler/admin"
+)
+
+type AdminUserStatusRequest struct {
+	Reason string     `json:"reason" validate:"required"`
+	Until  *time.Time `json:"until"`
+}
+
+func (req *AdminUserStatusRequest) ToCommand(userID string) admin.CommandUserStatus {
+	return admin.CommandUserStatus{
+		UserID: userID,
+		Reason: req.Reason,
+		Until:  req.Until,
+	}
+}
+
+type AdminJobModerationRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+func (req *AdminJobModerationRequest) ToCommand(jobID string) admin.CommandJobModeration {
+	return admin.CommandJobModeration{
+		JobID:  jobID,
+		Reason: req.Reason,
+	}
+}
+
+type AdminResolveReportRequest struct {
+	Status     string `json:"status" validate:"required,oneof=resolved dismissed"`
+	Resolution string `json:"resolution" validate:"required"`
+}
+
+func (req *AdminResolveReportRequest) ToCommand(reportID string) admin.CommandResolveReport {
+	return admin.CommandResolveReport{
+		ReportID:   reportID,
+		Status:     req.Status,
+		Resolution: req.Resolution,
+	}
+}
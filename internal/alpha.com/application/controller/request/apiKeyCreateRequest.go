@@ -0,0 +1,23 @@
+package request
+
+import (
+	"time"
+
+	"alpha.com/internal/alpha.com/application/handler/apiKey"
+)
+
+type ApiKeyCreateRequest struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,oneof=jobs:write applications:read"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+func (req *ApiKeyCreateRequest) ToCommand(businessAccountID string, userID string) apiKey.Command {
+	return apiKey.Command{
+		BusinessAccountID: businessAccountID,
+		UserID:            userID,
+		Name:              req.Name,
+		Scopes:            req.Scopes,
+		ExpiresAt:         req.ExpiresAt,
+	}
+}
@@ -0,0 +1,17 @@
+package request
+
+import "alpha.com/internal/alpha.com/application/handler/magicLink"
+
+type MagicLinkRequest struct {
+	Email         string `json:"email" validate:"required,email"`
+	CreateAccount bool   `json:"createAccount"`
+}
+
+func (req *MagicLinkRequest) ToCommand(deviceID string, ip string) magicLink.CommandRequest {
+	return magicLink.CommandRequest{
+		Email:         req.Email,
+		CreateAccount: req.CreateAccount,
+		DeviceID:      deviceID,
+		IP:            ip,
+	}
+}
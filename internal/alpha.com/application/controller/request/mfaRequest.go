@@ -0,0 +1,26 @@
+package request
+
+import "alpha.com/internal/alpha.com/application/handler/mfa"
+
+type MfaCodeRequest struct {
+	Code string `json:"code" validate:"required,min=6,max=16" sensitive:"true"`
+}
+
+func (req *MfaCodeRequest) ToCommand(userID string) mfa.Command {
+	return mfa.Command{
+		UserID: userID,
+		Code:   req.Code,
+	}
+}
+
+type MfaVerifyRequest struct {
+	ChallengeToken string `json:"challengeToken" validate:"required" sensitive:"true"`
+	Code           string `json:"code" validate:"required,min=6,max=16" sensitive:"true"`
+}
+
+func (req *MfaVerifyRequest) ToCommand() mfa.CommandVerify {
+	return mfa.CommandVerify{
+		ChallengeToken: req.ChallengeToken,
+		Code:           req.Code,
+	}
+}
@@ -0,0 +1,17 @@
+package request
+
+import "alpha.com/internal/alpha.com/application/handler/report"
+
+type ReportCreateRequest struct {
+	TargetType string `json:"targetType" validate:"required,oneof=job user"`
+	TargetID   string `json:"targetId" validate:"required"`
+	Reason     string `json:"reason" validate:"required,min=5"`
+}
+
+func (req *ReportCreateRequest) ToCommand() report.Command {
+	return report.Command{
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		Reason:     req.Reason,
+	}
+}
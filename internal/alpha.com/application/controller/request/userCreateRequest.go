@@ -6,7 +6,7 @@ type UserCreateRequest struct {
 	FirstName string `json:"firstName" validate:"required,min=2"`
 	LastName  string `json:"lastName" validate:"required"`
 	Email     string `json:"email" validate:"required"`
-	Password  string `json:"password" validate:"required,min=8,max=16"`
+	Password  string `json:"password" validate:"required,max=128" sensitive:"true"`
 	Age       int32  `json:"age" validate:"required"`
 }
 
@@ -4,7 +4,7 @@ import "alpha.com/internal/alpha.com/application/handler/user"
 
 type UserSignInRequest struct {
 	Email    string `json:"email" validate:"required"`
-	Password string `json:"password" validate:"required,min=8,max=16"`
+	Password string `json:"password" validate:"required,max=128" sensitive:"true"`
 }
 
 func (req *UserSignInRequest) ToCommand() user.CommandSignIn {
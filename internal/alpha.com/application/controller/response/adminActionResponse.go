@@ -0,0 +1,39 @@
+package response
+
+import (
+	"time"
+
+	"alpha.com/internal/alpha.com/domain"
+)
+
+type AdminActionResponse struct {
+	Id         string    `json:"_id"`
+	AdminID    string    `json:"adminId"`
+	Action     string    `json:"action"`
+	TargetType string    `json:"targetType"`
+	TargetID   string    `json:"targetId"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+func ToAdminActionResponse(adminAction *domain.AdminAction) AdminActionResponse {
+	return AdminActionResponse{
+		Id:         adminAction.Id.Hex(),
+		AdminID:    adminAction.AdminID.Hex(),
+		Action:     string(adminAction.Action),
+		TargetType: adminAction.TargetType,
+		TargetID:   adminAction.TargetID.Hex(),
+		Reason:     adminAction.Reason,
+		CreatedAt:  adminAction.CreatedAt,
+	}
+}
+
+func ToAdminActionResponseList(adminActions []*domain.AdminAction) []AdminActionResponse {
+	var response = make([]AdminActionResponse, 0)
+
+	for _, adminAction := range adminActions {
+		response = append(response, ToAdminActionResponse(adminAction))
+	}
+
+	return response
+}
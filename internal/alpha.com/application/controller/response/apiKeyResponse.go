@@ -0,0 +1,48 @@
+package response
+
+import (
+	"time"
+
+	"alpha.com/internal/alpha.com/domain"
+)
+
+type ApiKeyResponse struct {
+	Id                string     `json:"_id"`
+	BusinessAccountID string     `json:"businessAccountId"`
+	Name              string     `json:"name"`
+	Prefix            string     `json:"prefix"`
+	Scopes            []string   `json:"scopes"`
+	ExpiresAt         *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt        *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt         *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt         time.Time  `json:"createdAt"`
+}
+
+func ToApiKeyResponse(apiKey *domain.ApiKey) ApiKeyResponse {
+	scopes := make([]string, 0, len(apiKey.Scopes))
+	for _, scope := range apiKey.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+
+	return ApiKeyResponse{
+		Id:                apiKey.Id.Hex(),
+		BusinessAccountID: apiKey.BusinessAccountID.Hex(),
+		Name:              apiKey.Name,
+		Prefix:            apiKey.Prefix,
+		Scopes:            scopes,
+		ExpiresAt:         apiKey.ExpiresAt,
+		LastUsedAt:        apiKey.LastUsedAt,
+		RevokedAt:         apiKey.RevokedAt,
+		CreatedAt:         apiKey.CreatedAt,
+	}
+}
+
+func ToApiKeyResponseList(apiKeys []*domain.ApiKey) []ApiKeyResponse {
+	var response = make([]ApiKeyResponse, 0)
+
+	for _, apiKey := range apiKeys {
+		response = append(response, ToApiKeyResponse(apiKey))
+	}
+
+	return response
+}
@@ -0,0 +1,33 @@
+package response
+
+import (
+	"time"
+
+	"alpha.com/internal/alpha.com/domain"
+)
+
+type ExternalIdentityResponse struct {
+	Provider   string    `json:"provider"`
+	Email      string    `json:"email"`
+	LinkedAt   time.Time `json:"linkedAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+}
+
+func ToExternalIdentityResponse(identity *domain.ExternalIdentity) ExternalIdentityResponse {
+	return ExternalIdentityResponse{
+		Provider:   identity.Provider,
+		Email:      identity.Email,
+		LinkedAt:   identity.LinkedAt,
+		LastUsedAt: identity.LastUsedAt,
+	}
+}
+
+func ToExternalIdentityResponseList(identities []*domain.ExternalIdentity) []ExternalIdentityResponse {
+	var response = make([]ExternalIdentityResponse, 0)
+
+	for _, identity := range identities {
+		response = append(response, ToExternalIdentityResponse(identity))
+	}
+
+	return response
+}
@@ -13,6 +13,7 @@ type JobResponse struct {
 	Description       string    `json:"description"`
 	Price             float32   `json:"price"`
 	Category          string    `json:"category"`
+	Status            string    `json:"status"`
 	CreatedAt         time.Time `json:"createdAt"`
 	UpdatedAt         time.Time `json:"updatedAt"`
 }
@@ -25,6 +26,7 @@ func ToJobResponse(job *domain.Job) JobResponse {
 		Description:       job.Description,
 		Price:             job.Price,
 		Category:          job.Category,
+		Status:            string(job.Status),
 		CreatedAt:         job.CreatedAt,
 		UpdatedAt:         job.UpdatedAt,
 	}
@@ -7,22 +7,26 @@ import (
 )
 
 type JwtResponse struct {
-	Id           string    `json:"_id"`
-	UserID       string    `json:"userId"`
-	AccessToken  string    `json:"accessToken"`
-	RefreshToken string    `json:"refreshToken"`
-	CreatedAt    time.Time `json:"createdAt"`
-	UpdatedAt    time.Time `json:"updatedAt"`
+	Id        string     `json:"_id"`
+	UserID    string     `json:"userId"`
+	FamilyID  string     `json:"familyId"`
+	RotatedAt *time.Time `json:"rotatedAt,omitempty"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
 }
 
 func ToJwtResponse(jwt *domain.Jwt) JwtResponse {
 	return JwtResponse{
-		Id:           jwt.Id.Hex(),
-		UserID:       jwt.UserID.Hex(),
-		AccessToken:  jwt.AccessToken,
-		RefreshToken: jwt.RefreshToken,
-		CreatedAt:    jwt.CreatedAt,
-		UpdatedAt:    jwt.UpdatedAt,
+		Id:        jwt.Id.Hex(),
+		UserID:    jwt.UserID.Hex(),
+		FamilyID:  jwt.FamilyID,
+		RotatedAt: jwt.RotatedAt,
+		RevokedAt: jwt.RevokedAt,
+		ExpiresAt: jwt.ExpiresAt,
+		CreatedAt: jwt.CreatedAt,
+		UpdatedAt: jwt.UpdatedAt,
 	}
 }
 
@@ -0,0 +1,50 @@
+package response
+
+import (
+	"time"
+
+	"alpha.com/internal/alpha.com/domain"
+)
+
+type ReportResponse struct {
+	Id         string    `json:"_id"`
+	ReporterID string    `json:"reporterId"`
+	TargetType string    `json:"targetType"`
+	TargetID   string    `json:"targetId"`
+	Reason     string    `json:"reason"`
+	Status     string    `json:"status"`
+	ResolvedBy string    `json:"resolvedBy,omitempty"`
+	Resolution string    `json:"resolution,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+func ToReportResponse(report *domain.Report) ReportResponse {
+	response := ReportResponse{
+		Id:         report.Id.Hex(),
+		ReporterID: report.ReporterID.Hex(),
+		TargetType: string(report.TargetType),
+		TargetID:   report.TargetID.Hex(),
+		Reason:     report.Reason,
+		Status:     string(report.Status),
+		Resolution: report.Resolution,
+		CreatedAt:  report.CreatedAt,
+		UpdatedAt:  report.UpdatedAt,
+	}
+
+	if report.ResolvedBy != nil {
+		response.ResolvedBy = report.ResolvedBy.Hex()
+	}
+
+	return response
+}
+
+func ToReportResponseList(reports []*domain.Report) []ReportResponse {
+	var response = make([]ReportResponse, 0)
+
+	for _, report := range reports {
+		response = append(response, ToReportResponse(report))
+	}
+
+	return response
+}
@@ -0,0 +1,39 @@
+package response
+
+import (
+	"time"
+
+	"alpha.com/internal/alpha.com/domain"
+)
+
+type SessionResponse struct {
+	Id         string    `json:"_id"`
+	Device     string    `json:"device"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"userAgent"`
+	Current    bool      `json:"current"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+}
+
+func ToSessionResponse(session *domain.Session, currentSessionID string) SessionResponse {
+	return SessionResponse{
+		Id:         session.Id.Hex(),
+		Device:     session.Device,
+		IP:         session.IP,
+		UserAgent:  session.UserAgent,
+		Current:    session.Id.Hex() == currentSessionID,
+		CreatedAt:  session.CreatedAt,
+		LastUsedAt: session.LastUsedAt,
+	}
+}
+
+func ToSessionResponseList(sessions []*domain.Session, currentSessionID string) []SessionResponse {
+	var response = make([]SessionResponse, 0)
+
+	for _, session := range sessions {
+		response = append(response, ToSessionResponse(session, currentSessionID))
+	}
+
+	return response
+}
@@ -7,24 +7,30 @@ import (
 )
 
 type UserResponse struct {
-	Id        string    `json:"_id"`
-	FirstName string    `json:"firstName"`
-	LastName  string    `json:"lastName"`
-	Email     string    `json:"email"`
-	Age       int32     `json:"age"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	Id         string    `json:"_id"`
+	FirstName  string    `json:"firstName"`
+	LastName   string    `json:"lastName"`
+	Email      string    `json:"email"`
+	Age        int32     `json:"age"`
+	Roles      []string  `json:"roles"`
+	Status     string    `json:"status"`
+	MfaEnabled bool      `json:"mfaEnabled"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
 }
 
 func ToUserResponse(user *domain.User) UserResponse {
 	return UserResponse{
-		Id:        user.Id.Hex(),
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Email:     user.Email,
-		Age:       user.Age,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		Id:         user.Id.Hex(),
+		FirstName:  user.FirstName,
+		LastName:   user.LastName,
+		Email:      user.Email,
+		Age:        user.Age,
+		Roles:      domain.RolesToStrings(user.Roles),
+		Status:     string(user.Status),
+		MfaEnabled: user.MfaEnabled(),
+		CreatedAt:  user.CreatedAt,
+		UpdatedAt:  user.UpdatedAt,
 	}
 }
 
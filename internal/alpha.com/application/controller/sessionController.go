@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"alpha.com/internal/alpha.com/application/controller/response"
+	"alpha.com/internal/alpha.com/application/handler/session"
+	"alpha.com/internal/alpha.com/application/query"
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"alpha.com/internal/alpha.com/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ISessionController interface {
+	Logout(ctx *fiber.Ctx) error
+	GetSessions(ctx *fiber.Ctx) error
+	RevokeSession(ctx *fiber.Ctx) error
+	RevokeAllSessions(ctx *fiber.Ctx) error
+}
+
+type SessionController struct {
+	sessionQueryService   query.ISessionQueryService
+	sessionCommandHandler session.ICommandHandler
+}
+
+func NewSessionController(sessionQueryService query.ISessionQueryService, sessionCommandHandler session.ICommandHandler) ISessionController {
+	return &SessionController{
+		sessionQueryService:   sessionQueryService,
+		sessionCommandHandler: sessionCommandHandler,
+	}
+}
+
+// Logout godoc
+//
+//	@Summary		This method used for signing out the current session
+//	@Description	logout
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200
+//
+//	@Failure		400
+//	@Failure		401
+//	@Failure		500
+//	@Router			/api/v1/alpha/auth/logout [post]
+func (u *SessionController) Logout(ctx *fiber.Ctx) error {
+	userCtx := ctx.UserContext().Value("user").(*utils.UserContext)
+
+	if userCtx.SessionID == "" {
+		return fiber.NewError(http.StatusBadRequest, "Access token is not bound to a session, please sign in again")
+	}
+
+	if err := u.sessionCommandHandler.Revoke(ctx.UserContext(), userCtx.SessionID, userCtx.UserID); err != nil {
+		return sessionError(ctx, err)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(
+		map[string]interface{}{
+			"message": "Successfully Signed Out",
+		},
+	)
+}
+
+// GetSessions godoc
+//
+//	@Summary		This method used for listing the signed-in sessions of the current user
+//	@Description	get sessions
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200 {object} []response.SessionResponse
+//
+//	@Failure		401
+//	@Failure		500
+//	@Router			/api/v1/alpha/me/sessions [get]
+func (u *SessionController) GetSessions(ctx *fiber.Ctx) error {
+	userCtx := ctx.UserContext().Value("user").(*utils.UserContext)
+
+	sessions, err := u.sessionQueryService.GetActiveSessions(ctx.UserContext(), userCtx.UserID)
+
+	if err != nil {
+		logger.FromContext(ctx.UserContext()).Error("sessionController.GetSessions error while getting sessions", "error", err)
+		return errorResponse(err, fiber.NewError(http.StatusInternalServerError, err.Error()))
+	}
+
+	return ctx.Status(http.StatusOK).JSON(response.ToSessionResponseList(sessions, userCtx.SessionID))
+}
+
+// RevokeSession godoc
+//
+//	@Summary		This method used for signing out one session of the current user
+//	@Description	revoke session
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			sessionId	path		string	true	"sessionId"
+//
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200
+//
+//	@Failure		401
+//	@Failure		404
+//	@Failure		500
+//	@Router			/api/v1/alpha/me/sessions/{sessionId} [delete]
+func (u *SessionController) RevokeSession(ctx *fiber.Ctx) error {
+	userCtx := ctx.UserContext().Value("user").(*utils.UserContext)
+
+	if err := u.sessionCommandHandler.Revoke(ctx.UserContext(), ctx.Params("sessionId"), userCtx.UserID); err != nil {
+		return sessionError(ctx, err)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(
+		map[string]interface{}{
+			"message": "Session Successfully Revoked",
+		},
+	)
+}
+
+// RevokeAllSessions godoc
+//
+//	@Summary		This method used for signing out every session of the current user
+//	@Description	sign out everywhere
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200
+//
+//	@Failure		401
+//	@Failure		500
+//	@Router			/api/v1/alpha/me/sessions [delete]
+func (u *SessionController) RevokeAllSessions(ctx *fiber.Ctx) error {
+	userCtx := ctx.UserContext().Value("user").(*utils.UserContext)
+
+	if err := u.sessionCommandHandler.RevokeAll(ctx.UserContext(), userCtx.UserID); err != nil {
+		return sessionError(ctx, err)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(
+		map[string]interface{}{
+			"message": "Successfully Signed Out Everywhere",
+		},
+	)
+}
+
+func sessionError(ctx *fiber.Ctx, err error) error {
+	if errors.Is(err, session.ErrSessionNotFound) {
+		return fiber.NewError(http.StatusNotFound, err.Error())
+	}
+
+	logger.FromContext(ctx.UserContext()).Error("sessionController error while revoking session", "error", err)
+	return errorResponse(err, fiber.NewError(http.StatusInternalServerError, err.Error()))
+}
@@ -1,18 +1,17 @@
 package controller
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
-	"alpha.com/configuration"
 	"alpha.com/internal/alpha.com/application/controller/request"
 	"alpha.com/internal/alpha.com/application/controller/response"
+	"alpha.com/internal/alpha.com/application/handler/jwt"
 	"alpha.com/internal/alpha.com/application/handler/user"
 	"alpha.com/internal/alpha.com/application/query"
-	"alpha.com/internal/alpha.com/pkg/server/helpers"
-	"alpha.com/internal/alpha.com/pkg/utils"
+	"alpha.com/internal/alpha.com/pkg/logger"
 	"alpha.com/internal/alpha.com/pkg/validation"
 	"github.com/gofiber/fiber/v2"
 )
@@ -22,19 +21,29 @@ type IUserController interface {
 	GetUser(ctx *fiber.Ctx) error
 	GetUserById(ctx *fiber.Ctx) error
 	SignIn(ctx *fiber.Ctx) error
+	Unlock(ctx *fiber.Ctx) error
 }
 
 type UserController struct {
 	userQueryService   query.IUserQueryService
 	userCommandHandler user.ICommandHandler
+	jwtCommandHandler  jwt.ICommandHandler
 	customValidator    validation.ICustomValidator
+	passwordPolicy     validation.IPasswordPolicy
 }
 
-func NewUserController(userQueryService query.IUserQueryService, userCommandHandler user.ICommandHandler, customValidator validation.ICustomValidator) IUserController {
+func NewUserController(userQueryService query.IUserQueryService,
+	userCommandHandler user.ICommandHandler,
+	jwtCommandHandler jwt.ICommandHandler,
+	customValidator validation.ICustomValidator,
+	passwordPolicy validation.IPasswordPolicy,
+) IUserController {
 	return &UserController{
 		userQueryService:   userQueryService,
 		userCommandHandler: userCommandHandler,
+		jwtCommandHandler:  jwtCommandHandler,
 		customValidator:    customValidator,
+		passwordPolicy:     passwordPolicy,
 	}
 }
 
@@ -60,64 +69,50 @@ func (u *UserController) Save(ctx *fiber.Ctx) error {
 	err := ctx.BodyParser(&req)
 
 	if err != nil {
-		fmt.Printf("userController.Save ERROR -> There was an error while binding json - ERROR: %v\n", err.Error())
+		logger.FromContext(ctx.UserContext()).Warn("userController.Save error while binding json", "error", err)
 		return fiber.NewError(http.StatusBadRequest, err.Error())
 	}
 
-	fmt.Printf("userController.Save STARTED with request: %#v\n", req)
+	logger.FromContext(ctx.UserContext()).Info("userController.Save started", "request", req)
 
 	if err := u.customValidator.Validate(req); err != nil {
-		fmt.Printf("userController.Save INVALID request: %#v - ERROR: %#v\n", req, err)
+		logger.FromContext(ctx.UserContext()).Warn("userController.Save invalid request", "request", req, "errors", err)
+		return ctx.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	if err := u.passwordPolicy.Check(req.Password, req.Email, req.FirstName, req.LastName); err != nil {
+		logger.FromContext(ctx.UserContext()).Warn("userController.Save password policy violated", "errors", err)
 		return ctx.Status(http.StatusBadRequest).JSON(err)
 	}
 
 	userID, errOfCommandHandler := u.userCommandHandler.Save(ctx.UserContext(), req.ToCommand())
 
 	if errOfCommandHandler != nil {
-		return fiber.NewError(http.StatusBadRequest, errOfCommandHandler.Error())
+		return errorResponse(errOfCommandHandler, fiber.NewError(http.StatusBadRequest, errOfCommandHandler.Error()))
 	}
 
 	if userID == "" {
-		fmt.Printf("userController.Save ERROR -> There was an error while binding json - ERROR: %v\n", "Internal Server Error")
+		logger.FromContext(ctx.UserContext()).Error("userController.Save no user id returned")
 		return fiber.NewError(http.StatusBadRequest, "Internal Server Error")
 	}
 
-	// Create a map to represent the JSON data
-	requestData := map[string]string{"userId": userID}
+	accessToken, refreshToken, err := u.jwtCommandHandler.Create(ctx.UserContext(), jwt.Command{
+		UserID:    userID,
+		IP:        ctx.IP(),
+		UserAgent: ctx.Get(fiber.HeaderUserAgent),
+	})
 
-	// Convert the map to JSON
-	jsonData, err := json.Marshal(requestData)
 	if err != nil {
-		fmt.Println("Error marshalling JSON:", err)
-		return fiber.NewError(http.StatusInternalServerError, err.Error())
-	}
-
-	body, errOfHelper := helpers.HttpPostHelper(configuration.BACKEND_URL+"/api/v1/alpha/jwt", bytes.NewBuffer(jsonData))
-
-	if errOfHelper != nil {
-		fmt.Printf("userController.Save ERROR -> There was an error while sending request to jwt - ERROR: %v\n", errOfHelper.Error())
-		return fiber.NewError(http.StatusInternalServerError, errOfHelper.Error())
-	}
-
-	if body == nil {
-		fmt.Printf("userController.Save ERROR -> There was an error while sending request to jwt - ERROR: %v\n", "body is nil")
-		return fiber.NewError(http.StatusBadRequest, "Body is nil")
-	}
-
-	var data map[string]string
-
-	errOfData := json.Unmarshal([]byte(string(body)), &data)
-	if errOfData != nil {
-		fmt.Printf("userController.Save ERROR -> There was an error while binding data - ERROR: %v\n:", errOfData.Error())
-		return fiber.NewError(http.StatusInternalServerError, errOfData.Error())
+		logger.FromContext(ctx.UserContext()).Error("userController.Save error while creating jwt tokens", "error", err)
+		return tokenError(err)
 	}
 
 	return ctx.Status(http.StatusOK).JSON(
 		map[string]interface{}{
 			"message": "User Created Successfully",
 			"response": map[string]interface{}{
-				"accessToken":  data["accessToken"],
-				"refreshToken": data["refreshToken"],
+				"accessToken":  accessToken,
+				"refreshToken": refreshToken,
 			},
 		},
 	)
@@ -137,7 +132,8 @@ func (u *UserController) Save(ctx *fiber.Ctx) error {
 // @Success 200
 //
 //	@Failure		400
-//	@Failure		404
+//	@Failure		401
+//	@Failure		429
 //	@Failure		500
 //	@Router			/api/v1/alpha/user/sign-in [post]
 func (u *UserController) SignIn(ctx *fiber.Ctx) error {
@@ -145,64 +141,68 @@ func (u *UserController) SignIn(ctx *fiber.Ctx) error {
 	err := ctx.BodyParser(&req)
 
 	if err != nil {
-		fmt.Printf("userController.SignIn ERROR -> There was an error while binding json - ERROR: %v\n", err.Error())
+		logger.FromContext(ctx.UserContext()).Warn("userController.SignIn error while binding json", "error", err)
 		return fiber.NewError(http.StatusBadRequest, err.Error())
 	}
 
-	fmt.Printf("userController.SignIn STARTED with request: %#v\n", req)
+	logger.FromContext(ctx.UserContext()).Info("userController.SignIn started", "request", req)
 
 	if err := u.customValidator.Validate(req); err != nil {
-		fmt.Printf("userController.SignIn INVALID request: %#v - ERROR: %#v\n", req, err)
+		logger.FromContext(ctx.UserContext()).Warn("userController.SignIn invalid request", "request", req, "errors", err)
 		return ctx.Status(http.StatusBadRequest).JSON(err)
 	}
 
-	userID, errOfCommandHandler := u.userCommandHandler.SignIn(ctx.UserContext(), req.ToCommand())
+	command := req.ToCommand()
+	command.IP = ctx.IP()
+
+	signInResult, errOfCommandHandler := u.userCommandHandler.SignIn(ctx.UserContext(), command)
+
+	var throttledError *user.ThrottledError
+	switch {
+	case errors.As(errOfCommandHandler, &throttledError):
+		ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(throttledError.RetryAfter.Seconds()))))
+		return fiber.NewError(http.StatusTooManyRequests, throttledError.Error())
+	case errors.Is(errOfCommandHandler, user.ErrInvalidCredentials):
+		return fiber.NewError(http.StatusUnauthorized, errOfCommandHandler.Error())
+	case errOfCommandHandler != nil:
+		logger.FromContext(ctx.UserContext()).Error("userController.SignIn error while signing in", "error", errOfCommandHandler)
+		return errorResponse(errOfCommandHandler, fiber.NewError(http.StatusInternalServerError, "Internal Server Error"))
+	}
 
-	if errOfCommandHandler != nil {
-		return fiber.NewError(http.StatusBadRequest, errOfCommandHandler.Error())
+	if signInResult.MfaChallengeToken != "" {
+		return ctx.Status(http.StatusOK).JSON(
+			map[string]interface{}{
+				"message": "Two-Factor Authentication Required",
+				"response": map[string]interface{}{
+					"mfaRequired":    true,
+					"challengeToken": signInResult.MfaChallengeToken,
+				},
+			},
+		)
 	}
 
-	if userID == "" {
-		fmt.Printf("userController.SignIn ERROR -> There was an error while binding json - ERROR: %v\n", "Internal Server Error")
+	if signInResult.UserID == "" {
+		logger.FromContext(ctx.UserContext()).Error("userController.SignIn no user id returned")
 		return fiber.NewError(http.StatusInternalServerError, "Internal Server Error")
 	}
 
-	// Create a map to represent the JSON data
-	requestData := map[string]string{"userId": userID}
+	accessToken, refreshToken, err := u.jwtCommandHandler.Create(ctx.UserContext(), jwt.Command{
+		UserID:    signInResult.UserID,
+		IP:        ctx.IP(),
+		UserAgent: ctx.Get(fiber.HeaderUserAgent),
+	})
 
-	// Convert the map to JSON
-	jsonData, err := json.Marshal(requestData)
 	if err != nil {
-		fmt.Println("Error marshalling JSON:", err)
-		return fiber.NewError(http.StatusInternalServerError, err.Error())
-	}
-
-	body, errOfHelper := helpers.HttpPostHelper(configuration.BACKEND_URL+"/api/v1/alpha/jwt", bytes.NewBuffer(jsonData))
-
-	if errOfHelper != nil {
-		fmt.Printf("userController.SignIn ERROR -> There was an error while sending request to jwt - ERROR: %v\n", errOfHelper.Error())
-		return fiber.NewError(http.StatusInternalServerError, errOfHelper.Error())
-	}
-
-	if body == nil {
-		fmt.Printf("userController.SignIn ERROR -> There was an error while sending request to jwt - ERROR: %v\n", "body is nil")
-		return fiber.NewError(http.StatusBadRequest, "Body is nil")
-	}
-
-	var data map[string]string
-
-	errOfData := json.Unmarshal([]byte(string(body)), &data)
-	if errOfData != nil {
-		fmt.Printf("userController.SignIn ERROR -> There was an error while binding data - ERROR: %v\n:", errOfData.Error())
-		return fiber.NewError(http.StatusInternalServerError, errOfData.Error())
+		logger.FromContext(ctx.UserContext()).Error("userController.SignIn error while creating jwt tokens", "error", err)
+		return tokenError(err)
 	}
 
 	return ctx.Status(http.StatusOK).JSON(
 		map[string]interface{}{
 			"message": "User Successfully Sign In",
 			"response": map[string]interface{}{
-				"accessToken":  data["accessToken"],
-				"refreshToken": data["refreshToken"],
+				"accessToken":  accessToken,
+				"refreshToken": refreshToken,
 			},
 		},
 	)
@@ -216,18 +216,21 @@ func (u *UserController) SignIn(ctx *fiber.Ctx) error {
 //	@Accept			json
 //	@Produce		json
 //
+// @Param Authorization header string true "Bearer {token}"
+//
 // @Success 200 {object} []response.UserResponse
 //
 //	@Failure		400
-//	@Failure		404
+//	@Failure		401
+//	@Failure		403
 //	@Failure		500
 //	@Router			/api/v1/alpha/user [get]
 func (u *UserController) GetUser(ctx *fiber.Ctx) error {
 	users, err := u.userQueryService.GetUser(ctx.UserContext())
 
 	if err != nil {
-		fmt.Printf("userController.GetUser ERROR -> There was an error while getting users - ERROR: %v\n", err.Error())
-		return fiber.NewError(http.StatusInternalServerError, err.Error())
+		logger.FromContext(ctx.UserContext()).Error("userController.GetUser error while getting users", "error", err)
+		return errorResponse(err, fiber.NewError(http.StatusInternalServerError, err.Error()))
 	}
 
 	return ctx.Status(http.StatusOK).JSON(response.ToUserResponseList(users))
@@ -251,14 +254,47 @@ func (u *UserController) GetUser(ctx *fiber.Ctx) error {
 //	@Router			/api/v1/alpha/user/{userId} [get]
 func (u *UserController) GetUserById(ctx *fiber.Ctx) error {
 	user, err := u.userQueryService.GetUserById(ctx.UserContext(), ctx.Params("userId"))
-	userCtx := ctx.UserContext().Value("user").(*utils.UserContext)
-
-	fmt.Println(userCtx.UserID)
 
 	if err != nil {
-		fmt.Printf("userController.GetUserById ERROR -> There was an error while getting user - ERROR: %v\n", err.Error())
-		return fiber.NewError(http.StatusInternalServerError, err.Error())
+		logger.FromContext(ctx.UserContext()).Error("userController.GetUserById error while getting user", "error", err)
+		return errorResponse(err, fiber.NewError(http.StatusInternalServerError, err.Error()))
 	}
 
 	return ctx.Status(http.StatusOK).JSON(response.ToUserResponse(user))
 }
+
+// Unlock godoc
+//
+//	@Summary		This method used for unlocking a locked account
+//	@Description	unlock account with the token from the unlock mail
+//	@Param			token	query	string	true	"Unlock token"
+//	@Tags			User
+//	@Produce		json
+//	@Success		200
+//	@Failure		400
+//	@Failure		500
+//	@Router			/api/v1/alpha/user/unlock [get]
+func (u *UserController) Unlock(ctx *fiber.Ctx) error {
+	token := ctx.Query("token")
+
+	if token == "" {
+		return fiber.NewError(http.StatusBadRequest, "token is required")
+	}
+
+	err := u.userCommandHandler.Unlock(ctx.UserContext(), token)
+
+	if errors.Is(err, user.ErrInvalidUnlockToken) {
+		return fiber.NewError(http.StatusBadRequest, err.Error())
+	}
+
+	if err != nil {
+		logger.FromContext(ctx.UserContext()).Error("userController.Unlock error while unlocking account", "error", err)
+		return errorResponse(err, fiber.NewError(http.StatusInternalServerError, "Internal Server Error"))
+	}
+
+	return ctx.Status(http.StatusOK).JSON(
+		map[string]interface{}{
+			"message": "Account Successfully Unlocked",
+		},
+	)
+}
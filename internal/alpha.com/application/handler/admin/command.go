@@ -0,0 +1,20 @@
+package admin
+
+import "time"
+
+type CommandUserStatus struct {
+	UserID string
+	Reason string
+	Until  *time.Time
+}
+
+type CommandJobModeration struct {
+	JobID  string
+	Reason string
+}
+
+type CommandResolveReport struct {
+	ReportID   string
+	Status     string
+	Resolution string
+}
@@ -0,0 +1,181 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"alpha.com/internal/alpha.com/application/query"
+	"alpha.com/internal/alpha.com/application/repository"
+	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"alpha.com/internal/alpha.com/pkg/tracing"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ICommandHandler interface {
+	SuspendUser(ctx context.Context, command CommandUserStatus, adminID string) error
+	BanUser(ctx context.Context, command CommandUserStatus, adminID string) error
+	UnbanUser(ctx context.Context, command CommandUserStatus, adminID string) error
+	UnpublishJob(ctx context.Context, command CommandJobModeration, adminID string) error
+	DeleteJob(ctx context.Context, command CommandJobModeration, adminID string) error
+	ResolveReport(ctx context.Context, command CommandResolveReport, adminID string) error
+}
+
+type commandHandler struct {
+	userRepository         repository.IUserRepository
+	jobRepository          repository.IJobRepository
+	reportRepository       repository.IReportRepository
+	adminActionRepository  repository.IAdminActionRepository
+	userStatusQueryService query.IUserStatusQueryService
+}
+
+func NewCommandHandler(userRepository repository.IUserRepository,
+	jobRepository repository.IJobRepository,
+	reportRepository repository.IReportRepository,
+	adminActionRepository repository.IAdminActionRepository,
+	userStatusQueryService query.IUserStatusQueryService,
+) ICommandHandler {
+	return &commandHandler{
+		userRepository:         userRepository,
+		jobRepository:          jobRepository,
+		reportRepository:       reportRepository,
+		adminActionRepository:  adminActionRepository,
+		userStatusQueryService: userStatusQueryService,
+	}
+}
+
+func (c *commandHandler) SuspendUser(ctx context.Context, command CommandUserStatus, adminID string) error {
+	ctx, span := tracing.Start(ctx, "adminCommandHandler.SuspendUser")
+	defer span.End()
+
+	if command.Until != nil && !command.Until.After(time.Now()) {
+		return errors.New("suspension end date must be in the future")
+	}
+
+	return c.changeUserStatus(ctx, command, adminID, domain.UserStatusSuspended, domain.AdminActionSuspendUser)
+}
+
+func (c *commandHandler) BanUser(ctx context.Context, command CommandUserStatus, adminID string) error {
+	ctx, span := tracing.Start(ctx, "adminCommandHandler.BanUser")
+	defer span.End()
+
+	command.Until = nil
+
+	return c.changeUserStatus(ctx, command, adminID, domain.UserStatusBanned, domain.AdminActionBanUser)
+}
+
+func (c *commandHandler) UnbanUser(ctx context.Context, command CommandUserStatus, adminID string) error {
+	ctx, span := tracing.Start(ctx, "adminCommandHandler.UnbanUser")
+	defer span.End()
+
+	command.Until = nil
+
+	return c.changeUserStatus(ctx, command, adminID, domain.UserStatusActive, domain.AdminActionUnbanUser)
+}
+
+func (c *commandHandler) changeUserStatus(ctx context.Context, command CommandUserStatus, adminID string, status domain.UserStatus, action domain.AdminActionType) error {
+	if command.UserID == adminID {
+		return errors.New("admins cannot change their own status")
+	}
+
+	err := c.userRepository.UpdateStatus(ctx, command.UserID, status, command.Reason, command.Until)
+
+	if err != nil {
+		logger.FromContext(ctx).Error("commandHandler.changeUserStatus error while updating user", "action", action, "user_id", command.UserID, "error", err)
+		return err
+	}
+
+	c.userStatusQueryService.Invalidate(command.UserID)
+
+	return c.recordAction(ctx, adminID, action, "user", command.UserID, command.Reason)
+}
+
+func (c *commandHandler) UnpublishJob(ctx context.Context, command CommandJobModeration, adminID string) error {
+	ctx, span := tracing.Start(ctx, "adminCommandHandler.UnpublishJob")
+	defer span.End()
+
+	err := c.jobRepository.UpdateStatus(ctx, command.JobID, domain.JobStatusUnpublished, command.Reason)
+
+	if err != nil {
+		logger.FromContext(ctx).Error("commandHandler.UnpublishJob error while unpublishing job", "job_id", command.JobID, "error", err)
+		return err
+	}
+
+	return c.recordAction(ctx, adminID, domain.AdminActionUnpublishJob, "job", command.JobID, command.Reason)
+}
+
+func (c *commandHandler) DeleteJob(ctx context.Context, command CommandJobModeration, adminID string) error {
+	ctx, span := tracing.Start(ctx, "adminCommandHandler.DeleteJob")
+	defer span.End()
+
+	err := c.jobRepository.Delete(ctx, command.JobID)
+
+	if err != nil {
+		logger.FromContext(ctx).Error("commandHandler.DeleteJob error while deleting job", "job_id", command.JobID, "error", err)
+		return err
+	}
+
+	return c.recordAction(ctx, adminID, domain.AdminActionDeleteJob, "job", command.JobID, command.Reason)
+}
+
+func (c *commandHandler) ResolveReport(ctx context.Context, command CommandResolveReport, adminID string) error {
+	ctx, span := tracing.Start(ctx, "adminCommandHandler.ResolveReport")
+	defer span.End()
+
+	status := domain.ReportStatus(command.Status)
+
+	if status != domain.ReportStatusResolved && status != domain.ReportStatusDismissed {
+		return fmt.Errorf("report can not be closed with status: %s", command.Status)
+	}
+
+	adminObjectID, err := primitive.ObjectIDFromHex(adminID)
+	if err != nil {
+		logger.FromContext(ctx).Error("commandHandler.ResolveReport failed", "error", err)
+		return err
+	}
+
+	err = c.reportRepository.Resolve(ctx, command.ReportID, status, adminObjectID, command.Resolution)
+
+	if err != nil {
+		logger.FromContext(ctx).Error("commandHandler.ResolveReport error while resolving report", "report_id", command.ReportID, "error", err)
+		return err
+	}
+
+	return c.recordAction(ctx, adminID, domain.AdminActionResolveReport, "report", command.ReportID, command.Resolution)
+}
+
+func (c *commandHandler) recordAction(ctx context.Context, adminID string, action domain.AdminActionType, targetType, targetID, reason string) error {
+	adminObjectID, err := primitive.ObjectIDFromHex(adminID)
+	if err != nil {
+		logger.FromContext(ctx).Error("commandHandler.recordAction failed", "error", err)
+		return err
+	}
+
+	targetObjectID, err := primitive.ObjectIDFromHex(targetID)
+	if err != nil {
+		logger.FromContext(ctx).Error("commandHandler.recordAction failed", "error", err)
+		return err
+	}
+
+	adminAction := c.BuildEntity(adminObjectID, action, targetType, targetObjectID, reason)
+
+	if err = c.adminActionRepository.Upsert(ctx, adminAction); err != nil {
+		logger.FromContext(ctx).Error("commandHandler.recordAction error while saving admin action", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (c *commandHandler) BuildEntity(adminID primitive.ObjectID, action domain.AdminActionType, targetType string, targetID primitive.ObjectID, reason string) *domain.AdminAction {
+	return &domain.AdminAction{
+		AdminID:    adminID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Reason:     reason,
+		CreatedAt:  time.Now(),
+	}
+}
@@ -0,0 +1,17 @@
+package apiKey
+
+import "time"
+
+type Command struct {
+	BusinessAccountID string
+	UserID            string
+	Name              string
+	Scopes            []string
+	ExpiresAt         *time.Time
+}
+
+type CommandRevoke struct {
+	BusinessAccountID string
+	ApiKeyID          string
+	UserID            string
+}
@@ -0,0 +1,158 @@
+package apiKey
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"alpha.com/internal/alpha.com/application/query"
+	"alpha.com/internal/alpha.com/application/repository"
+	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"alpha.com/internal/alpha.com/pkg/server/helpers"
+	"alpha.com/internal/alpha.com/pkg/tracing"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrInvalidScope   = errors.New("api keys can only be granted jobs:write and applications:read")
+	ErrExpiryInPast   = errors.New("api key expiry must be in the future")
+	ErrApiKeyNotFound = errors.New("api key could not be found or is already revoked")
+)
+
+type ICommandHandler interface {
+	Create(ctx context.Context, command Command) (string, string, error)
+	Revoke(ctx context.Context, command CommandRevoke) error
+}
+
+type commandHandler struct {
+	apiKeyRepository   repository.IApiKeyRepository
+	apiKeyQueryService query.IApiKeyQueryService
+}
+
+func NewCommandHandler(apiKeyRepository repository.IApiKeyRepository, apiKeyQueryService query.IApiKeyQueryService) ICommandHandler {
+	return &commandHandler{
+		apiKeyRepository:   apiKeyRepository,
+		apiKeyQueryService: apiKeyQueryService,
+	}
+}
+
+// Create returns the id and the full key. The key cannot be shown again, only
+// its hash is stored.
+func (c *commandHandler) Create(ctx context.Context, command Command) (string, string, error) {
+	ctx, span := tracing.Start(ctx, "apiKeyCommandHandler.Create")
+	defer span.End()
+
+	businessAccount, err := c.apiKeyQueryService.GetOwnedBusinessAccount(ctx, command.BusinessAccountID, command.UserID)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	scopes, err := parseScopes(command.Scopes)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	if command.ExpiresAt != nil && !command.ExpiresAt.After(time.Now()) {
+		return "", "", ErrExpiryInPast
+	}
+
+	prefix, err := newPrefix()
+
+	if err != nil {
+		return "", "", err
+	}
+
+	secret, err := helpers.NewRandomToken()
+
+	if err != nil {
+		return "", "", err
+	}
+
+	newApiKey := c.BuildEntity(command, businessAccount, prefix, query.HashApiKeySecret(secret), scopes)
+
+	apiKeyID, err := c.apiKeyRepository.Upsert(ctx, newApiKey)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	return apiKeyID, query.FormatApiKey(prefix, secret), nil
+}
+
+func (c *commandHandler) Revoke(ctx context.Context, command CommandRevoke) error {
+	ctx, span := tracing.Start(ctx, "apiKeyCommandHandler.Revoke")
+	defer span.End()
+
+	if _, err := c.apiKeyQueryService.GetOwnedBusinessAccount(ctx, command.BusinessAccountID, command.UserID); err != nil {
+		return err
+	}
+
+	revoked, err := c.apiKeyRepository.Revoke(ctx, command.ApiKeyID, command.BusinessAccountID)
+
+	if errors.Is(err, primitive.ErrInvalidHex) {
+		return ErrApiKeyNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !revoked {
+		return ErrApiKeyNotFound
+	}
+
+	logger.FromContext(ctx).Info("commandHandler.Revoke api key revoked", "api_key_id", command.ApiKeyID, "business_account_id", command.BusinessAccountID)
+
+	return nil
+}
+
+func (c *commandHandler) BuildEntity(command Command, businessAccount *domain.BusinessAccount, prefix, secretHash string, scopes []domain.Permission) *domain.ApiKey {
+	createdBy, _ := primitive.ObjectIDFromHex(command.UserID)
+
+	return &domain.ApiKey{
+		BusinessAccountID: businessAccount.Id,
+		CreatedBy:         createdBy,
+		Name:              command.Name,
+		Prefix:            prefix,
+		SecretHash:        secretHash,
+		Scopes:            scopes,
+		ExpiresAt:         command.ExpiresAt,
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+	}
+}
+
+func parseScopes(rawScopes []string) ([]domain.Permission, error) {
+	scopes := make([]domain.Permission, 0, len(rawScopes))
+
+	for _, rawScope := range rawScopes {
+		valid := false
+		for _, scope := range domain.ApiKeyScopes {
+			if domain.Permission(rawScope) == scope {
+				valid = true
+			}
+		}
+
+		if !valid {
+			return nil, ErrInvalidScope
+		}
+
+		scopes = append(scopes, domain.Permission(rawScope))
+	}
+
+	return scopes, nil
+}
+
+func newPrefix() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
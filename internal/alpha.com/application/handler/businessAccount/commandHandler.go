@@ -2,11 +2,13 @@ package businessAccount
 
 import (
 	"context"
-	"fmt"
 	"time"
 
+	"alpha.com/internal/alpha.com/application/handler/user"
 	"alpha.com/internal/alpha.com/application/repository"
 	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"alpha.com/internal/alpha.com/pkg/tracing"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -16,18 +18,23 @@ type ICommandHandler interface {
 
 type commandHandler struct {
 	businessAccountRepository repository.IBusinessAccountRepository
+	userCommandHandler        user.ICommandHandler
 }
 
-func NewCommandHandler(businessAccountRepository repository.IBusinessAccountRepository) ICommandHandler {
+func NewCommandHandler(businessAccountRepository repository.IBusinessAccountRepository, userCommandHandler user.ICommandHandler) ICommandHandler {
 	return &commandHandler{
 		businessAccountRepository: businessAccountRepository,
+		userCommandHandler:        userCommandHandler,
 	}
 }
 
 func (c *commandHandler) Save(ctx context.Context, command Command, UserID string) error {
+	ctx, span := tracing.Start(ctx, "businessAccountCommandHandler.Save")
+	defer span.End()
+
 	userID, err := primitive.ObjectIDFromHex(UserID)
 	if err != nil {
-		fmt.Printf("commandHandler.Create ERROR :  %s\n", err.Error())
+		logger.FromContext(ctx).Error("commandHandler.Create failed", "error", err)
 	}
 
 	newBusinessAccount := c.BuildEntity(command, userID)
@@ -38,7 +45,9 @@ func (c *commandHandler) Save(ctx context.Context, command Command, UserID strin
 		return err
 	}
 
-	return nil
+	// the owner of a business account posts its jobs and reads the
+	// applications to them
+	return c.userCommandHandler.GrantRole(ctx, UserID, domain.RoleEmployer)
 }
 
 func (c *commandHandler) BuildEntity(command Command, userID primitive.ObjectID) *domain.BusinessAccount {
@@ -0,0 +1,21 @@
+package externalIdentity
+
+type CommandBegin struct {
+	Provider string
+	// UserID is set when a signed-in user links a provider, empty for sign-in.
+	UserID string
+}
+
+type CommandCallback struct {
+	Provider string
+	Code     string `sensitive:"true"`
+	State    string
+	// BrowserState is the state of the cookie set when the flow began, it ties
+	// the callback to the browser that started it.
+	BrowserState string `sensitive:"true"`
+}
+
+type CommandUnlink struct {
+	Provider string
+	UserID   string
+}
@@ -0,0 +1,301 @@
+package externalIdentity
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"alpha.com/internal/alpha.com/application/handler/user"
+	"alpha.com/internal/alpha.com/application/query"
+	"alpha.com/internal/alpha.com/application/repository"
+	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"alpha.com/internal/alpha.com/pkg/server/helpers"
+	"alpha.com/internal/alpha.com/pkg/server/services"
+	"alpha.com/internal/alpha.com/pkg/tracing"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrUnknownProvider         = errors.New("unknown identity provider")
+	ErrInvalidState            = errors.New("sign-in request is invalid or has expired, please start again")
+	ErrEmailNotVerified        = errors.New("identity provider did not confirm the email address")
+	ErrIdentityLinkedElsewhere = errors.New("this provider account is already linked to another user")
+	ErrProviderAlreadyLinked   = errors.New("a different account of this provider is already linked")
+	ErrIdentityNotFound        = errors.New("no account of this provider is linked")
+)
+
+// BeginResult holds the provider URL to send the browser to and the state the
+// browser has to present again on the callback.
+type BeginResult struct {
+	AuthURL string
+	State   string
+}
+
+// CallbackResult is a sign-in for login callbacks. Link callbacks only set
+// Linked since the user is already signed in.
+type CallbackResult struct {
+	SignIn user.SignInResult
+	Linked bool
+}
+
+type ICommandHandler interface {
+	Begin(ctx context.Context, command CommandBegin) (BeginResult, error)
+	Callback(ctx context.Context, command CommandCallback) (CallbackResult, error)
+	Unlink(ctx context.Context, command CommandUnlink) error
+}
+
+type commandHandler struct {
+	externalIdentityRepository repository.IExternalIdentityRepository
+	oidcStateRepository        repository.IOidcStateRepository
+	userQueryService           query.IUserQueryService
+	userCommandHandler         user.ICommandHandler
+	jwtService                 services.IJwtService
+	providers                  map[string]services.IOidcClient
+	stateTTL                   time.Duration
+}
+
+func NewCommandHandler(externalIdentityRepository repository.IExternalIdentityRepository,
+	oidcStateRepository repository.IOidcStateRepository,
+	userQueryService query.IUserQueryService,
+	userCommandHandler user.ICommandHandler,
+	jwtService services.IJwtService,
+	providers map[string]services.IOidcClient,
+	stateTTL time.Duration,
+) ICommandHandler {
+	return &commandHandler{
+		externalIdentityRepository: externalIdentityRepository,
+		oidcStateRepository:        oidcStateRepository,
+		userQueryService:           userQueryService,
+		userCommandHandler:         userCommandHandler,
+		jwtService:                 jwtService,
+		providers:                  providers,
+		stateTTL:                   stateTTL,
+	}
+}
+
+// Begin stores a fresh state, nonce and PKCE verifier and returns the provider
+// URL to send the browser to with the state, which the caller keeps in the
+// browser for Callback.
+func (c *commandHandler) Begin(ctx context.Context, command CommandBegin) (BeginResult, error) {
+	ctx, span := tracing.Start(ctx, "externalIdentityCommandHandler.Begin")
+	defer span.End()
+
+	provider, ok := c.providers[command.Provider]
+	if !ok {
+		return BeginResult{}, ErrUnknownProvider
+	}
+
+	state, err := helpers.NewRandomToken()
+	if err != nil {
+		return BeginResult{}, err
+	}
+
+	nonce, err := helpers.NewRandomToken()
+	if err != nil {
+		return BeginResult{}, err
+	}
+
+	codeVerifier, err := services.NewPkceVerifier()
+	if err != nil {
+		return BeginResult{}, err
+	}
+
+	oidcState := c.BuildState(command, state, nonce, codeVerifier, time.Now().Add(c.stateTTL))
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, codeVerifier)
+	if err != nil {
+		return BeginResult{}, err
+	}
+
+	if err := c.oidcStateRepository.Upsert(ctx, oidcState); err != nil {
+		return BeginResult{}, err
+	}
+
+	return BeginResult{AuthURL: authURL, State: state}, nil
+}
+
+func (c *commandHandler) Callback(ctx context.Context, command CommandCallback) (CallbackResult, error) {
+	ctx, span := tracing.Start(ctx, "externalIdentityCommandHandler.Callback")
+	defer span.End()
+
+	provider, ok := c.providers[command.Provider]
+	if !ok {
+		return CallbackResult{}, ErrUnknownProvider
+	}
+
+	// a callback from another browser, e.g. a link to the callback planted by
+	// an attacker, is refused before the state is used up
+	if command.BrowserState == "" || subtle.ConstantTimeCompare([]byte(command.State), []byte(command.BrowserState)) != 1 {
+		return CallbackResult{}, ErrInvalidState
+	}
+
+	oidcState, err := c.oidcStateRepository.Consume(ctx, hashState(command.State))
+	if err != nil {
+		return CallbackResult{}, err
+	}
+
+	if oidcState == nil || oidcState.Provider != command.Provider {
+		return CallbackResult{}, ErrInvalidState
+	}
+
+	identity, err := provider.Exchange(ctx, command.Code, oidcState.CodeVerifier, oidcState.Nonce)
+	if err != nil {
+		return CallbackResult{}, err
+	}
+
+	existing, err := c.externalIdentityRepository.GetByProviderSubject(ctx, command.Provider, identity.Subject)
+	if err != nil {
+		return CallbackResult{}, err
+	}
+
+	if oidcState.Purpose == domain.OidcStatePurposeLink {
+		if err := c.link(ctx, oidcState.UserID, command.Provider, identity, existing); err != nil {
+			return CallbackResult{}, err
+		}
+
+		return CallbackResult{Linked: true}, nil
+	}
+
+	signIn, err := c.signIn(ctx, command.Provider, identity, existing)
+	if err != nil {
+		return CallbackResult{}, err
+	}
+
+	return CallbackResult{SignIn: signIn}, nil
+}
+
+func (c *commandHandler) Unlink(ctx context.Context, command CommandUnlink) error {
+	ctx, span := tracing.Start(ctx, "externalIdentityCommandHandler.Unlink")
+	defer span.End()
+
+	deleted, err := c.externalIdentityRepository.Delete(ctx, command.UserID, command.Provider)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return ErrIdentityNotFound
+	}
+
+	return nil
+}
+
+// signIn finds the user of a provider account. An unknown account is linked
+// to the user with the same email, or to a new passwordless user, but only
+// when the provider verified the email; otherwise anyone able to register an
+// email at some provider could take over the matching account here.
+func (c *commandHandler) signIn(ctx context.Context, provider string, identity *services.OidcIdentity, existing *domain.ExternalIdentity) (user.SignInResult, error) {
+	var userID string
+
+	if existing != nil {
+		userID = existing.UserID.Hex()
+
+		if err := c.externalIdentityRepository.Touch(ctx, existing.Id); err != nil {
+			logger.FromContext(ctx).Error("commandHandler.signIn error while touching external identity", "error", err)
+		}
+	} else {
+		if !identity.EmailVerified || identity.Email == "" {
+			return user.SignInResult{}, ErrEmailNotVerified
+		}
+
+		existingUser, err := c.userQueryService.GetUserByEmail(ctx, identity.Email)
+
+		switch {
+		case errors.Is(err, query.ErrUserNotFound):
+			userID, err = c.userCommandHandler.SavePasswordless(ctx, identity.Email)
+			if err != nil {
+				return user.SignInResult{}, err
+			}
+		case err != nil:
+			return user.SignInResult{}, err
+		default:
+			userID = existingUser.Id.Hex()
+		}
+
+		if err := c.link(ctx, userID, provider, identity, nil); err != nil {
+			return user.SignInResult{}, err
+		}
+	}
+
+	signedInUser, err := c.userQueryService.GetUserById(ctx, userID)
+	if err != nil {
+		return user.SignInResult{}, err
+	}
+
+	if signedInUser.MfaEnabled() {
+		challengeToken, err := c.jwtService.CreateMfaChallengeToken(userID)
+		if err != nil {
+			return user.SignInResult{}, err
+		}
+
+		return user.SignInResult{MfaChallengeToken: challengeToken}, nil
+	}
+
+	return user.SignInResult{UserID: userID}, nil
+}
+
+func (c *commandHandler) link(ctx context.Context, userID string, provider string, identity *services.OidcIdentity, existing *domain.ExternalIdentity) error {
+	if existing != nil {
+		if existing.UserID.Hex() == userID {
+			return nil
+		}
+
+		return ErrIdentityLinkedElsewhere
+	}
+
+	linked, err := c.externalIdentityRepository.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, l := range linked {
+		if l.Provider == provider {
+			return ErrProviderAlreadyLinked
+		}
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	return c.externalIdentityRepository.Upsert(ctx, c.BuildEntity(objectID, provider, identity))
+}
+
+func (c *commandHandler) BuildEntity(userID primitive.ObjectID, provider string, identity *services.OidcIdentity) *domain.ExternalIdentity {
+	return &domain.ExternalIdentity{
+		UserID:     userID,
+		Provider:   provider,
+		Subject:    identity.Subject,
+		Email:      identity.Email,
+		LinkedAt:   time.Now(),
+		LastUsedAt: time.Now(),
+	}
+}
+
+func (c *commandHandler) BuildState(command CommandBegin, state, nonce, codeVerifier string, expiresAt time.Time) *domain.OidcState {
+	purpose := domain.OidcStatePurposeLogin
+	if command.UserID != "" {
+		purpose = domain.OidcStatePurposeLink
+	}
+
+	return &domain.OidcState{
+		StateHash:    hashState(state),
+		Provider:     command.Provider,
+		Purpose:      purpose,
+		UserID:       command.UserID,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    expiresAt,
+		CreatedAt:    time.Now(),
+	}
+}
+
+func hashState(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return hex.EncodeToString(sum[:])
+}
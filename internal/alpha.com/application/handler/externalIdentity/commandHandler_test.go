@@ -0,0 +1,229 @@
+package externalIdentity
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"alpha.com/internal/alpha.com/application/query"
+	"alpha.com/internal/alpha.com/application/repository"
+	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/server/services"
+	"alpha.com/internal/alpha.com/pkg/server/services/oidctest"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const testClientID = "alpha-client"
+
+type fakeOidcStateRepository struct {
+	states map[string]*domain.OidcState
+}
+
+func (r *fakeOidcStateRepository) Upsert(ctx context.Context, state *domain.OidcState) error {
+	r.states[state.StateHash] = state
+	return nil
+}
+
+func (r *fakeOidcStateRepository) Consume(ctx context.Context, stateHash string) (*domain.OidcState, error) {
+	state := r.states[stateHash]
+	delete(r.states, stateHash)
+
+	return state, nil
+}
+
+type fakeExternalIdentityRepository struct {
+	repository.IExternalIdentityRepository
+	linked []*domain.ExternalIdentity
+}
+
+func (r *fakeExternalIdentityRepository) GetByProviderSubject(ctx context.Context, provider string, subject string) (*domain.ExternalIdentity, error) {
+	for _, identity := range r.linked {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *fakeExternalIdentityRepository) GetByUserID(ctx context.Context, userId string) ([]*domain.ExternalIdentity, error) {
+	var linked []*domain.ExternalIdentity
+	for _, identity := range r.linked {
+		if identity.UserID.Hex() == userId {
+			linked = append(linked, identity)
+		}
+	}
+
+	return linked, nil
+}
+
+func (r *fakeExternalIdentityRepository) Upsert(ctx context.Context, identity *domain.ExternalIdentity) error {
+	r.linked = append(r.linked, identity)
+	return nil
+}
+
+type fakeUserQueryService struct {
+	query.IUserQueryService
+	user *domain.User
+}
+
+func (q *fakeUserQueryService) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	if q.user.Email != email {
+		return nil, query.ErrUserNotFound
+	}
+
+	return q.user, nil
+}
+
+func (q *fakeUserQueryService) GetUserById(ctx context.Context, userId string) (*domain.User, error) {
+	if q.user.Id.Hex() != userId {
+		return nil, query.ErrUserNotFound
+	}
+
+	return q.user, nil
+}
+
+type testFlow struct {
+	provider           *oidctest.Provider
+	handler            ICommandHandler
+	identityRepository *fakeExternalIdentityRepository
+	existingUser       *domain.User
+}
+
+// newTestFlow runs the handler against a stub provider named "stub" with a
+// user registered as user@example.com, the email of the stub ID tokens.
+func newTestFlow(t *testing.T) *testFlow {
+	t.Helper()
+
+	provider := oidctest.NewProvider(testClientID)
+	t.Cleanup(provider.Close)
+
+	client := services.NewOidcClient(services.OidcConfig{
+		IssuerURL: provider.URL,
+		ClientID:  testClientID,
+	}, provider.Client())
+
+	flow := &testFlow{
+		provider:           provider,
+		identityRepository: &fakeExternalIdentityRepository{},
+		existingUser:       &domain.User{Id: primitive.NewObjectID(), Email: "user@example.com"},
+	}
+
+	flow.handler = NewCommandHandler(
+		flow.identityRepository,
+		&fakeOidcStateRepository{states: make(map[string]*domain.OidcState)},
+		&fakeUserQueryService{user: flow.existingUser},
+		nil,
+		nil,
+		map[string]services.IOidcClient{"stub": client},
+		time.Minute,
+	)
+
+	return flow
+}
+
+// begin starts a flow and makes the stub provider answer with its nonce.
+func (f *testFlow) begin(t *testing.T, command CommandBegin) BeginResult {
+	t.Helper()
+
+	beginResult, err := f.handler.Begin(context.Background(), command)
+	if err != nil {
+		t.Fatalf("Begin returned %v", err)
+	}
+
+	authURL, err := url.Parse(beginResult.AuthURL)
+	if err != nil {
+		t.Fatalf("Begin returned an invalid url %q", beginResult.AuthURL)
+	}
+
+	f.provider.Set(func(p *oidctest.Provider) { p.Claims["nonce"] = authURL.Query().Get("nonce") })
+
+	return beginResult
+}
+
+func (f *testFlow) callback(state, browserState string) (CallbackResult, error) {
+	return f.handler.Callback(context.Background(), CommandCallback{
+		Provider:     "stub",
+		Code:         "code-1",
+		State:        state,
+		BrowserState: browserState,
+	})
+}
+
+func TestCallbackSignsInByVerifiedEmail(t *testing.T) {
+	flow := newTestFlow(t)
+
+	beginResult := flow.begin(t, CommandBegin{Provider: "stub"})
+
+	result, err := flow.callback(beginResult.State, beginResult.State)
+	if err != nil {
+		t.Fatalf("Callback returned %v", err)
+	}
+
+	if result.SignIn.UserID != flow.existingUser.Id.Hex() {
+		t.Errorf("Callback signed in %q, want %q", result.SignIn.UserID, flow.existingUser.Id.Hex())
+	}
+
+	if len(flow.identityRepository.linked) != 1 {
+		t.Errorf("Callback linked %d identities, want 1", len(flow.identityRepository.linked))
+	}
+}
+
+func TestCallbackRefusesToLinkByUnverifiedEmail(t *testing.T) {
+	flow := newTestFlow(t)
+
+	beginResult := flow.begin(t, CommandBegin{Provider: "stub"})
+	flow.provider.Set(func(p *oidctest.Provider) { p.Claims["email_verified"] = false })
+
+	_, err := flow.callback(beginResult.State, beginResult.State)
+	if !errors.Is(err, ErrEmailNotVerified) {
+		t.Fatalf("Callback returned %v, want ErrEmailNotVerified", err)
+	}
+
+	if len(flow.identityRepository.linked) != 0 {
+		t.Errorf("an unverified email linked %d identities to the user with that email", len(flow.identityRepository.linked))
+	}
+}
+
+func TestCallbackRefusesStateFromAnotherBrowser(t *testing.T) {
+	flow := newTestFlow(t)
+
+	attackerFlow := flow.begin(t, CommandBegin{Provider: "stub"})
+
+	for _, browserState := range []string{"", "another-state"} {
+		if _, err := flow.callback(attackerFlow.State, browserState); !errors.Is(err, ErrInvalidState) {
+			t.Errorf("Callback with browser state %q returned %v, want ErrInvalidState", browserState, err)
+		}
+	}
+
+	if len(flow.identityRepository.linked) != 0 {
+		t.Errorf("a callback from another browser linked %d identities", len(flow.identityRepository.linked))
+	}
+
+	// the refused callbacks did not use the state up
+	if _, err := flow.callback(attackerFlow.State, attackerFlow.State); err != nil {
+		t.Errorf("Callback from the starting browser returned %v", err)
+	}
+}
+
+func TestCallbackLinksToTheUserWhoStartedTheLink(t *testing.T) {
+	flow := newTestFlow(t)
+
+	beginResult := flow.begin(t, CommandBegin{Provider: "stub", UserID: flow.existingUser.Id.Hex()})
+
+	result, err := flow.callback(beginResult.State, beginResult.State)
+	if err != nil {
+		t.Fatalf("Callback returned %v", err)
+	}
+
+	if !result.Linked || len(flow.identityRepository.linked) != 1 || flow.identityRepository.linked[0].UserID != flow.existingUser.Id {
+		t.Errorf("Callback returned %+v and linked %+v", result, flow.identityRepository.linked)
+	}
+
+	// a state is good for one callback only
+	if _, err := flow.callback(beginResult.State, beginResult.State); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("a replayed callback returned %v, want ErrInvalidState", err)
+	}
+}
@@ -2,12 +2,14 @@ package job
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	"alpha.com/internal/alpha.com/application/query"
 	"alpha.com/internal/alpha.com/application/repository"
 	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"alpha.com/internal/alpha.com/pkg/metrics"
+	"alpha.com/internal/alpha.com/pkg/tracing"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -18,27 +20,31 @@ type ICommandHandler interface {
 type commandHandler struct {
 	jobRepository               repository.IJobRepository
 	businessAccountQueryService query.IBusinessAccountQueryService
+	businessMetrics             *metrics.BusinessMetrics
 }
 
-func NewCommandHandler(jobRepository repository.IJobRepository, businessAccountQueryService query.IBusinessAccountQueryService) ICommandHandler {
+func NewCommandHandler(jobRepository repository.IJobRepository, businessAccountQueryService query.IBusinessAccountQueryService, businessMetrics *metrics.BusinessMetrics) ICommandHandler {
 	return &commandHandler{
 		jobRepository:               jobRepository,
 		businessAccountQueryService: businessAccountQueryService,
+		businessMetrics:             businessMetrics,
 	}
 }
 
 func (c *commandHandler) Save(ctx context.Context, command Command, userID string) error {
+	ctx, span := tracing.Start(ctx, "jobCommandHandler.Save")
+	defer span.End()
 
 	_, err := c.businessAccountQueryService.GetByIDAndUserID(ctx, command.BusinessAccountID, userID)
 
 	if err != nil {
-		fmt.Printf("commandHandler.Save ERROR -> Error was happened while finding Business Account with given id: %v Error:  %s\n", command.BusinessAccountID, err.Error())
+		logger.FromContext(ctx).Error("commandHandler.Save error while finding business account", "business_account_id", command.BusinessAccountID, "error", err)
 		return err
 	}
 
 	businessAccountID, err := primitive.ObjectIDFromHex(command.BusinessAccountID)
 	if err != nil {
-		fmt.Printf("commandHandler.Save ERROR :  %s\n", err.Error())
+		logger.FromContext(ctx).Error("commandHandler.Save failed", "error", err)
 		return err
 	}
 
@@ -50,6 +56,8 @@ func (c *commandHandler) Save(ctx context.Context, command Command, userID strin
 		return err
 	}
 
+	c.businessMetrics.JobsPosted.Inc()
+
 	return nil
 }
 
@@ -60,6 +68,7 @@ func (c *commandHandler) BuildEntity(command Command, businessAccountID primitiv
 		Description:       command.Description,
 		Price:             command.Price,
 		Category:          command.Category,
+		Status:            domain.JobStatusPublished,
 		CreatedAt:         time.Now(),
 		UpdatedAt:         time.Now(),
 	}
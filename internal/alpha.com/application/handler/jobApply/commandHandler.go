@@ -2,12 +2,15 @@ package jobApply
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"time"
 
 	"alpha.com/internal/alpha.com/application/query"
 	"alpha.com/internal/alpha.com/application/repository"
 	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"alpha.com/internal/alpha.com/pkg/metrics"
+	"alpha.com/internal/alpha.com/pkg/tracing"
 	"alpha.com/internal/alpha.com/pkg/utils"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -20,45 +23,55 @@ type commandHandler struct {
 	jobApplyRepository repository.IJobApplyRepository
 	jobQueryService    query.IJobQueryService
 	userQueryService   query.IUserQueryService
+	businessMetrics    *metrics.BusinessMetrics
 }
 
 func NewCommandHandler(jobApplyRepository repository.IJobApplyRepository,
 	jobQueryService query.IJobQueryService,
 	userQueryService query.IUserQueryService,
+	businessMetrics *metrics.BusinessMetrics,
 ) ICommandHandler {
 	return &commandHandler{
 		jobApplyRepository: jobApplyRepository,
 		jobQueryService:    jobQueryService,
 		userQueryService:   userQueryService,
+		businessMetrics:    businessMetrics,
 	}
 }
 
 func (c *commandHandler) Save(ctx context.Context, command Command) error {
+	ctx, span := tracing.Start(ctx, "jobApplyCommandHandler.Save")
+	defer span.End()
+
 	userCtx := ctx.Value("user").(*utils.UserContext)
 
 	_, err := c.userQueryService.GetUserById(ctx, userCtx.UserID)
 
 	if err != nil {
-		fmt.Printf("commandHandler.Save ERROR -> Error was happened while finding User with given id: %v Error:  %s\n", userCtx.UserID, err.Error())
+		logger.FromContext(ctx).Error("commandHandler.Save error while finding user", "user_id", userCtx.UserID, "error", err)
 		return err
 	}
 
-	_, err = c.jobQueryService.GetByIDAndBusinessAccountID(ctx, command.JobID, command.BusinessAccountID)
+	job, err := c.jobQueryService.GetByIDAndBusinessAccountID(ctx, command.JobID, command.BusinessAccountID)
 
 	if err != nil {
-		fmt.Printf("commandHandler.Save ERROR -> Error was happened while finding Job with given id: %v Error:  %s\n", command.JobID, err.Error())
+		logger.FromContext(ctx).Error("commandHandler.Save error while finding job", "job_id", command.JobID, "error", err)
 		return err
 	}
 
+	if !job.IsPublished() {
+		return errors.New("job is no longer accepting applications")
+	}
+
 	jobID, err := primitive.ObjectIDFromHex(command.JobID)
 	if err != nil {
-		fmt.Printf("commandHandler.Save ERROR :  %s\n", err.Error())
+		logger.FromContext(ctx).Error("commandHandler.Save failed", "error", err)
 		return err
 	}
 
 	userID, err := primitive.ObjectIDFromHex(userCtx.UserID)
 	if err != nil {
-		fmt.Printf("commandHandler.Save ERROR :  %s\n", err.Error())
+		logger.FromContext(ctx).Error("commandHandler.Save failed", "error", err)
 		return err
 	}
 
@@ -70,6 +83,8 @@ func (c *commandHandler) Save(ctx context.Context, command Command) error {
 		return err
 	}
 
+	c.businessMetrics.JobApplications.Inc()
+
 	return nil
 }
 
@@ -1,8 +1,12 @@
 package jwt
 
 type Command struct {
-	Id           string
-	UserID       string
-	AccessToken  string
-	RefreshToken string
+	UserID    string
+	IP        string
+	UserAgent string
+}
+
+type CommandRefresh struct {
+	RefreshToken string `sensitive:"true"`
+	IP           string
 }
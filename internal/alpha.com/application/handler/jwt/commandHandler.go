@@ -3,111 +3,213 @@ package jwt
 import (
 	"context"
 	"errors"
-	"fmt"
 	"time"
 
 	"alpha.com/internal/alpha.com/application/query"
 	"alpha.com/internal/alpha.com/application/repository"
 	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"alpha.com/internal/alpha.com/pkg/server/helpers"
 	"alpha.com/internal/alpha.com/pkg/server/services"
+	"alpha.com/internal/alpha.com/pkg/tracing"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token has already been used, please sign in again")
+	ErrUserBlocked         = errors.New("user is suspended or banned")
+)
+
 type ICommandHandler interface {
 	Create(ctx context.Context, command Command) (string, string, error)
-	Refresh(ctx context.Context, userID string) (string, error)
-	Update(ctx context.Context, userID, accessToken, refreshToken string) error
+	Refresh(ctx context.Context, command CommandRefresh) (string, string, error)
 }
 
 type commandHandler struct {
-	jwtRepository    repository.IJwtRepository
-	jwtService       services.IJwtService
-	userQueryService query.IUserQueryService
+	jwtRepository       repository.IJwtRepository
+	sessionRepository   repository.ISessionRepository
+	jwtService          services.IJwtService
+	userQueryService    query.IUserQueryService
+	sessionQueryService query.ISessionQueryService
 }
 
-func NewCommandHandler(jwtRepository repository.IJwtRepository, jwtService services.IJwtService, userQueryService query.IUserQueryService) ICommandHandler {
+func NewCommandHandler(jwtRepository repository.IJwtRepository,
+	sessionRepository repository.ISessionRepository,
+	jwtService services.IJwtService,
+	userQueryService query.IUserQueryService,
+	sessionQueryService query.ISessionQueryService,
+) ICommandHandler {
 	return &commandHandler{
-		jwtRepository:    jwtRepository,
-		jwtService:       jwtService,
-		userQueryService: userQueryService,
+		jwtRepository:       jwtRepository,
+		sessionRepository:   sessionRepository,
+		jwtService:          jwtService,
+		userQueryService:    userQueryService,
+		sessionQueryService: sessionQueryService,
 	}
 }
 
+// Create starts a session for a user who signed in. Every way to sign in ends
+// here, so banned and suspended users are refused here, whichever it was.
 func (c *commandHandler) Create(ctx context.Context, command Command) (string, string, error) {
-	_, err := c.userQueryService.GetUserById(ctx, command.UserID)
+	ctx, span := tracing.Start(ctx, "jwtCommandHandler.Create")
+	defer span.End()
+
+	user, err := c.userQueryService.GetUserById(ctx, command.UserID)
 
 	if err != nil {
-		fmt.Printf("commandHandler.Create ERROR -> There was an error while finding user with given id: %v Error: %v\n", command.UserID, err.Error())
+		logger.FromContext(ctx).Error("commandHandler.Create error while finding user", "user_id", command.UserID, "error", err)
 		return "", "", err
 	}
 
-	accessToken, refreshToken, err := c.jwtService.CreateTokens(command.UserID)
+	if user.IsBlocked(time.Now()) {
+		logger.FromContext(ctx).Info("commandHandler.Create refused a banned or suspended user", "user_id", command.UserID)
+		return "", "", ErrUserBlocked
+	}
 
-	if err != nil {
+	refreshTokenTTL := c.jwtService.RefreshTokenTTL()
 
-		fmt.Printf("commandHandler.Create ERROR -> There was an error while creating jwt tokens - ERROR: %v\n", err.Error())
+	// every sign-in starts a new session, which is also the refresh token family
+	session := c.BuildSession(user.Id, command, time.Now().Add(refreshTokenTTL))
 
+	if err = c.sessionRepository.Upsert(ctx, session); err != nil {
+		logger.FromContext(ctx).Error("commandHandler.Create error while saving session", "error", err)
 		return "", "", err
 	}
 
-	userID, err := primitive.ObjectIDFromHex(command.UserID)
+	accessToken, refreshToken, err := c.issueTokens(ctx, user, session.Id.Hex())
+
 	if err != nil {
-		fmt.Printf("commandHandler.Create ERROR :  %s\n", err.Error())
+		logger.FromContext(ctx).Error("commandHandler.Create error while creating jwt tokens", "error", err)
+		return "", "", err
 	}
 
-	data := c.BuildEntity(accessToken, refreshToken, userID)
+	logger.FromContext(ctx).Info("commandHandler.Create jwt tokens created", "user_id", command.UserID)
 
-	if err = c.jwtRepository.Upsert(ctx, data); err != nil {
+	return accessToken, refreshToken, nil
+}
+
+// Refresh exchanges a refresh token for a new access and refresh token pair.
+// Presenting a refresh token that was already exchanged revokes its whole
+// family, since either the client or an attacker holds a stolen copy. Banned
+// and suspended users are refused, their refresh token is left unused.
+func (c *commandHandler) Refresh(ctx context.Context, command CommandRefresh) (string, string, error) {
+	ctx, span := tracing.Start(ctx, "jwtCommandHandler.Refresh")
+	defer span.End()
+
+	refreshToken := command.RefreshToken
+
+	userID, err := c.jwtService.ParseRefreshToken(refreshToken)
 
-		fmt.Printf("commandHandler.Create ERROR -> There was an error while saving jwt tokens - ERROR: %v\n", err.Error())
+	if err != nil {
+		return "", "", ErrInvalidRefreshToken
+	}
+
+	refreshTokenHash := c.jwtService.HashToken(refreshToken)
+
+	stored, err := c.jwtRepository.GetByRefreshTokenHash(ctx, refreshTokenHash)
+
+	if err != nil {
+		logger.FromContext(ctx).Error("commandHandler.Refresh error while finding refresh token", "error", err)
 		return "", "", err
 	}
 
-	fmt.Println("commandHandler.Create SUCCESS -> Jwt Tokens successfully created")
+	if stored == nil || stored.UserID.Hex() != userID || stored.RevokedAt != nil {
+		return "", "", ErrInvalidRefreshToken
+	}
 
-	return accessToken, refreshToken, nil
-}
+	user, err := c.userQueryService.GetUserById(ctx, userID)
 
-func (c *commandHandler) Refresh(ctx context.Context, userID string) (string, error) {
-	_, err := c.userQueryService.GetUserById(ctx, userID)
+	if err != nil {
+		logger.FromContext(ctx).Error("commandHandler.Refresh error while finding user", "user_id", userID, "error", err)
+		return "", "", err
+	}
+
+	if user.IsBlocked(time.Now()) {
+		logger.FromContext(ctx).Info("commandHandler.Refresh refused a banned or suspended user", "user_id", userID)
+		return "", "", ErrUserBlocked
+	}
+
+	rotated, err := c.jwtRepository.MarkRotated(ctx, refreshTokenHash)
 
 	if err != nil {
-		fmt.Printf("commandHandler.Refresh ERROR -> There was an error while finding user with given id: %v Error: %v\n", userID, err.Error())
-		return "", err
+		logger.FromContext(ctx).Error("commandHandler.Refresh error while rotating refresh token", "error", err)
+		return "", "", err
+	}
+
+	if !rotated {
+		logger.FromContext(ctx).Warn("commandHandler.Refresh refresh token reuse detected", "user_id", userID, "family_id", stored.FamilyID)
+
+		if err := c.jwtRepository.RevokeFamily(ctx, stored.FamilyID); err != nil {
+			return "", "", err
+		}
+
+		if err := c.sessionRepository.Revoke(ctx, stored.FamilyID); err != nil {
+			return "", "", err
+		}
+
+		c.sessionQueryService.MarkRevoked(stored.FamilyID)
+
+		return "", "", ErrRefreshTokenReused
 	}
 
-	accessToken, err := c.jwtService.CreateAccessToken(userID)
+	accessToken, newRefreshToken, err := c.issueTokens(ctx, user, stored.FamilyID)
 
 	if err != nil {
-		fmt.Printf("commandHandler.Refresh ERROR -> There was an error while creating access token - ERROR: %v\n", err.Error())
-		return "", err
+		logger.FromContext(ctx).Error("commandHandler.Refresh error while creating jwt tokens", "error", err)
+		return "", "", err
 	}
 
-	if accessToken == "" {
-		fmt.Println("commandHandler.Refresh ERROR -> Access Token is empty")
-		return "", errors.New("access could not created")
+	refreshTokenTTL := c.jwtService.RefreshTokenTTL()
+
+	if err = c.sessionRepository.Touch(ctx, stored.FamilyID, command.IP, time.Now().Add(refreshTokenTTL)); err != nil {
+		logger.FromContext(ctx).Error("commandHandler.Refresh error while updating session", "error", err)
+		return "", "", err
 	}
 
-	return accessToken, nil
+	return accessToken, newRefreshToken, nil
 }
 
-func (c *commandHandler) Update(ctx context.Context, userID, accessToken, refreshToken string) error {
-	err := c.jwtRepository.Update(ctx, userID, accessToken, refreshToken)
+func (c *commandHandler) issueTokens(ctx context.Context, user *domain.User, sessionID string) (string, string, error) {
+	accessToken, refreshToken, err := c.jwtService.CreateTokens(user.Id.Hex(), sessionID, domain.RolesToStrings(user.Roles))
 
 	if err != nil {
-		fmt.Printf("commandHandler.Update ERROR -> There was an error while updating jwt tokens - ERROR: %v\n", err.Error())
-		return err
+		return "", "", err
+	}
+
+	refreshTokenTTL := c.jwtService.RefreshTokenTTL()
+
+	data := c.BuildEntity(user.Id, sessionID, c.jwtService.HashToken(refreshToken), time.Now().Add(refreshTokenTTL))
+
+	if err = c.jwtRepository.Upsert(ctx, data); err != nil {
+		logger.FromContext(ctx).Error("commandHandler.issueTokens error while saving refresh token", "error", err)
+		return "", "", err
 	}
 
-	return nil
+	return accessToken, refreshToken, nil
 }
 
-func (c *commandHandler) BuildEntity(accessToken, refreshToken string, userID primitive.ObjectID) *domain.Jwt {
+func (c *commandHandler) BuildEntity(userID primitive.ObjectID, familyID, refreshTokenHash string, expiresAt time.Time) *domain.Jwt {
 	return &domain.Jwt{
-		UserID:       userID,
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		UserID:           userID,
+		FamilyID:         familyID,
+		RefreshTokenHash: refreshTokenHash,
+		ExpiresAt:        expiresAt,
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+	}
+}
+
+func (c *commandHandler) BuildSession(userID primitive.ObjectID, command Command, expiresAt time.Time) *domain.Session {
+	return &domain.Session{
+		Id:         primitive.NewObjectID(),
+		UserID:     userID,
+		Device:     helpers.DeviceFromUserAgent(command.UserAgent),
+		IP:         command.IP,
+		UserAgent:  command.UserAgent,
+		LastUsedAt: time.Now(),
+		ExpiresAt:  expiresAt,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
 	}
 }
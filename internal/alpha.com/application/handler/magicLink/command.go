@@ -0,0 +1,13 @@
+package magicLink
+
+type CommandRequest struct {
+	Email         string
+	CreateAccount bool
+	DeviceID      string
+	IP            string
+}
+
+type CommandConsume struct {
+	Token    string `sensitive:"true"`
+	DeviceID string
+}
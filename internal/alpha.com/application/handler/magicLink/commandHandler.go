@@ -0,0 +1,170 @@
+package magicLink
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"alpha.com/configuration"
+	"alpha.com/internal/alpha.com/application/handler/user"
+	"alpha.com/internal/alpha.com/application/query"
+	"alpha.com/internal/alpha.com/application/repository"
+	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"alpha.com/internal/alpha.com/pkg/server/helpers"
+	"alpha.com/internal/alpha.com/pkg/server/services"
+	"alpha.com/internal/alpha.com/pkg/tracing"
+)
+
+var ErrInvalidMagicLink = errors.New("sign-in link is invalid, expired, already used or was requested from another device")
+
+type ICommandHandler interface {
+	Request(ctx context.Context, command CommandRequest) error
+	Consume(ctx context.Context, command CommandConsume) (user.SignInResult, error)
+}
+
+type commandHandler struct {
+	magicLinkRepository repository.IMagicLinkRepository
+	userQueryService    query.IUserQueryService
+	userCommandHandler  user.ICommandHandler
+	mailService         services.IMailService
+	jwtService          services.IJwtService
+	magicLinkConfig     configuration.MagicLinkConfig
+	backendURL          string
+}
+
+func NewCommandHandler(magicLinkRepository repository.IMagicLinkRepository,
+	userQueryService query.IUserQueryService,
+	userCommandHandler user.ICommandHandler,
+	mailService services.IMailService,
+	jwtService services.IJwtService,
+	magicLinkConfig configuration.MagicLinkConfig,
+	backendURL string,
+) ICommandHandler {
+	return &commandHandler{
+		magicLinkRepository: magicLinkRepository,
+		userQueryService:    userQueryService,
+		userCommandHandler:  userCommandHandler,
+		mailService:         mailService,
+		jwtService:          jwtService,
+		magicLinkConfig:     magicLinkConfig,
+		backendURL:          backendURL,
+	}
+}
+
+// Request mails a sign-in link. Unknown emails get nothing unless the caller
+// asked for an account to be created, and the result is the same either way
+// so the endpoint does not reveal which emails are registered.
+func (c *commandHandler) Request(ctx context.Context, command CommandRequest) error {
+	ctx, span := tracing.Start(ctx, "magicLinkCommandHandler.Request")
+	defer span.End()
+
+	email := strings.TrimSpace(command.Email)
+
+	existingUser, err := c.userQueryService.GetUserByEmail(ctx, email)
+
+	if err != nil && !errors.Is(err, query.ErrUserNotFound) {
+		return err
+	}
+
+	if existingUser == nil && !command.CreateAccount {
+		logger.FromContext(ctx).Info("commandHandler.Request no account for magic link email, nothing sent")
+		return nil
+	}
+
+	linkTTL := c.magicLinkConfig.Time.Duration()
+
+	token, err := helpers.NewRandomToken()
+
+	if err != nil {
+		return err
+	}
+
+	magicLink := c.BuildEntity(command, email, token, time.Now().Add(linkTTL))
+
+	if err := c.magicLinkRepository.Upsert(ctx, magicLink); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/api/v1/alpha/auth/magic-link/consume?token=%s", c.backendURL, url.QueryEscape(token))
+	body := fmt.Sprintf("Hi,\n\nUse the link below to sign in. It works once, for %s, and only in the browser "+
+		"it was requested from:\n\n%s\n\nIf you did not ask for it, you can ignore this mail.\n",
+		c.magicLinkConfig.Time, link)
+
+	return c.mailService.Send(email, "Your sign-in link", body)
+}
+
+// Consume signs in with a link, creating the account on first use when the
+// link was requested with CreateAccount. Users with 2FA still get a challenge.
+func (c *commandHandler) Consume(ctx context.Context, command CommandConsume) (user.SignInResult, error) {
+	ctx, span := tracing.Start(ctx, "magicLinkCommandHandler.Consume")
+	defer span.End()
+
+	if command.Token == "" || command.DeviceID == "" {
+		return user.SignInResult{}, ErrInvalidMagicLink
+	}
+
+	magicLink, err := c.magicLinkRepository.Consume(ctx, hashToken(command.Token), hashToken(command.DeviceID))
+
+	if err != nil {
+		return user.SignInResult{}, err
+	}
+
+	if magicLink == nil {
+		return user.SignInResult{}, ErrInvalidMagicLink
+	}
+
+	existingUser, err := c.userQueryService.GetUserByEmail(ctx, magicLink.Email)
+
+	if errors.Is(err, query.ErrUserNotFound) {
+		if !magicLink.CreateAccount {
+			return user.SignInResult{}, ErrInvalidMagicLink
+		}
+
+		userID, err := c.userCommandHandler.SavePasswordless(ctx, magicLink.Email)
+
+		if err != nil {
+			return user.SignInResult{}, err
+		}
+
+		return user.SignInResult{UserID: userID}, nil
+	}
+
+	if err != nil {
+		return user.SignInResult{}, err
+	}
+
+	if existingUser.MfaEnabled() {
+		challengeToken, err := c.jwtService.CreateMfaChallengeToken(existingUser.Id.Hex())
+
+		if err != nil {
+			return user.SignInResult{}, err
+		}
+
+		return user.SignInResult{MfaChallengeToken: challengeToken}, nil
+	}
+
+	return user.SignInResult{UserID: existingUser.Id.Hex()}, nil
+}
+
+func (c *commandHandler) BuildEntity(command CommandRequest, email string, token string, expiresAt time.Time) *domain.MagicLink {
+	return &domain.MagicLink{
+		Email:         email,
+		TokenHash:     hashToken(token),
+		DeviceHash:    hashToken(command.DeviceID),
+		IP:            command.IP,
+		CreateAccount: command.CreateAccount,
+		ExpiresAt:     expiresAt,
+		CreatedAt:     time.Now(),
+	}
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,11 @@
+package mfa
+
+type Command struct {
+	UserID string
+	Code   string `sensitive:"true"`
+}
+
+type CommandVerify struct {
+	ChallengeToken string `sensitive:"true"`
+	Code           string `sensitive:"true"`
+}
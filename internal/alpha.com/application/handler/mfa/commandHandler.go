@@ -0,0 +1,355 @@
+package mfa
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"alpha.com/configuration"
+	"alpha.com/internal/alpha.com/application/repository"
+	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"alpha.com/internal/alpha.com/pkg/server/services"
+	"alpha.com/internal/alpha.com/pkg/tracing"
+)
+
+var (
+	ErrMfaAlreadyEnabled  = errors.New("two-factor authentication is already enabled")
+	ErrMfaNotEnabled      = errors.New("two-factor authentication is not enabled")
+	ErrMfaNotEnrolled     = errors.New("two-factor authentication enrollment has not been started")
+	ErrInvalidMfaCode     = errors.New("invalid two-factor authentication code")
+	ErrInvalidChallenge   = errors.New("invalid or expired two-factor authentication challenge")
+	ErrTooManyMfaAttempts = errors.New("too many invalid two-factor authentication codes, please try again later")
+)
+
+type ICommandHandler interface {
+	Enroll(ctx context.Context, userID string) (string, string, error)
+	Confirm(ctx context.Context, command Command) ([]string, error)
+	Disable(ctx context.Context, command Command) error
+	RegenerateRecoveryCodes(ctx context.Context, command Command) ([]string, error)
+	Verify(ctx context.Context, command CommandVerify) (string, error)
+}
+
+type commandHandler struct {
+	userRepository         repository.IUserRepository
+	loginAttemptRepository repository.ILoginAttemptRepository
+	totpService            services.ITotpService
+	secretCipher           services.ISecretCipher
+	jwtService             services.IJwtService
+	mfaConfig              configuration.MfaConfig
+	attemptWindow          time.Duration
+}
+
+func NewCommandHandler(userRepository repository.IUserRepository,
+	loginAttemptRepository repository.ILoginAttemptRepository,
+	totpService services.ITotpService,
+	secretCipher services.ISecretCipher,
+	jwtService services.IJwtService,
+	mfaConfig configuration.MfaConfig,
+	attemptWindow time.Duration,
+) ICommandHandler {
+	return &commandHandler{
+		userRepository:         userRepository,
+		loginAttemptRepository: loginAttemptRepository,
+		totpService:            totpService,
+		secretCipher:           secretCipher,
+		jwtService:             jwtService,
+		mfaConfig:              mfaConfig,
+		attemptWindow:          attemptWindow,
+	}
+}
+
+// Enroll issues a new secret and returns it with its otpauth:// URI. 2FA is
+// only switched on once Confirm receives a code generated from it.
+func (c *commandHandler) Enroll(ctx context.Context, userID string) (string, string, error) {
+	ctx, span := tracing.Start(ctx, "mfaCommandHandler.Enroll")
+	defer span.End()
+
+	user, err := c.userRepository.GetById(ctx, userID)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	if user.MfaEnabled() {
+		return "", "", ErrMfaAlreadyEnabled
+	}
+
+	secret, err := c.totpService.GenerateSecret()
+
+	if err != nil {
+		return "", "", err
+	}
+
+	encryptedSecret, err := c.secretCipher.Encrypt(secret)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := c.userRepository.UpdateMfa(ctx, userID, &domain.UserMfa{PendingSecret: encryptedSecret}); err != nil {
+		return "", "", err
+	}
+
+	return secret, c.totpService.ProvisioningURI(secret, user.Email), nil
+}
+
+// Confirm enables 2FA with the pending secret and returns the recovery codes.
+// They are only ever shown here, the database keeps their hashes.
+func (c *commandHandler) Confirm(ctx context.Context, command Command) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "mfaCommandHandler.Confirm")
+	defer span.End()
+
+	user, err := c.userRepository.GetById(ctx, command.UserID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if user.MfaEnabled() {
+		return nil, ErrMfaAlreadyEnabled
+	}
+
+	if user.Mfa == nil || user.Mfa.PendingSecret == "" {
+		return nil, ErrMfaNotEnrolled
+	}
+
+	if err := c.checkAttempts(ctx, command.UserID); err != nil {
+		return nil, err
+	}
+
+	secret, err := c.secretCipher.Decrypt(user.Mfa.PendingSecret)
+
+	if err != nil {
+		return nil, err
+	}
+
+	step, ok := c.totpService.Validate(secret, command.Code, time.Now())
+
+	if !ok {
+		c.registerFailure(ctx, command.UserID)
+		return nil, ErrInvalidMfaCode
+	}
+
+	recoveryCodes, recoveryCodeHashes, err := newRecoveryCodes(c.mfaConfig.RecoveryCodeCount)
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	mfa := &domain.UserMfa{
+		Enabled:            true,
+		Secret:             user.Mfa.PendingSecret,
+		RecoveryCodeHashes: recoveryCodeHashes,
+		LastUsedStep:       step,
+		EnabledAt:          &now,
+	}
+
+	if err := c.userRepository.UpdateMfa(ctx, command.UserID, mfa); err != nil {
+		return nil, err
+	}
+
+	c.resetAttempts(ctx, command.UserID)
+
+	return recoveryCodes, nil
+}
+
+func (c *commandHandler) Disable(ctx context.Context, command Command) error {
+	ctx, span := tracing.Start(ctx, "mfaCommandHandler.Disable")
+	defer span.End()
+
+	user, err := c.userRepository.GetById(ctx, command.UserID)
+
+	if err != nil {
+		return err
+	}
+
+	if !user.MfaEnabled() {
+		return ErrMfaNotEnabled
+	}
+
+	if err := c.verifyCode(ctx, user, command.Code); err != nil {
+		return err
+	}
+
+	return c.userRepository.UpdateMfa(ctx, command.UserID, nil)
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes, for when they were lost
+// or mostly used up.
+func (c *commandHandler) RegenerateRecoveryCodes(ctx context.Context, command Command) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "mfaCommandHandler.RegenerateRecoveryCodes")
+	defer span.End()
+
+	user, err := c.userRepository.GetById(ctx, command.UserID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.MfaEnabled() {
+		return nil, ErrMfaNotEnabled
+	}
+
+	if err := c.verifyCode(ctx, user, command.Code); err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, recoveryCodeHashes, err := newRecoveryCodes(c.mfaConfig.RecoveryCodeCount)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// re-read so the step advanced by verifyCode is kept
+	user, err = c.userRepository.GetById(ctx, command.UserID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	mfa := *user.Mfa
+	mfa.RecoveryCodeHashes = recoveryCodeHashes
+
+	if err := c.userRepository.UpdateMfa(ctx, command.UserID, &mfa); err != nil {
+		return nil, err
+	}
+
+	return recoveryCodes, nil
+}
+
+// Verify checks the second factor for a challenge issued by SignIn and returns
+// the user id to issue tokens for.
+func (c *commandHandler) Verify(ctx context.Context, command CommandVerify) (string, error) {
+	ctx, span := tracing.Start(ctx, "mfaCommandHandler.Verify")
+	defer span.End()
+
+	userID, err := c.jwtService.ParseMfaChallengeToken(command.ChallengeToken)
+
+	if err != nil {
+		return "", ErrInvalidChallenge
+	}
+
+	user, err := c.userRepository.GetById(ctx, userID)
+
+	if err != nil {
+		logger.FromContext(ctx).Error("commandHandler.Verify error while finding user", "user_id", userID, "error", err)
+		return "", ErrInvalidChallenge
+	}
+
+	if !user.MfaEnabled() {
+		return "", ErrInvalidChallenge
+	}
+
+	if err := c.verifyCode(ctx, user, command.Code); err != nil {
+		return "", err
+	}
+
+	return userID, nil
+}
+
+// verifyCode accepts a current TOTP code or an unused recovery code.
+func (c *commandHandler) verifyCode(ctx context.Context, user *domain.User, code string) error {
+	userID := user.Id.Hex()
+
+	if err := c.checkAttempts(ctx, userID); err != nil {
+		return err
+	}
+
+	code = strings.TrimSpace(code)
+
+	if len(code) == 6 {
+		secret, err := c.secretCipher.Decrypt(user.Mfa.Secret)
+
+		if err != nil {
+			return err
+		}
+
+		if step, ok := c.totpService.Validate(secret, code, time.Now()); ok {
+			advanced, err := c.userRepository.AdvanceMfaStep(ctx, userID, step)
+
+			if err != nil {
+				return err
+			}
+
+			if advanced {
+				c.resetAttempts(ctx, userID)
+				return nil
+			}
+		}
+	} else {
+		consumed, err := c.userRepository.ConsumeMfaRecoveryCode(ctx, userID, hashRecoveryCode(code))
+
+		if err != nil {
+			return err
+		}
+
+		if consumed {
+			c.resetAttempts(ctx, userID)
+			return nil
+		}
+	}
+
+	c.registerFailure(ctx, userID)
+
+	return ErrInvalidMfaCode
+}
+
+func (c *commandHandler) checkAttempts(ctx context.Context, userID string) error {
+	attempt, err := c.loginAttemptRepository.Get(ctx, domain.LoginAttemptMfaKey(userID))
+
+	if err != nil {
+		return err
+	}
+
+	if attempt != nil && attempt.Failures >= c.mfaConfig.MaxFailures {
+		return ErrTooManyMfaAttempts
+	}
+
+	return nil
+}
+
+func (c *commandHandler) registerFailure(ctx context.Context, userID string) {
+	_, _ = c.loginAttemptRepository.RegisterFailure(ctx, domain.LoginAttemptMfaKey(userID), time.Now().Add(c.attemptWindow))
+}
+
+func (c *commandHandler) resetAttempts(ctx context.Context, userID string) {
+	if err := c.loginAttemptRepository.Reset(ctx, domain.LoginAttemptMfaKey(userID)); err != nil {
+		logger.FromContext(ctx).Error("commandHandler.resetAttempts error while resetting mfa attempts", "error", err)
+	}
+}
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// newRecoveryCodes returns codes like "abcde-fghij" and their hashes.
+func newRecoveryCodes(count int) ([]string, []string, error) {
+	codes := make([]string, 0, count)
+	hashes := make([]string, 0, count)
+
+	for i := 0; i < count; i++ {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+
+		raw := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))[:10]
+		code := raw[:5] + "-" + raw[5:]
+
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,7 @@
+package report
+
+type Command struct {
+	TargetType string
+	TargetID   string
+	Reason     string
+}
@@ -0,0 +1,87 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"alpha.com/internal/alpha.com/application/repository"
+	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"alpha.com/internal/alpha.com/pkg/tracing"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ICommandHandler interface {
+	Save(ctx context.Context, command Command, userID string) error
+}
+
+type commandHandler struct {
+	reportRepository repository.IReportRepository
+	userRepository   repository.IUserRepository
+	jobRepository    repository.IJobRepository
+}
+
+func NewCommandHandler(reportRepository repository.IReportRepository,
+	userRepository repository.IUserRepository,
+	jobRepository repository.IJobRepository,
+) ICommandHandler {
+	return &commandHandler{
+		reportRepository: reportRepository,
+		userRepository:   userRepository,
+		jobRepository:    jobRepository,
+	}
+}
+
+func (c *commandHandler) Save(ctx context.Context, command Command, userID string) error {
+	ctx, span := tracing.Start(ctx, "reportCommandHandler.Save")
+	defer span.End()
+
+	reporterID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		logger.FromContext(ctx).Error("commandHandler.Save failed", "error", err)
+		return err
+	}
+
+	targetID, err := primitive.ObjectIDFromHex(command.TargetID)
+	if err != nil {
+		logger.FromContext(ctx).Error("commandHandler.Save failed", "error", err)
+		return err
+	}
+
+	switch domain.ReportTargetType(command.TargetType) {
+	case domain.ReportTargetJob:
+		_, err = c.jobRepository.GetByID(ctx, command.TargetID)
+	case domain.ReportTargetUser:
+		_, err = c.userRepository.GetById(ctx, command.TargetID)
+	default:
+		return fmt.Errorf("unknown report target type: %s", command.TargetType)
+	}
+
+	if err != nil {
+		logger.FromContext(ctx).Error("commandHandler.Save error while finding report target", "target_type", command.TargetType, "target_id", command.TargetID, "error", err)
+		return err
+	}
+
+	newReport := c.BuildEntity(command, reporterID, targetID)
+
+	err = c.reportRepository.Upsert(ctx, newReport)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *commandHandler) BuildEntity(command Command, reporterID, targetID primitive.ObjectID) *domain.Report {
+	return &domain.Report{
+		ReporterID: reporterID,
+		TargetType: domain.ReportTargetType(command.TargetType),
+		TargetID:   targetID,
+		Reason:     command.Reason,
+		Status:     domain.ReportStatusOpen,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+}
@@ -0,0 +1,83 @@
+package session
+
+import (
+	"context"
+	"errors"
+
+	"alpha.com/internal/alpha.com/application/query"
+	"alpha.com/internal/alpha.com/application/repository"
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"alpha.com/internal/alpha.com/pkg/tracing"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+type ICommandHandler interface {
+	Revoke(ctx context.Context, sessionID, userID string) error
+	RevokeAll(ctx context.Context, userID string) error
+}
+
+type commandHandler struct {
+	sessionRepository   repository.ISessionRepository
+	jwtRepository       repository.IJwtRepository
+	sessionQueryService query.ISessionQueryService
+}
+
+func NewCommandHandler(sessionRepository repository.ISessionRepository,
+	jwtRepository repository.IJwtRepository,
+	sessionQueryService query.ISessionQueryService,
+) ICommandHandler {
+	return &commandHandler{
+		sessionRepository:   sessionRepository,
+		jwtRepository:       jwtRepository,
+		sessionQueryService: sessionQueryService,
+	}
+}
+
+// Revoke signs out a single session of the given user. Both its refresh
+// tokens and its outstanding access tokens stop working.
+func (c *commandHandler) Revoke(ctx context.Context, sessionID, userID string) error {
+	ctx, span := tracing.Start(ctx, "sessionCommandHandler.Revoke")
+	defer span.End()
+
+	session, err := c.sessionRepository.GetByID(ctx, sessionID)
+
+	if err != nil || session.UserID.Hex() != userID {
+		return ErrSessionNotFound
+	}
+
+	if err := c.sessionRepository.Revoke(ctx, sessionID); err != nil {
+		logger.FromContext(ctx).Error("commandHandler.Revoke error while revoking session", "session_id", sessionID, "error", err)
+		return err
+	}
+
+	if err := c.jwtRepository.RevokeFamily(ctx, sessionID); err != nil {
+		return err
+	}
+
+	c.sessionQueryService.MarkRevoked(sessionID)
+
+	return nil
+}
+
+func (c *commandHandler) RevokeAll(ctx context.Context, userID string) error {
+	ctx, span := tracing.Start(ctx, "sessionCommandHandler.RevokeAll")
+	defer span.End()
+
+	sessionIDs, err := c.sessionRepository.RevokeAllByUserID(ctx, userID)
+
+	if err != nil {
+		logger.FromContext(ctx).Error("commandHandler.RevokeAll error while revoking sessions of user", "user_id", userID, "error", err)
+		return err
+	}
+
+	for _, sessionID := range sessionIDs {
+		if err := c.jwtRepository.RevokeFamily(ctx, sessionID); err != nil {
+			return err
+		}
+	}
+
+	c.sessionQueryService.MarkRevoked(sessionIDs...)
+
+	return nil
+}
@@ -5,11 +5,12 @@ type Command struct {
 	FirstName string
 	LastName  string
 	Email     string
-	Password  string
+	Password  string `sensitive:"true"`
 	Age       int32
 }
 
 type CommandSignIn struct {
 	Email    string
-	Password string
+	Password string `sensitive:"true"`
+	IP       string
 }
@@ -2,50 +2,379 @@ package user
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"math"
 	"time"
 
+	"alpha.com/configuration"
 	"alpha.com/internal/alpha.com/application/repository"
 	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"alpha.com/internal/alpha.com/pkg/metrics"
 	"alpha.com/internal/alpha.com/pkg/server/services"
+	"alpha.com/internal/alpha.com/pkg/tracing"
 )
 
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidUnlockToken = errors.New("unlock link is invalid or has expired")
+)
+
+// ThrottledError is returned while an account or IP address has to wait before
+// the next sign-in attempt.
+type ThrottledError struct {
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *ThrottledError) Error() string {
+	if e.Locked {
+		return "too many failed sign-in attempts, the account is temporarily locked"
+	}
+
+	return "too many failed sign-in attempts, please try again later"
+}
+
+// SignInResult carries either the signed-in user or, when 2FA is on, the
+// challenge token that /auth/mfa/verify exchanges for tokens.
+type SignInResult struct {
+	UserID            string
+	MfaChallengeToken string
+}
+
 type ICommandHandler interface {
 	Save(ctx context.Context, command Command) (string, error)
-	SignIn(ctx context.Context, command CommandSignIn) (string, error)
+	SignIn(ctx context.Context, command CommandSignIn) (SignInResult, error)
+	Unlock(ctx context.Context, token string) error
+	SavePasswordless(ctx context.Context, email string) (string, error)
+	SetPassword(ctx context.Context, userID string, password string) error
+	GrantRole(ctx context.Context, userID string, role domain.Role) error
 }
 
 type commandHandler struct {
-	userRepository repository.IUserRepository
-	userService    services.IUserService
+	userRepository         repository.IUserRepository
+	loginAttemptRepository repository.ILoginAttemptRepository
+	userService            services.IUserService
+	mailService            services.IMailService
+	jwtService             services.IJwtService
+	loginConfig            configuration.LoginConfig
+	backendURL             string
+	businessMetrics        *metrics.BusinessMetrics
+	dummyPasswordHash      string
 }
 
-func NewCommandHandler(userRepository repository.IUserRepository, userService services.IUserService) ICommandHandler {
-	return &commandHandler{
-		userRepository: userRepository,
-		userService:    userService,
+func NewCommandHandler(userRepository repository.IUserRepository,
+	loginAttemptRepository repository.ILoginAttemptRepository,
+	userService services.IUserService,
+	mailService services.IMailService,
+	jwtService services.IJwtService,
+	loginConfig configuration.LoginConfig,
+	backendURL string,
+	businessMetrics *metrics.BusinessMetrics,
+) (ICommandHandler, error) {
+	// compared against when the email is unknown so that both failure paths
+	// take the same time, hashed here to follow the configured parameters.
+	// Without it unknown emails would answer faster, so it is not optional.
+	dummyPasswordHash, err := userService.HashPassword("dummy-password")
+	if err != nil {
+		return nil, fmt.Errorf("dummy password could not be hashed: %w", err)
 	}
+
+	return &commandHandler{
+		userRepository:         userRepository,
+		loginAttemptRepository: loginAttemptRepository,
+		userService:            userService,
+		mailService:            mailService,
+		jwtService:             jwtService,
+		loginConfig:            loginConfig,
+		backendURL:             backendURL,
+		businessMetrics:        businessMetrics,
+		dummyPasswordHash:      dummyPasswordHash,
+	}, nil
 }
 
-func (c *commandHandler) SignIn(ctx context.Context, command CommandSignIn) (string, error) {
+// SignIn checks the credentials behind per account and per IP throttling. An
+// unknown email, a wrong password and a banned or suspended user all return
+// ErrInvalidCredentials. Users with 2FA get a challenge token instead of being
+// signed in.
+func (c *commandHandler) SignIn(ctx context.Context, command CommandSignIn) (SignInResult, error) {
+	ctx, span := tracing.Start(ctx, "userCommandHandler.SignIn")
+	defer span.End()
+
+	now := time.Now()
+	accountKey := domain.LoginAttemptAccountKey(command.Email)
+	ipKey := domain.LoginAttemptIPKey(command.IP)
+
+	for _, key := range []string{accountKey, ipKey} {
+		if err := c.checkThrottle(ctx, key, now); err != nil {
+			return SignInResult{}, err
+		}
+	}
+
 	user, err := c.userRepository.GetByEmail(ctx, command.Email)
 
 	if err != nil {
-		return "", err
+		return SignInResult{}, err
 	}
 
 	if user == nil {
-		return "", fmt.Errorf("user could not find with given email: %s", command.Email)
+		c.checkPassword(ctx, command.Password, c.dummyPasswordHash)
+		if err := c.registerFailure(ctx, accountKey, ipKey, nil, now); err != nil {
+			logger.FromContext(ctx).Error("commandHandler.SignIn error while recording the failed sign-in", "error", err)
+		}
+		return SignInResult{}, ErrInvalidCredentials
+	}
+
+	if !c.checkPassword(ctx, command.Password, user.Password) {
+		if err := c.registerFailure(ctx, accountKey, ipKey, user, now); err != nil {
+			logger.FromContext(ctx).Error("commandHandler.SignIn error while recording the failed sign-in", "error", err)
+		}
+		return SignInResult{}, ErrInvalidCredentials
+	}
+
+	// checked after the password so that the answer does not tell a banned
+	// account from a wrong password
+	if user.IsBlocked(now) {
+		logger.FromContext(ctx).Info("commandHandler.SignIn refused a banned or suspended user", "user_id", user.Id.Hex())
+		return SignInResult{}, ErrInvalidCredentials
+	}
+
+	if err := c.loginAttemptRepository.Reset(ctx, accountKey); err != nil {
+		logger.FromContext(ctx).Error("commandHandler.SignIn error while resetting login attempts", "error", err)
+	}
+
+	c.rehashPassword(ctx, user, command.Password)
+
+	if user.MfaEnabled() {
+		challengeToken, err := c.jwtService.CreateMfaChallengeToken(user.Id.Hex())
+
+		if err != nil {
+			return SignInResult{}, err
+		}
+
+		return SignInResult{MfaChallengeToken: challengeToken}, nil
+	}
+
+	return SignInResult{UserID: user.Id.Hex()}, nil
+}
+
+// hashPassword and checkPassword run argon2id, which is slow on purpose, within
+// spans of their own so that it is told apart from the queries around it.
+func (c *commandHandler) hashPassword(ctx context.Context, password string) (string, error) {
+	_, span := tracing.Start(ctx, "userService.HashPassword")
+	defer span.End()
+
+	hash, err := c.userService.HashPassword(password)
+	span.RecordError(err)
+
+	return hash, err
+}
+
+func (c *commandHandler) checkPassword(ctx context.Context, password, hash string) bool {
+	_, span := tracing.Start(ctx, "userService.CheckPasswordHash")
+	defer span.End()
+
+	return c.userService.CheckPasswordHash(password, hash)
+}
+
+// rehashPassword upgrades a bcrypt or outdated argon2id hash while the plain
+// password is known. A failure leaves the old hash, which still verifies.
+func (c *commandHandler) rehashPassword(ctx context.Context, user *domain.User, password string) {
+	if !c.userService.NeedsRehash(user.Password) {
+		return
 	}
 
-	if !c.userService.CheckPasswordHash(command.Password, user.Password) {
-		return "", fmt.Errorf("password is not correct for given email: %s", command.Email)
+	hashedPassword, err := c.hashPassword(ctx, password)
+	if err != nil {
+		logger.FromContext(ctx).Error("commandHandler.SignIn error while rehashing the password", "error", err)
+		return
 	}
 
-	return user.Id.Hex(), nil
+	if err := c.userRepository.UpdatePassword(ctx, user.Id.Hex(), hashedPassword); err != nil {
+		logger.FromContext(ctx).Error("commandHandler.SignIn error while saving the rehashed password", "error", err)
+	}
+}
+
+// SetPassword replaces the password of the user and lifts a lockout of the
+// account. Sessions are left alone, revoking them is up to the caller.
+func (c *commandHandler) SetPassword(ctx context.Context, userID string, password string) error {
+	ctx, span := tracing.Start(ctx, "userCommandHandler.SetPassword")
+	defer span.End()
+
+	user, err := c.userRepository.GetById(ctx, userID)
+
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := c.hashPassword(ctx, password)
+
+	if err != nil {
+		return fmt.Errorf("password could not hash: %s", err.Error())
+	}
+
+	if err := c.userRepository.UpdatePassword(ctx, userID, hashedPassword); err != nil {
+		return err
+	}
+
+	if err := c.loginAttemptRepository.Reset(ctx, domain.LoginAttemptAccountKey(user.Email)); err != nil {
+		logger.FromContext(ctx).Error("commandHandler.SetPassword error while resetting login attempts", "user_id", userID, "error", err)
+	}
+
+	return nil
+}
+
+// GrantRole adds role to the roles of the user, granting a role the user
+// already has does nothing. Tokens issued before carry the old roles until
+// they are refreshed.
+func (c *commandHandler) GrantRole(ctx context.Context, userID string, role domain.Role) error {
+	ctx, span := tracing.Start(ctx, "userCommandHandler.GrantRole")
+	defer span.End()
+
+	user, err := c.userRepository.GetById(ctx, userID)
+
+	if err != nil {
+		return err
+	}
+
+	if user.HasRole(role) {
+		return nil
+	}
+
+	return c.userRepository.UpdateRoles(ctx, userID, append(user.Roles, role))
+}
+
+func (c *commandHandler) Unlock(ctx context.Context, token string) error {
+	ctx, span := tracing.Start(ctx, "userCommandHandler.Unlock")
+	defer span.End()
+
+	unlocked, err := c.loginAttemptRepository.ResetByUnlockTokenHash(ctx, hashUnlockToken(token))
+
+	if err != nil {
+		return err
+	}
+
+	if !unlocked {
+		return ErrInvalidUnlockToken
+	}
+
+	return nil
+}
+
+func (c *commandHandler) checkThrottle(ctx context.Context, key string, now time.Time) error {
+	attempt, err := c.loginAttemptRepository.Get(ctx, key)
+
+	if err != nil {
+		return err
+	}
+
+	if attempt == nil {
+		return nil
+	}
+
+	if attempt.IsLocked(now) {
+		return &ThrottledError{RetryAfter: attempt.LockedUntil.Sub(now), Locked: true}
+	}
+
+	backoff := c.backoffDelay(attempt.Failures)
+
+	if retryAt := attempt.LastFailedAt.Add(backoff); retryAt.After(now) {
+		return &ThrottledError{RetryAfter: retryAt.Sub(now)}
+	}
+
+	return nil
+}
+
+// registerFailure counts the failure for the account and the IP address and
+// locks whichever went over its limit. Both are recorded independently, the
+// account counter failing to update must not turn the per-IP throttle off.
+// The caller reports invalid credentials either way.
+func (c *commandHandler) registerFailure(ctx context.Context, accountKey, ipKey string, user *domain.User, now time.Time) error {
+	window := c.loginConfig.AttemptWindow.Duration()
+	lockedUntil := now.Add(c.loginConfig.LockoutTime.Duration())
+
+	accountAttempt, accountErr := c.loginAttemptRepository.RegisterFailure(ctx, accountKey, now.Add(window))
+	if accountErr == nil && accountAttempt.Failures >= c.loginConfig.MaxAccountFailures {
+		c.lockAccount(ctx, accountKey, user, lockedUntil, lockedUntil.Add(window))
+	}
+
+	ipAttempt, ipErr := c.loginAttemptRepository.RegisterFailure(ctx, ipKey, now.Add(window))
+	if ipErr == nil && ipAttempt.Failures >= c.loginConfig.MaxIPFailures {
+		ipErr = c.loginAttemptRepository.Lock(ctx, ipKey, lockedUntil, "", lockedUntil.Add(window))
+	}
+
+	return errors.Join(accountErr, ipErr)
+}
+
+// lockAccount locks the account key and mails an unlock link when the email
+// belongs to a user. Unknown emails are locked the same way but get no mail.
+func (c *commandHandler) lockAccount(ctx context.Context, accountKey string, user *domain.User, lockedUntil, expiresAt time.Time) {
+	if user == nil {
+		_ = c.loginAttemptRepository.Lock(ctx, accountKey, lockedUntil, "", expiresAt)
+		return
+	}
+
+	token, err := newUnlockToken()
+	if err != nil {
+		logger.FromContext(ctx).Error("commandHandler.SignIn error while creating unlock token", "error", err)
+		_ = c.loginAttemptRepository.Lock(ctx, accountKey, lockedUntil, "", expiresAt)
+		return
+	}
+
+	if err := c.loginAttemptRepository.Lock(ctx, accountKey, lockedUntil, hashUnlockToken(token), expiresAt); err != nil {
+		return
+	}
+
+	unlockURL := fmt.Sprintf("%s/api/v1/alpha/user/unlock?token=%s", c.backendURL, token)
+	body := fmt.Sprintf("Hi %s,\n\nYour account was locked after too many failed sign-in attempts. "+
+		"It unlocks itself at %s. If it was you, you can unlock it right away:\n\n%s\n\n"+
+		"If it was not you, consider changing your password.\n",
+		user.FirstName, lockedUntil.UTC().Format(time.RFC1123), unlockURL)
+
+	if err := c.mailService.Send(user.Email, "Your account has been locked", body); err != nil {
+		logger.FromContext(ctx).Error("commandHandler.SignIn error while sending unlock mail", "error", err)
+	}
+}
+
+// backoffDelay doubles the wait after every failure beyond the free ones.
+func (c *commandHandler) backoffDelay(failures int) time.Duration {
+	if failures < c.loginConfig.FreeAttempts {
+		return 0
+	}
+
+	base := c.loginConfig.BackoffBase.Duration()
+	maxDelay := c.loginConfig.BackoffMax.Duration()
+
+	delay := time.Duration(float64(base) * math.Pow(2, float64(failures-c.loginConfig.FreeAttempts)))
+	if delay > maxDelay || delay <= 0 {
+		return maxDelay
+	}
+
+	return delay
+}
+
+func newUnlockToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+func hashUnlockToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
 func (c *commandHandler) Save(ctx context.Context, command Command) (string, error) {
+	ctx, span := tracing.Start(ctx, "userCommandHandler.Save")
+	defer span.End()
+
 	user, err := c.userRepository.GetByEmail(ctx, command.Email)
 
 	if err != nil {
@@ -56,7 +385,7 @@ func (c *commandHandler) Save(ctx context.Context, command Command) (string, err
 		return "", fmt.Errorf("user Already Exist for given email: %s", command.Email)
 	}
 
-	hashedPassword, err := c.userService.HashPassword(command.Password)
+	hashedPassword, err := c.hashPassword(ctx, command.Password)
 
 	if err != nil {
 		return "", fmt.Errorf("password could not hash: %s", err.Error())
@@ -74,6 +403,31 @@ func (c *commandHandler) Save(ctx context.Context, command Command) (string, err
 		return "", fmt.Errorf("user could not be saved: %s", command.Email)
 	}
 
+	c.businessMetrics.SignUps.Inc()
+
+	return objectID, nil
+}
+
+// SavePasswordless creates the minimal account for a first magic-link sign-in.
+// It has no password, so it can only sign in by magic link until one is set.
+func (c *commandHandler) SavePasswordless(ctx context.Context, email string) (string, error) {
+	ctx, span := tracing.Start(ctx, "userCommandHandler.SavePasswordless")
+	defer span.End()
+
+	newUser := c.BuildEntity(Command{Email: email}, "")
+
+	objectID, err := c.userRepository.Upsert(ctx, newUser)
+
+	if err != nil {
+		return "", err
+	}
+
+	if objectID == "" {
+		return "", fmt.Errorf("user could not be saved: %s", email)
+	}
+
+	c.businessMetrics.SignUps.Inc()
+
 	return objectID, nil
 }
 
@@ -84,6 +438,8 @@ func (c *commandHandler) BuildEntity(command Command, hashedPassword string) *do
 		Email:     command.Email,
 		Password:  hashedPassword,
 		Age:       command.Age,
+		Roles:     []domain.Role{domain.RoleCandidate},
+		Status:    domain.UserStatusActive,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
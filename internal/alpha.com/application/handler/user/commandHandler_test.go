@@ -0,0 +1,189 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"alpha.com/configuration"
+	"alpha.com/internal/alpha.com/application/repository"
+	"alpha.com/internal/alpha.com/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type fakeUserRepository struct {
+	repository.IUserRepository
+	user *domain.User
+}
+
+func (r *fakeUserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	if r.user == nil || r.user.Email != email {
+		return nil, nil
+	}
+
+	return r.user, nil
+}
+
+type fakeLoginAttemptRepository struct {
+	repository.ILoginAttemptRepository
+	// failing makes RegisterFailure fail for these keys
+	failing    map[string]error
+	registered []string
+}
+
+func (r *fakeLoginAttemptRepository) Get(ctx context.Context, key string) (*domain.LoginAttempt, error) {
+	return nil, nil
+}
+
+func (r *fakeLoginAttemptRepository) RegisterFailure(ctx context.Context, key string, expiresAt time.Time) (*domain.LoginAttempt, error) {
+	if err := r.failing[key]; err != nil {
+		return nil, err
+	}
+
+	r.registered = append(r.registered, key)
+
+	return &domain.LoginAttempt{Key: key, Failures: 1}, nil
+}
+
+func (r *fakeLoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	return nil
+}
+
+// fakeUserService hashes a password to "hash:" followed by the password, or
+// fails with hashErr when it is set.
+type fakeUserService struct {
+	hashErr error
+}
+
+func (s *fakeUserService) HashPassword(password string) (string, error) {
+	if s.hashErr != nil {
+		return "", s.hashErr
+	}
+
+	return "hash:" + password, nil
+}
+
+func (s *fakeUserService) CheckPasswordHash(password, hash string) bool {
+	return hash == "hash:"+password
+}
+
+func (s *fakeUserService) NeedsRehash(hash string) bool {
+	return false
+}
+
+func newTestCommandHandler(t *testing.T, user *domain.User) ICommandHandler {
+	t.Helper()
+
+	return newTestCommandHandlerWithAttempts(t, user, &fakeLoginAttemptRepository{})
+}
+
+func newTestCommandHandlerWithAttempts(t *testing.T, user *domain.User, loginAttemptRepository *fakeLoginAttemptRepository) ICommandHandler {
+	t.Helper()
+
+	handler, err := NewCommandHandler(
+		&fakeUserRepository{user: user},
+		loginAttemptRepository,
+		&fakeUserService{},
+		nil,
+		nil,
+		configuration.Default().Login,
+		"http://localhost",
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("NewCommandHandler failed: %v", err)
+	}
+
+	return handler
+}
+
+func TestNewCommandHandlerFailsWhenTheDummyPasswordCannotBeHashed(t *testing.T) {
+	hashErr := errors.New("hashing failed")
+
+	handler, err := NewCommandHandler(
+		&fakeUserRepository{},
+		&fakeLoginAttemptRepository{},
+		&fakeUserService{hashErr: hashErr},
+		nil,
+		nil,
+		configuration.Default().Login,
+		"http://localhost",
+		nil,
+	)
+
+	if !errors.Is(err, hashErr) {
+		t.Fatalf("NewCommandHandler returned %v, want %v", err, hashErr)
+	}
+
+	if handler != nil {
+		t.Errorf("NewCommandHandler returned a handler without a dummy password hash")
+	}
+}
+
+func TestSignInRefusesBlockedUsers(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name           string
+		status         domain.UserStatus
+		suspendedUntil *time.Time
+		err            error
+	}{
+		{"active", domain.UserStatusActive, nil, nil},
+		{"banned", domain.UserStatusBanned, nil, ErrInvalidCredentials},
+		{"suspended", domain.UserStatusSuspended, &future, ErrInvalidCredentials},
+		{"suspended without end", domain.UserStatusSuspended, nil, ErrInvalidCredentials},
+		{"suspension over", domain.UserStatusSuspended, &past, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			user := &domain.User{
+				Id:             primitive.NewObjectID(),
+				Email:          "user@example.com",
+				Password:       "hash:secret",
+				Status:         test.status,
+				SuspendedUntil: test.suspendedUntil,
+			}
+
+			result, err := newTestCommandHandler(t, user).SignIn(context.Background(), CommandSignIn{
+				Email:    "user@example.com",
+				Password: "secret",
+				IP:       "203.0.113.1",
+			})
+
+			if !errors.Is(err, test.err) {
+				t.Fatalf("SignIn returned %v, want %v", err, test.err)
+			}
+
+			if test.err != nil && result.UserID != "" {
+				t.Errorf("SignIn signed in a %s user", test.status)
+			}
+		})
+	}
+}
+
+func TestSignInRecordsTheIPFailureWhenTheAccountFailureCannotBeRecorded(t *testing.T) {
+	loginAttemptRepository := &fakeLoginAttemptRepository{
+		failing: map[string]error{domain.LoginAttemptAccountKey("user@example.com"): errors.New("mongo unavailable")},
+	}
+
+	user := &domain.User{Id: primitive.NewObjectID(), Email: "user@example.com", Password: "hash:secret"}
+
+	_, err := newTestCommandHandlerWithAttempts(t, user, loginAttemptRepository).SignIn(context.Background(), CommandSignIn{
+		Email:    "user@example.com",
+		Password: "wrong",
+		IP:       "203.0.113.1",
+	})
+
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("SignIn returned %v, want %v", err, ErrInvalidCredentials)
+	}
+
+	ipKey := domain.LoginAttemptIPKey("203.0.113.1")
+	if len(loginAttemptRepository.registered) != 1 || loginAttemptRepository.registered[0] != ipKey {
+		t.Errorf("SignIn recorded failures for %v, want %s", loginAttemptRepository.registered, ipKey)
+	}
+}
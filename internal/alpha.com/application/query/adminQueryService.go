@@ -0,0 +1,54 @@
+package query
+
+import (
+	"context"
+
+	"alpha.com/internal/alpha.com/application/repository"
+	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/tracing"
+)
+
+type IAdminQueryService interface {
+	SearchUsers(ctx context.Context, search string, status string) ([]*domain.User, error)
+	GetModerationQueue(ctx context.Context) ([]*domain.Report, error)
+	GetAdminActions(ctx context.Context) ([]*domain.AdminAction, error)
+}
+
+type adminQueryService struct {
+	userRepository        repository.IUserRepository
+	reportRepository      repository.IReportRepository
+	adminActionRepository repository.IAdminActionRepository
+}
+
+func NewAdminQueryService(
+	userRepository repository.IUserRepository,
+	reportRepository repository.IReportRepository,
+	adminActionRepository repository.IAdminActionRepository,
+) IAdminQueryService {
+	return &adminQueryService{
+		userRepository:        userRepository,
+		reportRepository:      reportRepository,
+		adminActionRepository: adminActionRepository,
+	}
+}
+
+func (u *adminQueryService) SearchUsers(ctx context.Context, search string, status string) ([]*domain.User, error) {
+	ctx, span := tracing.Start(ctx, "adminQueryService.SearchUsers")
+	defer span.End()
+
+	return u.userRepository.Search(ctx, search, status)
+}
+
+func (u *adminQueryService) GetModerationQueue(ctx context.Context) ([]*domain.Report, error) {
+	ctx, span := tracing.Start(ctx, "adminQueryService.GetModerationQueue")
+	defer span.End()
+
+	return u.reportRepository.GetByStatus(ctx, domain.ReportStatusOpen)
+}
+
+func (u *adminQueryService) GetAdminActions(ctx context.Context) ([]*domain.AdminAction, error) {
+	ctx, span := tracing.Start(ctx, "adminQueryService.GetAdminActions")
+	defer span.End()
+
+	return u.adminActionRepository.Get(ctx)
+}
@@ -0,0 +1,138 @@
+package query
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"alpha.com/internal/alpha.com/application/repository"
+	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"alpha.com/internal/alpha.com/pkg/tracing"
+)
+
+const apiKeyPrefix = "ak_"
+
+var (
+	ErrInvalidApiKey           = errors.New("invalid, expired or revoked api key")
+	ErrBusinessAccountNotFound = errors.New("business account could not be found")
+)
+
+type IApiKeyQueryService interface {
+	GetByBusinessAccountID(ctx context.Context, businessAccountId string, userId string) ([]*domain.ApiKey, error)
+	GetOwnedBusinessAccount(ctx context.Context, businessAccountId string, userId string) (*domain.BusinessAccount, error)
+	Authenticate(ctx context.Context, rawKey string) (*domain.ApiKey, *domain.BusinessAccount, error)
+}
+
+type apiKeyQueryService struct {
+	apiKeyRepository          repository.IApiKeyRepository
+	businessAccountRepository repository.IBusinessAccountRepository
+}
+
+func NewApiKeyQueryService(apiKeyRepository repository.IApiKeyRepository, businessAccountRepository repository.IBusinessAccountRepository) IApiKeyQueryService {
+	return &apiKeyQueryService{
+		apiKeyRepository:          apiKeyRepository,
+		businessAccountRepository: businessAccountRepository,
+	}
+}
+
+func (q *apiKeyQueryService) GetByBusinessAccountID(ctx context.Context, businessAccountId string, userId string) ([]*domain.ApiKey, error) {
+	ctx, span := tracing.Start(ctx, "apiKeyQueryService.GetByBusinessAccountID")
+	defer span.End()
+
+	if _, err := q.GetOwnedBusinessAccount(ctx, businessAccountId, userId); err != nil {
+		return nil, err
+	}
+
+	return q.apiKeyRepository.GetByBusinessAccountID(ctx, businessAccountId)
+}
+
+// GetOwnedBusinessAccount returns ErrBusinessAccountNotFound for accounts of
+// other users as well, so ids of other companies cannot be probed.
+func (q *apiKeyQueryService) GetOwnedBusinessAccount(ctx context.Context, businessAccountId string, userId string) (*domain.BusinessAccount, error) {
+	ctx, span := tracing.Start(ctx, "apiKeyQueryService.GetOwnedBusinessAccount")
+	defer span.End()
+
+	businessAccount, err := q.businessAccountRepository.GetByIDAndUserID(ctx, businessAccountId, userId)
+
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && businessAccount == nil) {
+		return nil, ErrBusinessAccountNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return businessAccount, nil
+}
+
+// Authenticate resolves a key presented in X-Api-Key. The business account is
+// returned too since requests act as its owner.
+func (q *apiKeyQueryService) Authenticate(ctx context.Context, rawKey string) (*domain.ApiKey, *domain.BusinessAccount, error) {
+	ctx, span := tracing.Start(ctx, "apiKeyQueryService.Authenticate")
+	defer span.End()
+
+	prefix, secret, ok := ParseApiKey(rawKey)
+	if !ok {
+		return nil, nil, ErrInvalidApiKey
+	}
+
+	apiKey, err := q.apiKeyRepository.GetByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+
+	if apiKey == nil || !apiKey.IsActive(now) {
+		return nil, nil, ErrInvalidApiKey
+	}
+
+	if subtle.ConstantTimeCompare([]byte(apiKey.SecretHash), []byte(HashApiKeySecret(secret))) != 1 {
+		return nil, nil, ErrInvalidApiKey
+	}
+
+	businessAccount, err := q.businessAccountRepository.GetByID(ctx, apiKey.BusinessAccountID.Hex())
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && businessAccount == nil) {
+		return nil, nil, ErrInvalidApiKey
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := q.apiKeyRepository.TouchLastUsed(ctx, apiKey.Id, now); err != nil {
+		logger.FromContext(ctx).Error("apiKeyQueryService.Authenticate error while tracking api key usage", "error", err)
+	}
+
+	return apiKey, businessAccount, nil
+}
+
+// FormatApiKey builds the key handed to the integration, "ak_<prefix>.<secret>".
+func FormatApiKey(prefix, secret string) string {
+	return apiKeyPrefix + prefix + "." + secret
+}
+
+func ParseApiKey(rawKey string) (string, string, bool) {
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		return "", "", false
+	}
+
+	prefix, secret, ok := strings.Cut(strings.TrimPrefix(rawKey, apiKeyPrefix), ".")
+	if !ok || prefix == "" || secret == "" {
+		return "", "", false
+	}
+
+	return prefix, secret, true
+}
+
+// HashApiKeySecret can be a plain SHA-256, the secrets are 256 random bits and
+// not worth a slow password hash.
+func HashApiKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -6,6 +6,7 @@ import (
 
 	"alpha.com/internal/alpha.com/application/repository"
 	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/tracing"
 )
 
 type IBusinessAccountQueryService interface {
@@ -25,6 +26,9 @@ func NewBusinessAccountQueryService(businessAccountRepository repository.IBusine
 }
 
 func (u *businessAccountQueryService) GetAllBusinessAccounts(ctx context.Context) ([]*domain.BusinessAccount, error) {
+	ctx, span := tracing.Start(ctx, "businessAccountQueryService.GetAllBusinessAccounts")
+	defer span.End()
+
 	businessAccounts, err := u.businessAccountRepository.Get(ctx)
 
 	if err != nil {
@@ -39,6 +43,9 @@ func (u *businessAccountQueryService) GetAllBusinessAccounts(ctx context.Context
 }
 
 func (u *businessAccountQueryService) GetByID(ctx context.Context, id string) (*domain.BusinessAccount, error) {
+	ctx, span := tracing.Start(ctx, "businessAccountQueryService.GetByID")
+	defer span.End()
+
 	businessAccount, err := u.businessAccountRepository.GetByID(ctx, id)
 
 	if err != nil {
@@ -53,6 +60,9 @@ func (u *businessAccountQueryService) GetByID(ctx context.Context, id string) (*
 }
 
 func (u *businessAccountQueryService) GetByIDAndUserID(ctx context.Context, id string, userID string) (*domain.BusinessAccount, error) {
+	ctx, span := tracing.Start(ctx, "businessAccountQueryService.GetByIDAndUserID")
+	defer span.End()
+
 	businessAccount, err := u.businessAccountRepository.GetByIDAndUserID(ctx, id, userID)
 
 	if err != nil {
@@ -0,0 +1,30 @@
+package query
+
+import (
+	"context"
+
+	"alpha.com/internal/alpha.com/application/repository"
+	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/tracing"
+)
+
+type IExternalIdentityQueryService interface {
+	GetByUserID(ctx context.Context, userId string) ([]*domain.ExternalIdentity, error)
+}
+
+type externalIdentityQueryService struct {
+	externalIdentityRepository repository.IExternalIdentityRepository
+}
+
+func NewExternalIdentityQueryService(externalIdentityRepository repository.IExternalIdentityRepository) IExternalIdentityQueryService {
+	return &externalIdentityQueryService{
+		externalIdentityRepository: externalIdentityRepository,
+	}
+}
+
+func (q *externalIdentityQueryService) GetByUserID(ctx context.Context, userId string) ([]*domain.ExternalIdentity, error) {
+	ctx, span := tracing.Start(ctx, "externalIdentityQueryService.GetByUserID")
+	defer span.End()
+
+	return q.externalIdentityRepository.GetByUserID(ctx, userId)
+}
@@ -6,23 +6,37 @@ import (
 
 	"alpha.com/internal/alpha.com/application/repository"
 	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/tracing"
 )
 
+var ErrJobNotFound = errors.New("job could not be found")
+
 type IJobApplyQueryService interface {
 	GetAllJobApplies(ctx context.Context) ([]*domain.JobApply, error)
+	GetByBusinessAccountJob(ctx context.Context, businessAccountID, jobID, userID string) ([]*domain.JobApply, error)
 }
 
 type jobApplyQueryService struct {
 	jobApplyRepository repository.IJobApplyRepository
+	jobQueryService    IJobQueryService
+	apiKeyQueryService IApiKeyQueryService
 }
 
-func NewJobApplyQueryService(jobApplyRepository repository.IJobApplyRepository) IJobApplyQueryService {
+func NewJobApplyQueryService(jobApplyRepository repository.IJobApplyRepository,
+	jobQueryService IJobQueryService,
+	apiKeyQueryService IApiKeyQueryService,
+) IJobApplyQueryService {
 	return &jobApplyQueryService{
 		jobApplyRepository: jobApplyRepository,
+		jobQueryService:    jobQueryService,
+		apiKeyQueryService: apiKeyQueryService,
 	}
 }
 
 func (u *jobApplyQueryService) GetAllJobApplies(ctx context.Context) ([]*domain.JobApply, error) {
+	ctx, span := tracing.Start(ctx, "jobApplyQueryService.GetAllJobApplies")
+	defer span.End()
+
 	jobApplies, err := u.jobApplyRepository.Get(ctx)
 
 	if err != nil {
@@ -35,3 +49,20 @@ func (u *jobApplyQueryService) GetAllJobApplies(ctx context.Context) ([]*domain.
 
 	return jobApplies, nil
 }
+
+// GetByBusinessAccountJob returns the applications to a job of a business
+// account owned by the user.
+func (u *jobApplyQueryService) GetByBusinessAccountJob(ctx context.Context, businessAccountID, jobID, userID string) ([]*domain.JobApply, error) {
+	ctx, span := tracing.Start(ctx, "jobApplyQueryService.GetByBusinessAccountJob")
+	defer span.End()
+
+	if _, err := u.apiKeyQueryService.GetOwnedBusinessAccount(ctx, businessAccountID, userID); err != nil {
+		return nil, err
+	}
+
+	if _, err := u.jobQueryService.GetByIDAndBusinessAccountID(ctx, jobID, businessAccountID); err != nil {
+		return nil, ErrJobNotFound
+	}
+
+	return u.jobApplyRepository.GetByJobID(ctx, jobID)
+}
@@ -6,6 +6,7 @@ import (
 
 	"alpha.com/internal/alpha.com/application/repository"
 	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/tracing"
 )
 
 type IJobQueryService interface {
@@ -24,6 +25,9 @@ func NewJobQueryService(jobRepository repository.IJobRepository) IJobQueryServic
 }
 
 func (u *jobQueryService) GetAllJobs(ctx context.Context) ([]*domain.Job, error) {
+	ctx, span := tracing.Start(ctx, "jobQueryService.GetAllJobs")
+	defer span.End()
+
 	jobs, err := u.jobRepository.Get(ctx)
 
 	if err != nil {
@@ -38,6 +42,9 @@ func (u *jobQueryService) GetAllJobs(ctx context.Context) ([]*domain.Job, error)
 }
 
 func (u *jobQueryService) GetByIDAndBusinessAccountID(ctx context.Context, id, businessAccountID string) (*domain.Job, error) {
+	ctx, span := tracing.Start(ctx, "jobQueryService.GetByIDAndBusinessAccountID")
+	defer span.End()
+
 	job, err := u.jobRepository.GetByIDAndBusinessAccountID(ctx, id, businessAccountID)
 
 	if err != nil {
@@ -5,53 +5,26 @@ import (
 
 	"alpha.com/internal/alpha.com/application/repository"
 	"alpha.com/internal/alpha.com/domain"
-	"alpha.com/internal/alpha.com/pkg/server/services"
+	"alpha.com/internal/alpha.com/pkg/tracing"
 )
 
 type IJwtQueryService interface {
-	GetUserById(ctx context.Context, userId string) (*domain.User, error)
-	ParseRefreshToken(ctx context.Context, refreshToken string) (string, error)
 	Get(ctx context.Context) ([]*domain.Jwt, error)
 }
 
 type jwtQueryService struct {
-	jwtRepository    repository.IJwtRepository
-	userQueryService IUserQueryService
-	jwtService       services.IJwtService
+	jwtRepository repository.IJwtRepository
 }
 
-func NewJwtQueryService(jwtRepository repository.IJwtRepository, userQueryService IUserQueryService, jwtService services.IJwtService) IJwtQueryService {
+func NewJwtQueryService(jwtRepository repository.IJwtRepository) IJwtQueryService {
 	return &jwtQueryService{
-		jwtRepository:    jwtRepository,
-		userQueryService: userQueryService,
-		jwtService:       jwtService,
+		jwtRepository: jwtRepository,
 	}
 }
 
-func (u *jwtQueryService) GetUserById(ctx context.Context, userId string) (*domain.User, error) {
-	user, err := u.userQueryService.GetUserById(ctx, userId)
-
-	if err != nil {
-		return nil, err
-	}
-
-	if user == nil {
-		return nil, nil
-	}
-
-	return user, nil
-}
-
-func (u *jwtQueryService) ParseRefreshToken(ctx context.Context, refreshToken string) (string, error) {
-	userID, err := u.jwtService.ParseRefreshToken(refreshToken)
-
-	if err != nil {
-		return "", err
-	}
-
-	return userID, nil
-}
-
 func (c *jwtQueryService) Get(ctx context.Context) ([]*domain.Jwt, error) {
+	ctx, span := tracing.Start(ctx, "jwtQueryService.Get")
+	defer span.End()
+
 	return c.jwtRepository.Get(ctx)
 }
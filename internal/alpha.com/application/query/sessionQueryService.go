@@ -0,0 +1,113 @@
+package query
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"alpha.com/internal/alpha.com/application/repository"
+	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/tracing"
+	"golang.org/x/sync/singleflight"
+)
+
+// revocationListTTL bounds how long a logout on one replica takes to be
+// enforced by the others.
+const revocationListTTL = 15 * time.Second
+
+type ISessionQueryService interface {
+	GetActiveSessions(ctx context.Context, userId string) ([]*domain.Session, error)
+	IsRevoked(ctx context.Context, sessionId string) (bool, error)
+	MarkRevoked(sessionIds ...string)
+}
+
+type sessionQueryService struct {
+	sessionRepository repository.ISessionRepository
+	reloads           singleflight.Group
+	mutex             sync.RWMutex
+	revoked           map[string]struct{}
+	expiresAt         time.Time
+}
+
+func NewSessionQueryService(sessionRepository repository.ISessionRepository) ISessionQueryService {
+	return &sessionQueryService{
+		sessionRepository: sessionRepository,
+		revoked:           make(map[string]struct{}),
+	}
+}
+
+func (s *sessionQueryService) GetActiveSessions(ctx context.Context, userId string) ([]*domain.Session, error) {
+	ctx, span := tracing.Start(ctx, "sessionQueryService.GetActiveSessions")
+	defer span.End()
+
+	return s.sessionRepository.GetActiveByUserID(ctx, userId)
+}
+
+func (s *sessionQueryService) IsRevoked(ctx context.Context, sessionId string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "sessionQueryService.IsRevoked")
+	defer span.End()
+
+	s.mutex.RLock()
+	fresh := time.Now().Before(s.expiresAt)
+	_, revoked := s.revoked[sessionId]
+	s.mutex.RUnlock()
+
+	if fresh {
+		return revoked, nil
+	}
+
+	// every request arriving once the list expired waits for the same reload
+	// instead of loading the whole list itself, one of them giving up must not
+	// fail the others, the operation timeout bounds it. A request that saw the
+	// list expired just before a reload finished finds it fresh again.
+	if _, err, _ := s.reloads.Do("revoked", func() (interface{}, error) {
+		s.mutex.RLock()
+		fresh := time.Now().Before(s.expiresAt)
+		s.mutex.RUnlock()
+
+		if fresh {
+			return nil, nil
+		}
+
+		return nil, s.reload(context.WithoutCancel(ctx))
+	}); err != nil {
+		return false, err
+	}
+
+	s.mutex.RLock()
+	_, revoked = s.revoked[sessionId]
+	s.mutex.RUnlock()
+
+	return revoked, nil
+}
+
+// MarkRevoked makes a revocation done on this replica effective immediately
+// instead of after the next reload.
+func (s *sessionQueryService) MarkRevoked(sessionIds ...string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, sessionId := range sessionIds {
+		s.revoked[sessionId] = struct{}{}
+	}
+}
+
+func (s *sessionQueryService) reload(ctx context.Context) error {
+	sessionIds, err := s.sessionRepository.GetRevokedUnexpiredIDs(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	revoked := make(map[string]struct{}, len(sessionIds))
+	for _, sessionId := range sessionIds {
+		revoked[sessionId] = struct{}{}
+	}
+
+	s.mutex.Lock()
+	s.revoked = revoked
+	s.expiresAt = time.Now().Add(revocationListTTL)
+	s.mutex.Unlock()
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package query
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"alpha.com/internal/alpha.com/application/repository"
+)
+
+type fakeSessionRepository struct {
+	repository.ISessionRepository
+	loads   atomic.Int32
+	release chan struct{}
+}
+
+func (r *fakeSessionRepository) GetRevokedUnexpiredIDs(ctx context.Context) ([]string, error) {
+	r.loads.Add(1)
+	<-r.release
+
+	return []string{"revoked-session"}, nil
+}
+
+func TestIsRevokedReloadsOnceForConcurrentRequests(t *testing.T) {
+	sessionRepository := &fakeSessionRepository{release: make(chan struct{})}
+	sessionQueryService := NewSessionQueryService(sessionRepository)
+
+	var wait sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+
+			revoked, err := sessionQueryService.IsRevoked(context.Background(), "revoked-session")
+			if err != nil || !revoked {
+				t.Errorf("IsRevoked returned %v, %v, want true", revoked, err)
+			}
+		}()
+	}
+
+	// let the requests pile up on the expired list before the load returns
+	for sessionRepository.loads.Load() == 0 {
+		runtime.Gosched()
+	}
+	close(sessionRepository.release)
+	wait.Wait()
+
+	if loads := sessionRepository.loads.Load(); loads != 1 {
+		t.Errorf("the revoked sessions were loaded %d times, want 1", loads)
+	}
+}
@@ -6,8 +6,11 @@ import (
 
 	"alpha.com/internal/alpha.com/application/repository"
 	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/tracing"
 )
 
+var ErrUserNotFound = errors.New("not found error")
+
 type IUserQueryService interface {
 	GetUser(ctx context.Context) ([]*domain.User, error)
 	GetUserById(ctx context.Context, userId string) (*domain.User, error)
@@ -25,6 +28,8 @@ func NewUserQueryService(userRepository repository.IUserRepository) IUserQuerySe
 }
 
 func (u *userQueryService) GetUser(ctx context.Context) ([]*domain.User, error) {
+	ctx, span := tracing.Start(ctx, "userQueryService.GetUser")
+	defer span.End()
 
 	users, err := u.userRepository.Get(ctx)
 
@@ -40,6 +45,9 @@ func (u *userQueryService) GetUser(ctx context.Context) ([]*domain.User, error)
 }
 
 func (u *userQueryService) GetUserById(ctx context.Context, userId string) (*domain.User, error) {
+	ctx, span := tracing.Start(ctx, "userQueryService.GetUserById")
+	defer span.End()
+
 	user, err := u.userRepository.GetById(ctx, userId)
 
 	if err != nil {
@@ -47,13 +55,15 @@ func (u *userQueryService) GetUserById(ctx context.Context, userId string) (*dom
 	}
 
 	if user == nil {
-		return nil, errors.New("not found error")
+		return nil, ErrUserNotFound
 	}
 
 	return user, nil
 }
 
 func (u *userQueryService) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	ctx, span := tracing.Start(ctx, "userQueryService.GetUserByEmail")
+	defer span.End()
 
 	user, err := u.userRepository.GetByEmail(ctx, email)
 
@@ -62,7 +72,7 @@ func (u *userQueryService) GetUserByEmail(ctx context.Context, email string) (*d
 	}
 
 	if user == nil {
-		return nil, errors.New("not found error")
+		return nil, ErrUserNotFound
 	}
 
 	return user, nil
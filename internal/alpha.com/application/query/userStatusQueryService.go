@@ -0,0 +1,76 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"alpha.com/internal/alpha.com/application/repository"
+	"alpha.com/internal/alpha.com/pkg/tracing"
+)
+
+// userStatusCacheTTL bounds how long a ban takes to reach the other replicas.
+const userStatusCacheTTL = 30 * time.Second
+
+type IUserStatusQueryService interface {
+	IsBlocked(ctx context.Context, userId string) (bool, error)
+	Invalidate(userId string)
+}
+
+type userStatusCacheEntry struct {
+	blocked   bool
+	expiresAt time.Time
+}
+
+type userStatusQueryService struct {
+	userRepository repository.IUserRepository
+	mutex          sync.RWMutex
+	cache          map[string]userStatusCacheEntry
+}
+
+func NewUserStatusQueryService(userRepository repository.IUserRepository) IUserStatusQueryService {
+	return &userStatusQueryService{
+		userRepository: userRepository,
+		cache:          make(map[string]userStatusCacheEntry),
+	}
+}
+
+func (u *userStatusQueryService) IsBlocked(ctx context.Context, userId string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "userStatusQueryService.IsBlocked")
+	defer span.End()
+
+	now := time.Now()
+
+	u.mutex.RLock()
+	entry, ok := u.cache[userId]
+	u.mutex.RUnlock()
+
+	if ok && now.Before(entry.expiresAt) {
+		return entry.blocked, nil
+	}
+
+	user, err := u.userRepository.GetById(ctx, userId)
+
+	blocked := false
+	if errors.Is(err, repository.ErrNotFound) {
+		// tokens of deleted users are rejected as well
+		blocked = true
+	} else if err != nil {
+		return false, err
+	} else {
+		blocked = user.IsBlocked(now)
+	}
+
+	u.mutex.Lock()
+	u.cache[userId] = userStatusCacheEntry{blocked: blocked, expiresAt: now.Add(userStatusCacheTTL)}
+	u.mutex.Unlock()
+
+	return blocked, nil
+}
+
+func (u *userStatusQueryService) Invalidate(userId string) {
+	u.mutex.Lock()
+	delete(u.cache, userId)
+	u.mutex.Unlock()
+}
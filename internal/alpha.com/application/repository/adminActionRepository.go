@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"alpha.com/configuration"
+	"alpha.com/internal/alpha.com/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type IAdminActionRepository interface {
+	Get(ctx context.Context) ([]*domain.AdminAction, error)
+	Upsert(ctx context.Context, adminAction *domain.AdminAction) error
+}
+
+type adminActionRepository struct {
+	*MongoRepository[domain.AdminAction]
+}
+
+func NewAdminActionRepository(mongoClient *mongo.Client, mongoConfig configuration.MongoConfig) IAdminActionRepository {
+	return &adminActionRepository{
+		MongoRepository: NewMongoRepository[domain.AdminAction](mongoClient, mongoConfig, mongoConfig.Collections.AdminActions, "adminAction"),
+	}
+}
+
+// Get returns the most recent actions first.
+func (r *adminActionRepository) Get(ctx context.Context) ([]*domain.AdminAction, error) {
+	return r.Find(ctx, bson.D{}, FindOptions{Sort: bson.D{{Key: "createdAt", Value: -1}}})
+}
+
+func (r *adminActionRepository) Upsert(ctx context.Context, adminAction *domain.AdminAction) error {
+	_, err := r.Insert(ctx, adminAction)
+	return err
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"alpha.com/configuration"
+	"alpha.com/internal/alpha.com/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type IApiKeyRepository interface {
+	GetByPrefix(ctx context.Context, prefix string) (*domain.ApiKey, error)
+	GetByBusinessAccountID(ctx context.Context, businessAccountId string) ([]*domain.ApiKey, error)
+	Upsert(ctx context.Context, apiKey *domain.ApiKey) (string, error)
+	Revoke(ctx context.Context, apiKeyId string, businessAccountId string) (bool, error)
+	TouchLastUsed(ctx context.Context, apiKeyId primitive.ObjectID, usedAt time.Time) error
+}
+
+type apiKeyRepository struct {
+	*MongoRepository[domain.ApiKey]
+}
+
+func NewApiKeyRepository(mongoClient *mongo.Client, mongoConfig configuration.MongoConfig) IApiKeyRepository {
+	return &apiKeyRepository{
+		MongoRepository: NewMongoRepository[domain.ApiKey](mongoClient, mongoConfig, mongoConfig.Collections.ApiKeys, "apiKey"),
+	}
+}
+
+// GetByPrefix returns nil and no error for an unknown prefix.
+func (r *apiKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*domain.ApiKey, error) {
+	apiKey, err := r.FindOne(ctx, bson.M{"prefix": prefix})
+
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+
+	return apiKey, err
+}
+
+// GetByBusinessAccountID returns the most recent keys first.
+func (r *apiKeyRepository) GetByBusinessAccountID(ctx context.Context, businessAccountId string) ([]*domain.ApiKey, error) {
+	objectID, err := primitive.ObjectIDFromHex(businessAccountId)
+	if err != nil {
+		return make([]*domain.ApiKey, 0), nil
+	}
+
+	return r.Find(ctx, bson.M{"businessAccountId": objectID}, FindOptions{Sort: bson.D{{Key: "createdAt", Value: -1}}})
+}
+
+func (r *apiKeyRepository) Upsert(ctx context.Context, apiKey *domain.ApiKey) (string, error) {
+	objectID, err := r.Insert(ctx, apiKey)
+	if err != nil {
+		return "", err
+	}
+
+	return objectID.Hex(), nil
+}
+
+// Revoke returns false when the business account has no such key or it was
+// revoked already.
+func (r *apiKeyRepository) Revoke(ctx context.Context, apiKeyId string, businessAccountId string) (bool, error) {
+	objectID, err := primitive.ObjectIDFromHex(apiKeyId)
+	if err != nil {
+		return false, nil
+	}
+
+	objectIDForBusinessAccount, err := primitive.ObjectIDFromHex(businessAccountId)
+	if err != nil {
+		return false, nil
+	}
+
+	now := time.Now()
+	filter := bson.M{
+		"_id":               objectID,
+		"businessAccountId": objectIDForBusinessAccount,
+		"revokedAt":         bson.M{"$exists": false},
+	}
+	update := bson.M{"$set": bson.M{"revokedAt": now, "updatedAt": now}}
+
+	return applied(r.Update(ctx, filter, update))
+}
+
+// TouchLastUsed records usage at most once a minute per key, so busy
+// integrations do not turn every request into a write.
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, apiKeyId primitive.ObjectID, usedAt time.Time) error {
+	filter := bson.M{
+		"_id": apiKeyId,
+		"$or": bson.A{
+			bson.M{"lastUsedAt": bson.M{"$exists": false}},
+			bson.M{"lastUsedAt": bson.M{"$lt": usedAt.Add(-time.Minute)}},
+		},
+	}
+
+	_, err := applied(r.Update(ctx, filter, bson.M{"$set": bson.M{"lastUsedAt": usedAt}}))
+
+	return err
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"alpha.com/configuration"
+	"alpha.com/internal/alpha.com/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type IExternalIdentityRepository interface {
+	GetByProviderSubject(ctx context.Context, provider string, subject string) (*domain.ExternalIdentity, error)
+	GetByUserID(ctx context.Context, userId string) ([]*domain.ExternalIdentity, error)
+	Upsert(ctx context.Context, identity *domain.ExternalIdentity) error
+	Touch(ctx context.Context, identityId primitive.ObjectID) error
+	Delete(ctx context.Context, userId string, provider string) (bool, error)
+}
+
+type externalIdentityRepository struct {
+	*MongoRepository[domain.ExternalIdentity]
+}
+
+func NewExternalIdentityRepository(mongoClient *mongo.Client, mongoConfig configuration.MongoConfig) IExternalIdentityRepository {
+	return &externalIdentityRepository{
+		MongoRepository: NewMongoRepository[domain.ExternalIdentity](mongoClient, mongoConfig, mongoConfig.Collections.ExternalIdentities, "externalIdentity"),
+	}
+}
+
+// GetByProviderSubject returns nil and no error when the account was never
+// linked.
+func (r *externalIdentityRepository) GetByProviderSubject(ctx context.Context, provider string, subject string) (*domain.ExternalIdentity, error) {
+	identity, err := r.FindOne(ctx, bson.M{"provider": provider, "subject": subject})
+
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+
+	return identity, err
+}
+
+func (r *externalIdentityRepository) GetByUserID(ctx context.Context, userId string) ([]*domain.ExternalIdentity, error) {
+	objectID, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return make([]*domain.ExternalIdentity, 0), nil
+	}
+
+	return r.Find(ctx, bson.M{"userId": objectID}, FindOptions{})
+}
+
+func (r *externalIdentityRepository) Upsert(ctx context.Context, identity *domain.ExternalIdentity) error {
+	_, err := r.Insert(ctx, identity)
+	return err
+}
+
+func (r *externalIdentityRepository) Touch(ctx context.Context, identityId primitive.ObjectID) error {
+	_, err := applied(r.Update(ctx, bson.M{"_id": identityId}, bson.M{"$set": bson.M{"lastUsedAt": time.Now()}}))
+	return err
+}
+
+// Delete returns false when the user has no identity of the provider.
+func (r *externalIdentityRepository) Delete(ctx context.Context, userId string, provider string) (bool, error) {
+	objectID, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return false, nil
+	}
+
+	return applied(r.MongoRepository.Delete(ctx, bson.M{"userId": objectID, "provider": provider}))
+}
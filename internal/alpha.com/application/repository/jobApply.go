@@ -2,7 +2,6 @@ package repository
 
 import (
 	"context"
-	"fmt"
 
 	"alpha.com/configuration"
 	"alpha.com/internal/alpha.com/domain"
@@ -13,67 +12,41 @@ import (
 
 type IJobApplyRepository interface {
 	Get(ctx context.Context) ([]*domain.JobApply, error)
+	GetByJobID(ctx context.Context, jobId string) ([]*domain.JobApply, error)
 	Upsert(ctx context.Context, jobApply *domain.JobApply) error
+	Replace(ctx context.Context, jobApply *domain.JobApply) error
 }
 
 type jobApplyRepository struct {
-	mongoClient *mongo.Client
+	*MongoRepository[domain.JobApply]
 }
 
-func NewJobApplyRepository(mongoClient *mongo.Client) IJobApplyRepository {
+func NewJobApplyRepository(mongoClient *mongo.Client, mongoConfig configuration.MongoConfig) IJobApplyRepository {
 	return &jobApplyRepository{
-		mongoClient: mongoClient,
+		MongoRepository: NewMongoRepository[domain.JobApply](mongoClient, mongoConfig, mongoConfig.Collections.JobApplies, "jobApply"),
 	}
 }
 
 func (r *jobApplyRepository) Get(ctx context.Context) ([]*domain.JobApply, error) {
-	collection := r.mongoClient.Database(configuration.MONGO_DB_NAME).Collection(configuration.MONGO_JOB_APPLIES_DB_NAME)
-
-	var jobApplys []*domain.JobApply
-	cursor, err := collection.Find(context.TODO(), bson.D{})
-
-	if err != nil {
-		fmt.Printf("jobApplyRepository.Get ERROR : %s\n", err.Error())
-		return make([]*domain.JobApply, 0), err
-	}
-
-	defer cursor.Close(context.TODO())
-
-	for cursor.Next(context.TODO()) {
-		var jobApply *domain.JobApply
-		err := cursor.Decode(&jobApply)
-		if err != nil {
-			fmt.Printf("jobApplyRepository.Get ERROR : %s\n", err.Error())
-			return make([]*domain.JobApply, 0), err
-		}
-
-		jobApplys = append(jobApplys, jobApply)
-	}
-
-	if err := cursor.Err(); err != nil {
-		fmt.Printf("jobApplyRepository.Get ERROR : %s\n", err.Error())
-	}
-
-	if jobApplys == nil {
-		fmt.Println("jobApplyRepository.Get INFO not found users on datasource")
-		return make([]*domain.JobApply, 0), nil
-	}
-
-	return jobApplys, nil
+	return r.Find(ctx, bson.D{}, FindOptions{})
 }
 
 func (r *jobApplyRepository) Upsert(ctx context.Context, jobApply *domain.JobApply) error {
-	collection := r.mongoClient.Database(configuration.MONGO_DB_NAME).Collection(configuration.MONGO_JOB_APPLIES_DB_NAME)
-
-	insertResult, err := collection.InsertOne(context.TODO(), jobApply)
+	_, err := r.Insert(ctx, jobApply)
+	return err
+}
 
+// GetByJobID returns no applications for an id that is not an ObjectID.
+func (r *jobApplyRepository) GetByJobID(ctx context.Context, jobId string) ([]*domain.JobApply, error) {
+	objectID, err := primitive.ObjectIDFromHex(jobId)
 	if err != nil {
-		return err
+		return make([]*domain.JobApply, 0), nil
 	}
 
-	objectID := insertResult.InsertedID.(primitive.ObjectID)
-
-	fmt.Printf("jobApplyRepository.Upsert INFO user saved with id: %s\n", objectID.Hex())
+	return r.Find(ctx, bson.M{"jobId": objectID}, FindOptions{})
+}
 
-	return nil
+// Replace writes the whole document, inserting it when its id is unknown.
+func (r *jobApplyRepository) Replace(ctx context.Context, jobApply *domain.JobApply) error {
+	return r.ReplaceByID(ctx, jobApply.Id, jobApply)
 }
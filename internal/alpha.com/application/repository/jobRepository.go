@@ -2,7 +2,7 @@ package repository
 
 import (
 	"context"
-	"fmt"
+	"time"
 
 	"alpha.com/configuration"
 	"alpha.com/internal/alpha.com/domain"
@@ -14,94 +14,68 @@ import (
 type IJobRepository interface {
 	Get(ctx context.Context) ([]*domain.Job, error)
 	Upsert(ctx context.Context, job *domain.Job) error
+	Replace(ctx context.Context, job *domain.Job) error
 	GetByIDAndBusinessAccountID(ctx context.Context, id, businessAccountID string) (*domain.Job, error)
+	GetByID(ctx context.Context, id string) (*domain.Job, error)
+	UpdateStatus(ctx context.Context, id string, status domain.JobStatus, reason string) error
+	Delete(ctx context.Context, id string) error
 }
 
 type jobRepository struct {
-	mongoClient *mongo.Client
+	*MongoRepository[domain.Job]
 }
 
-func NewJobRepository(mongoClient *mongo.Client) IJobRepository {
+func NewJobRepository(mongoClient *mongo.Client, mongoConfig configuration.MongoConfig) IJobRepository {
 	return &jobRepository{
-		mongoClient: mongoClient,
+		MongoRepository: NewMongoRepository[domain.Job](mongoClient, mongoConfig, mongoConfig.Collections.Jobs, "job"),
 	}
 }
 
 func (r *jobRepository) Get(ctx context.Context) ([]*domain.Job, error) {
-	collection := r.mongoClient.Database(configuration.MONGO_DB_NAME).Collection(configuration.MONGO_JOBS_DB_NAME)
-
-	var jobs []*domain.Job
-	cursor, err := collection.Find(context.TODO(), bson.D{})
-
-	if err != nil {
-		fmt.Printf("jobRepository.Get ERROR : %s\n", err.Error())
-		return make([]*domain.Job, 0), err
-	}
-
-	defer cursor.Close(context.TODO())
-
-	for cursor.Next(context.TODO()) {
-		var job *domain.Job
-		err := cursor.Decode(&job)
-		if err != nil {
-			fmt.Printf("jobRepository.Get ERROR : %s\n", err.Error())
-			return make([]*domain.Job, 0), err
-		}
-
-		jobs = append(jobs, job)
-	}
-
-	if err := cursor.Err(); err != nil {
-		fmt.Printf("jobRepository.Get ERROR : %s\n", err.Error())
-	}
-
-	if jobs == nil {
-		fmt.Println("jobRepository.Get INFO not found users on datasource")
-		return make([]*domain.Job, 0), nil
-	}
-
-	return jobs, nil
+	// unpublished jobs are hidden from the public listing
+	return r.Find(ctx, bson.M{"status": bson.M{"$ne": domain.JobStatusUnpublished}}, FindOptions{})
 }
 
 func (r *jobRepository) Upsert(ctx context.Context, job *domain.Job) error {
-	collection := r.mongoClient.Database(configuration.MONGO_DB_NAME).Collection(configuration.MONGO_JOBS_DB_NAME)
-
-	insertResult, err := collection.InsertOne(context.TODO(), job)
-
-	if err != nil {
-		return err
-	}
-
-	objectID := insertResult.InsertedID.(primitive.ObjectID)
-
-	fmt.Printf("jobRepository.Upsert INFO user saved with id: %s\n", objectID.Hex())
-
-	return nil
+	_, err := r.Insert(ctx, job)
+	return err
 }
 
 func (r *jobRepository) GetByIDAndBusinessAccountID(ctx context.Context, id, businessAccountID string) (*domain.Job, error) {
-	collection := r.mongoClient.Database(configuration.MONGO_DB_NAME).Collection(configuration.MONGO_JOBS_DB_NAME)
-
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		fmt.Printf("jobRepository.GetByIDAndUserID ERROR :  %s\n", err.Error())
-		return nil, err
+		return nil, r.notFound()
 	}
 
 	objectIDForBusinessAccount, err := primitive.ObjectIDFromHex(businessAccountID)
 	if err != nil {
-		fmt.Printf("jobRepository.GetByIDAndUserID ERROR :  %s\n", err.Error())
-		return nil, err
+		return nil, r.notFound()
 	}
 
-	filter := bson.M{"_id": objectID, "businessAccountId": objectIDForBusinessAccount}
+	return r.FindOne(ctx, bson.M{"_id": objectID, "businessAccountId": objectIDForBusinessAccount})
+}
 
-	var job *domain.Job
-	err = collection.FindOne(context.Background(), filter).Decode(&job)
+func (r *jobRepository) GetByID(ctx context.Context, id string) (*domain.Job, error) {
+	return r.FindByID(ctx, id)
+}
 
-	if err != nil {
-		return nil, err
+func (r *jobRepository) UpdateStatus(ctx context.Context, id string, status domain.JobStatus, reason string) error {
+	update := bson.M{
+		"$set": bson.M{
+			"status":           status,
+			"moderationReason": reason,
+			"updatedAt":        time.Now(),
+		},
 	}
 
-	return job, nil
+	return r.UpdateByID(ctx, id, update)
+}
+
+func (r *jobRepository) Delete(ctx context.Context, id string) error {
+	return r.DeleteByID(ctx, id)
+}
+
+// Replace writes the whole document, inserting it when its id is unknown.
+func (r *jobRepository) Replace(ctx context.Context, job *domain.Job) error {
+	return r.ReplaceByID(ctx, job.Id, job)
 }
@@ -2,111 +2,92 @@ package repository
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"time"
 
 	"alpha.com/configuration"
 	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/logger"
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type IJwtRepository interface {
 	Get(ctx context.Context) ([]*domain.Jwt, error)
-	GetById(ctx context.Context, userId string) (*domain.Jwt, error)
+	GetByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (*domain.Jwt, error)
 	Upsert(ctx context.Context, jwt *domain.Jwt) error
-	Update(ctx context.Context, userID, accessToken, refreshToken string) error
+	MarkRotated(ctx context.Context, refreshTokenHash string) (bool, error)
+	RevokeFamily(ctx context.Context, familyID string) error
 }
 
 type jwtRepository struct {
-	mongoClient *mongo.Client
+	*MongoRepository[domain.Jwt]
 }
 
-func NewJwtRepository(mongoClient *mongo.Client) IJwtRepository {
+func NewJwtRepository(mongoClient *mongo.Client, mongoConfig configuration.MongoConfig) IJwtRepository {
 	return &jwtRepository{
-		mongoClient: mongoClient,
+		MongoRepository: NewMongoRepository[domain.Jwt](mongoClient, mongoConfig, mongoConfig.Collections.Jwts, "jwt"),
 	}
 }
 
 func (r *jwtRepository) Get(ctx context.Context) ([]*domain.Jwt, error) {
+	return r.Find(ctx, bson.D{}, FindOptions{})
+}
 
-	collection := r.mongoClient.Database(configuration.MONGO_DB_NAME).Collection(configuration.MONGO_JWT_DB_NAME)
-
-	var jwts []*domain.Jwt
-	cursor, err := collection.Find(context.TODO(), bson.D{})
-
-	if err != nil {
-		fmt.Printf("jwtRepository.Get ERROR : %s\n", err.Error())
-		return make([]*domain.Jwt, 0), err
-	}
-
-	defer cursor.Close(context.TODO())
-
-	for cursor.Next(context.TODO()) {
-		var jwt *domain.Jwt
-		err := cursor.Decode(&jwt)
-		if err != nil {
-			fmt.Printf("jwtRepository.Get ERROR : %s\n", err.Error())
-			return make([]*domain.Jwt, 0), err
-		}
-
-		jwts = append(jwts, jwt)
-	}
-
-	if err := cursor.Err(); err != nil {
-		fmt.Printf("jwtRepository.Get ERROR : %s\n", err.Error())
-	}
+// GetByRefreshTokenHash returns nil and no error for an unknown refresh token.
+func (r *jwtRepository) GetByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (*domain.Jwt, error) {
+	jwt, err := r.FindOne(ctx, bson.M{"refreshTokenHash": refreshTokenHash})
 
-	if jwts == nil {
-		fmt.Println("jwtRepository.Get INFO not found users on datasource")
-		return make([]*domain.Jwt, 0), nil
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
 	}
 
-	return jwts, nil
-}
-
-func (r *jwtRepository) GetById(ctx context.Context, userId string) (*domain.Jwt, error) {
-	return nil, nil
+	return jwt, err
 }
 
 func (r *jwtRepository) Upsert(ctx context.Context, jwt *domain.Jwt) error {
-	collection := r.mongoClient.Database(configuration.MONGO_DB_NAME).Collection(configuration.MONGO_JWT_DB_NAME)
-
-	insertResult, err := collection.InsertOne(context.TODO(), jwt)
+	_, err := r.Insert(ctx, jwt)
+	return err
+}
 
-	if err != nil {
-		return err
+// MarkRotated flags the refresh token as used. It returns false when the token
+// had already been rotated or revoked, which callers must treat as reuse.
+func (r *jwtRepository) MarkRotated(ctx context.Context, refreshTokenHash string) (bool, error) {
+	now := time.Now()
+	filter := bson.M{
+		"refreshTokenHash": refreshTokenHash,
+		"rotatedAt":        bson.M{"$exists": false},
+		"revokedAt":        bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"rotatedAt": now,
+			"updatedAt": now,
+		},
 	}
 
-	objectID := insertResult.InsertedID.(primitive.ObjectID)
-
-	fmt.Printf("jwtRepository.Upsert INFO user saved with id: %s\n", objectID.Hex())
-
-	return nil
+	return applied(r.Update(ctx, filter, update))
 }
 
-func (r *jwtRepository) Update(ctx context.Context, userID, accessToken, refreshToken string) error {
-	objectID, err := primitive.ObjectIDFromHex(userID)
-
-	if err != nil {
-		fmt.Printf("jwtRepository.Update ERROR :  %s\n", err.Error())
+func (r *jwtRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	now := time.Now()
+	filter := bson.M{
+		"familyId":  familyID,
+		"revokedAt": bson.M{"$exists": false},
 	}
-
-	filter := bson.M{"refreshToken": refreshToken, "userId": objectID}
 	update := bson.M{
 		"$set": bson.M{
-			"accessToken": accessToken,
-			"updatedAt":   time.Now(),
+			"revokedAt": now,
+			"updatedAt": now,
 		},
 	}
 
-	collection := r.mongoClient.Database(configuration.MONGO_DB_NAME).Collection(configuration.MONGO_JWT_DB_NAME)
-
-	_, err = collection.UpdateOne(context.Background(), filter, update)
+	count, err := r.UpdateMany(ctx, filter, update)
 	if err != nil {
 		return err
 	}
 
+	logger.FromContext(ctx).Info("jwtRepository.RevokeFamily refresh tokens revoked", "count", count, "family_id", familyID)
+
 	return nil
 }
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"alpha.com/configuration"
+	"alpha.com/internal/alpha.com/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type ILoginAttemptRepository interface {
+	Get(ctx context.Context, key string) (*domain.LoginAttempt, error)
+	RegisterFailure(ctx context.Context, key string, expiresAt time.Time) (*domain.LoginAttempt, error)
+	Lock(ctx context.Context, key string, lockedUntil time.Time, unlockTokenHash string, expiresAt time.Time) error
+	Reset(ctx context.Context, key string) error
+	ResetByUnlockTokenHash(ctx context.Context, unlockTokenHash string) (bool, error)
+}
+
+type loginAttemptRepository struct {
+	*MongoRepository[domain.LoginAttempt]
+}
+
+func NewLoginAttemptRepository(mongoClient *mongo.Client, mongoConfig configuration.MongoConfig) ILoginAttemptRepository {
+	return &loginAttemptRepository{
+		MongoRepository: NewMongoRepository[domain.LoginAttempt](mongoClient, mongoConfig, mongoConfig.Collections.LoginAttempts, "loginAttempt"),
+	}
+}
+
+// Get returns nil and no error when the key has no failure recorded.
+func (r *loginAttemptRepository) Get(ctx context.Context, key string) (*domain.LoginAttempt, error) {
+	attempt, err := r.FindOne(ctx, bson.M{"key": key})
+
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+
+	return attempt, err
+}
+
+// RegisterFailure increments the failure counter atomically and returns the
+// document as it is after the update.
+func (r *loginAttemptRepository) RegisterFailure(ctx context.Context, key string, expiresAt time.Time) (*domain.LoginAttempt, error) {
+	now := time.Now()
+	update := bson.M{
+		"$inc": bson.M{"failures": 1},
+		"$set": bson.M{
+			"lastFailedAt": now,
+			"expiresAt":    expiresAt,
+			"updatedAt":    now,
+		},
+		"$setOnInsert": bson.M{"createdAt": now},
+	}
+
+	return r.FindOneAndUpdate(ctx, bson.M{"key": key}, update, true)
+}
+
+// Lock starts a lockout and resets the counter, so the attempts allowed after
+// the lockout are backed off from scratch.
+func (r *loginAttemptRepository) Lock(ctx context.Context, key string, lockedUntil time.Time, unlockTokenHash string, expiresAt time.Time) error {
+	set := bson.M{
+		"failures":    0,
+		"lockedUntil": lockedUntil,
+		"expiresAt":   expiresAt,
+		"updatedAt":   time.Now(),
+	}
+	if unlockTokenHash != "" {
+		set["unlockTokenHash"] = unlockTokenHash
+	}
+
+	_, err := applied(r.Update(ctx, bson.M{"key": key}, bson.M{"$set": set}))
+
+	return err
+}
+
+func (r *loginAttemptRepository) Reset(ctx context.Context, key string) error {
+	_, err := applied(r.Delete(ctx, bson.M{"key": key}))
+	return err
+}
+
+func (r *loginAttemptRepository) ResetByUnlockTokenHash(ctx context.Context, unlockTokenHash string) (bool, error) {
+	return applied(r.Delete(ctx, bson.M{"unlockTokenHash": unlockTokenHash}))
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"alpha.com/configuration"
+	"alpha.com/internal/alpha.com/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type IMagicLinkRepository interface {
+	Upsert(ctx context.Context, magicLink *domain.MagicLink) error
+	Consume(ctx context.Context, tokenHash string, deviceHash string) (*domain.MagicLink, error)
+}
+
+type magicLinkRepository struct {
+	*MongoRepository[domain.MagicLink]
+}
+
+func NewMagicLinkRepository(mongoClient *mongo.Client, mongoConfig configuration.MongoConfig) IMagicLinkRepository {
+	return &magicLinkRepository{
+		MongoRepository: NewMongoRepository[domain.MagicLink](mongoClient, mongoConfig, mongoConfig.Collections.MagicLinks, "magicLink"),
+	}
+}
+
+func (r *magicLinkRepository) Upsert(ctx context.Context, magicLink *domain.MagicLink) error {
+	_, err := r.Insert(ctx, magicLink)
+	return err
+}
+
+// Consume marks an unused, unexpired link requested from the same device as
+// used and returns it. It returns nil when there is no such link, so two
+// concurrent requests cannot both sign in with the same link, and opening it on
+// another device does not burn it.
+func (r *magicLinkRepository) Consume(ctx context.Context, tokenHash string, deviceHash string) (*domain.MagicLink, error) {
+	now := time.Now()
+	filter := bson.M{
+		"tokenHash":  tokenHash,
+		"deviceHash": deviceHash,
+		"consumedAt": bson.M{"$exists": false},
+		"expiresAt":  bson.M{"$gt": now},
+	}
+
+	magicLink, err := r.FindOneAndUpdate(ctx, filter, bson.M{"$set": bson.M{"consumedAt": now}}, false)
+
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+
+	return magicLink, err
+}
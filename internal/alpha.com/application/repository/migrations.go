@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"alpha.com/configuration"
+	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/migration"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Migrations is every change of the database, in the order it was made.
+// Applied migrations must never be edited, add a new version instead.
+// Versions 1 to 6 are the indexes the repositories used to create on startup,
+// named the same way so that existing databases already have them.
+func Migrations(collections configuration.MongoCollections) []migration.Migration {
+	return []migration.Migration{
+		{
+			Version: 1,
+			Name:    "login attempt indexes",
+			Indexes: []migration.Index{
+				{Collection: collections.LoginAttempts, Keys: bson.D{{Key: "key", Value: 1}}, Unique: true},
+				{Collection: collections.LoginAttempts, Keys: bson.D{{Key: "expiresAt", Value: 1}}, ExpireAfter: migration.ExpireAt()},
+				{Collection: collections.LoginAttempts, Keys: bson.D{{Key: "unlockTokenHash", Value: 1}}, Sparse: true},
+			},
+		},
+		{
+			Version: 2,
+			Name:    "magic link indexes",
+			Indexes: []migration.Index{
+				{Collection: collections.MagicLinks, Keys: bson.D{{Key: "tokenHash", Value: 1}}, Unique: true},
+				{Collection: collections.MagicLinks, Keys: bson.D{{Key: "expiresAt", Value: 1}}, ExpireAfter: migration.ExpireAt()},
+			},
+		},
+		{
+			Version: 3,
+			Name:    "external identity indexes",
+			Indexes: []migration.Index{
+				{Collection: collections.ExternalIdentities, Keys: bson.D{{Key: "provider", Value: 1}, {Key: "subject", Value: 1}}, Unique: true},
+				{Collection: collections.ExternalIdentities, Keys: bson.D{{Key: "userId", Value: 1}, {Key: "provider", Value: 1}}, Unique: true},
+			},
+		},
+		{
+			Version: 4,
+			Name:    "oidc state indexes",
+			Indexes: []migration.Index{
+				{Collection: collections.OidcStates, Keys: bson.D{{Key: "stateHash", Value: 1}}, Unique: true},
+				{Collection: collections.OidcStates, Keys: bson.D{{Key: "expiresAt", Value: 1}}, ExpireAfter: migration.ExpireAt()},
+			},
+		},
+		{
+			Version: 5,
+			Name:    "api key indexes",
+			Indexes: []migration.Index{
+				{Collection: collections.ApiKeys, Keys: bson.D{{Key: "prefix", Value: 1}}, Unique: true},
+				{Collection: collections.ApiKeys, Keys: bson.D{{Key: "businessAccountId", Value: 1}}},
+			},
+		},
+		{
+			Version: 6,
+			Name:    "rate limit indexes",
+			Indexes: []migration.Index{
+				{Collection: collections.RateLimits, Keys: bson.D{{Key: "expiresAt", Value: 1}}, ExpireAfter: migration.ExpireAt()},
+			},
+		},
+		{
+			// sign-up only checks for an existing email, two concurrent
+			// sign-ups could both pass, the index settles it
+			Version:   7,
+			Name:      "unique user email",
+			Transform: failOnDuplicateEmails(collections.Users),
+			Revert:    func(ctx context.Context, db *mongo.Database) error { return nil },
+			Indexes: []migration.Index{
+				{Collection: collections.Users, Keys: bson.D{{Key: "email", Value: 1}}, Unique: true},
+			},
+		},
+		{
+			Version: 8,
+			Name:    "lookup indexes",
+			Indexes: []migration.Index{
+				{Collection: collections.Jobs, Keys: bson.D{{Key: "businessAccountId", Value: 1}}},
+				{Collection: collections.Jobs, Keys: bson.D{{Key: "status", Value: 1}}},
+				{Collection: collections.JobApplies, Keys: bson.D{{Key: "jobId", Value: 1}}},
+				{Collection: collections.JobApplies, Keys: bson.D{{Key: "userId", Value: 1}}},
+				{Collection: collections.BusinessAccounts, Keys: bson.D{{Key: "userId", Value: 1}}},
+				{Collection: collections.Jwts, Keys: bson.D{{Key: "refreshTokenHash", Value: 1}}},
+				{Collection: collections.Jwts, Keys: bson.D{{Key: "familyId", Value: 1}}},
+				{Collection: collections.Sessions, Keys: bson.D{{Key: "userId", Value: 1}, {Key: "lastUsedAt", Value: -1}}},
+				{Collection: collections.Reports, Keys: bson.D{{Key: "status", Value: 1}, {Key: "createdAt", Value: 1}}},
+				{Collection: collections.AdminActions, Keys: bson.D{{Key: "createdAt", Value: -1}}},
+			},
+		},
+		{
+			// routes check permissions since roles were added, users made
+			// before would have none and owners of business accounts would
+			// lose access to their jobs
+			Version:   9,
+			Name:      "backfill user roles",
+			Transform: backfillUserRoles(collections.Users, collections.BusinessAccounts),
+			Revert:    func(ctx context.Context, db *mongo.Database) error { return nil },
+		},
+	}
+}
+
+// backfillUserRoles makes users without a role candidates and adds the
+// employer role to the owners of business accounts.
+func backfillUserRoles(usersCollection, businessAccountsCollection string) func(ctx context.Context, db *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		users := db.Collection(usersCollection)
+
+		withoutRoles := bson.M{"$or": bson.A{
+			bson.M{"roles": bson.M{"$exists": false}},
+			bson.M{"roles": nil},
+			bson.M{"roles": bson.M{"$size": 0}},
+		}}
+
+		if _, err := users.UpdateMany(ctx, withoutRoles, bson.M{"$set": bson.M{"roles": bson.A{domain.RoleCandidate}}}); err != nil {
+			return err
+		}
+
+		ownerIDs, err := db.Collection(businessAccountsCollection).Distinct(ctx, "userId", bson.M{})
+		if err != nil {
+			return err
+		}
+
+		if len(ownerIDs) == 0 {
+			return nil
+		}
+
+		_, err = users.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": ownerIDs}}, bson.M{"$addToSet": bson.M{"roles": domain.RoleEmployer}})
+
+		return err
+	}
+}
+
+// failOnDuplicateEmails stops the migration with the emails used more than
+// once, which accounts to keep is for an operator to decide.
+func failOnDuplicateEmails(usersCollection string) func(ctx context.Context, db *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		pipeline := mongo.Pipeline{
+			{{Key: "$group", Value: bson.D{{Key: "_id", Value: "$email"}, {Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}}}}},
+			{{Key: "$match", Value: bson.D{{Key: "count", Value: bson.D{{Key: "$gt", Value: 1}}}}}},
+			{{Key: "$limit", Value: 20}},
+		}
+
+		cursor, err := db.Collection(usersCollection).Aggregate(ctx, pipeline)
+		if err != nil {
+			return err
+		}
+
+		var duplicates []struct {
+			Email string `bson:"_id"`
+			Count int    `bson:"count"`
+		}
+		if err := cursor.All(ctx, &duplicates); err != nil {
+			return err
+		}
+
+		if len(duplicates) == 0 {
+			return nil
+		}
+
+		emails := make([]string, 0, len(duplicates))
+		for _, duplicate := range duplicates {
+			emails = append(emails, fmt.Sprintf("%s (%d)", duplicate.Email, duplicate.Count))
+		}
+
+		return fmt.Errorf("emails used by several users, merge or delete them first: %s", strings.Join(emails, ", "))
+	}
+}
@@ -0,0 +1,322 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"alpha.com/configuration"
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"alpha.com/internal/alpha.com/pkg/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrNotFound is matched, through errors.Is, by the error MongoRepository
+// returns when no document matches, an id that is not an ObjectID included.
+var ErrNotFound = errors.New("not found")
+
+// notFoundError names the entity for the client, and matches
+// mongo.ErrNoDocuments too so that checks made against the driver error
+// before MongoRepository existed keep working.
+type notFoundError struct {
+	entity string
+}
+
+func (e *notFoundError) Error() string {
+	return e.entity + " not found"
+}
+
+func (e *notFoundError) Is(target error) bool {
+	return target == ErrNotFound || target == mongo.ErrNoDocuments
+}
+
+// FindOptions of Find, zero values leave the driver defaults: no sort, whole
+// documents, no skip and no limit.
+type FindOptions struct {
+	Sort       bson.D
+	Projection bson.D
+	Skip       int64
+	Limit      int64
+}
+
+// MongoRepository is what the entity repositories have in common. Every call
+// gets a span named after the entity repository, e.g. userRepository.FindByID,
+// and the operation timeout. Entity repositories embed it and keep only their
+// own queries.
+type MongoRepository[T any] struct {
+	mongoClient    *mongo.Client
+	mongoConfig    configuration.MongoConfig
+	collectionName string
+	entity         string
+}
+
+// NewMongoRepository stores T in the collection named collectionName, entity
+// is its name in spans, logs and not found errors, e.g. "user".
+func NewMongoRepository[T any](mongoClient *mongo.Client, mongoConfig configuration.MongoConfig, collectionName string, entity string) *MongoRepository[T] {
+	return &MongoRepository[T]{
+		mongoClient:    mongoClient,
+		mongoConfig:    mongoConfig,
+		collectionName: collectionName,
+		entity:         entity,
+	}
+}
+
+func (r *MongoRepository[T]) FindByID(ctx context.Context, id string) (*T, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, r.notFound()
+	}
+
+	return r.findOne(ctx, "FindByID", bson.M{"_id": objectID})
+}
+
+// FindOne returns ErrNotFound when no document matches filter.
+func (r *MongoRepository[T]) FindOne(ctx context.Context, filter any) (*T, error) {
+	return r.findOne(ctx, "FindOne", filter)
+}
+
+// Find never returns a nil slice, no match is an empty one.
+func (r *MongoRepository[T]) Find(ctx context.Context, filter any, findOptions FindOptions) ([]*T, error) {
+	ctx, cancel, span := r.start(ctx, "Find")
+	defer span.End()
+	defer cancel()
+
+	driverOptions := options.Find()
+	if findOptions.Sort != nil {
+		driverOptions.SetSort(findOptions.Sort)
+	}
+	if findOptions.Projection != nil {
+		driverOptions.SetProjection(findOptions.Projection)
+	}
+	if findOptions.Skip > 0 {
+		driverOptions.SetSkip(findOptions.Skip)
+	}
+	if findOptions.Limit > 0 {
+		driverOptions.SetLimit(findOptions.Limit)
+	}
+
+	cursor, err := r.collection().Find(ctx, filter, driverOptions)
+	if err != nil {
+		return make([]*T, 0), r.failed(ctx, span, "Find", err)
+	}
+
+	documents := make([]*T, 0)
+	if err := cursor.All(ctx, &documents); err != nil {
+		return make([]*T, 0), r.failed(ctx, span, "Find", err)
+	}
+
+	return documents, nil
+}
+
+// Insert returns the id of the document, generated when it had none.
+func (r *MongoRepository[T]) Insert(ctx context.Context, document *T) (primitive.ObjectID, error) {
+	ctx, cancel, span := r.start(ctx, "Insert")
+	defer span.End()
+	defer cancel()
+
+	insertResult, err := r.collection().InsertOne(ctx, document)
+	if err != nil {
+		return primitive.NilObjectID, r.failed(ctx, span, "Insert", err)
+	}
+
+	objectID, _ := insertResult.InsertedID.(primitive.ObjectID)
+
+	logger.FromContext(ctx).Info(r.entity+"Repository.Insert "+r.entity+" saved", "id", objectID.Hex())
+
+	return objectID, nil
+}
+
+// Update applies update to the first document matching filter and returns
+// ErrNotFound when there is none. Filters that hold a condition, e.g. a token
+// that must not be used yet, rely on it to tell a lost race.
+func (r *MongoRepository[T]) Update(ctx context.Context, filter any, update any) error {
+	ctx, cancel, span := r.start(ctx, "Update")
+	defer span.End()
+	defer cancel()
+
+	result, err := r.collection().UpdateOne(ctx, filter, update)
+	if err != nil {
+		return r.failed(ctx, span, "Update", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return r.notFound()
+	}
+
+	return nil
+}
+
+func (r *MongoRepository[T]) UpdateByID(ctx context.Context, id string, update any) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return r.notFound()
+	}
+
+	return r.Update(ctx, bson.M{"_id": objectID}, update)
+}
+
+// UpdateMany returns the number of documents changed, none is not an error.
+func (r *MongoRepository[T]) UpdateMany(ctx context.Context, filter any, update any) (int64, error) {
+	ctx, cancel, span := r.start(ctx, "UpdateMany")
+	defer span.End()
+	defer cancel()
+
+	result, err := r.collection().UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, r.failed(ctx, span, "UpdateMany", err)
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// ReplaceByID writes the whole document with the given id, inserting it when
+// the id is unknown.
+func (r *MongoRepository[T]) ReplaceByID(ctx context.Context, id primitive.ObjectID, document *T) error {
+	ctx, cancel, span := r.start(ctx, "ReplaceByID")
+	defer span.End()
+	defer cancel()
+
+	if _, err := r.collection().ReplaceOne(ctx, bson.M{"_id": id}, document, options.Replace().SetUpsert(true)); err != nil {
+		return r.failed(ctx, span, "ReplaceByID", err)
+	}
+
+	return nil
+}
+
+// Delete removes the first document matching filter and returns ErrNotFound
+// when there is none.
+func (r *MongoRepository[T]) Delete(ctx context.Context, filter any) error {
+	ctx, cancel, span := r.start(ctx, "Delete")
+	defer span.End()
+	defer cancel()
+
+	result, err := r.collection().DeleteOne(ctx, filter)
+	if err != nil {
+		return r.failed(ctx, span, "Delete", err)
+	}
+
+	if result.DeletedCount == 0 {
+		return r.notFound()
+	}
+
+	logger.FromContext(ctx).Info(r.entity+"Repository.Delete "+r.entity+" deleted")
+
+	return nil
+}
+
+func (r *MongoRepository[T]) DeleteByID(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return r.notFound()
+	}
+
+	return r.Delete(ctx, bson.M{"_id": objectID})
+}
+
+func (r *MongoRepository[T]) Count(ctx context.Context, filter any) (int64, error) {
+	ctx, cancel, span := r.start(ctx, "Count")
+	defer span.End()
+	defer cancel()
+
+	count, err := r.collection().CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, r.failed(ctx, span, "Count", err)
+	}
+
+	return count, nil
+}
+
+// Exists stops at the first match, unlike Count.
+func (r *MongoRepository[T]) Exists(ctx context.Context, filter any) (bool, error) {
+	ctx, cancel, span := r.start(ctx, "Exists")
+	defer span.End()
+	defer cancel()
+
+	count, err := r.collection().CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	if err != nil {
+		return false, r.failed(ctx, span, "Exists", err)
+	}
+
+	return count > 0, nil
+}
+
+// FindOneAndUpdate applies update to the first document matching filter and
+// returns the document as it is after the update, ErrNotFound when none
+// matches. With upsert a document is inserted when none matches.
+func (r *MongoRepository[T]) FindOneAndUpdate(ctx context.Context, filter any, update any, upsert bool) (*T, error) {
+	ctx, cancel, span := r.start(ctx, "FindOneAndUpdate")
+	defer span.End()
+	defer cancel()
+
+	driverOptions := options.FindOneAndUpdate().SetUpsert(upsert).SetReturnDocument(options.After)
+
+	return r.decodeOne(ctx, span, "FindOneAndUpdate", r.collection().FindOneAndUpdate(ctx, filter, update, driverOptions))
+}
+
+// FindOneAndDelete removes the first document matching filter and returns it,
+// ErrNotFound when none matches.
+func (r *MongoRepository[T]) FindOneAndDelete(ctx context.Context, filter any) (*T, error) {
+	ctx, cancel, span := r.start(ctx, "FindOneAndDelete")
+	defer span.End()
+	defer cancel()
+
+	return r.decodeOne(ctx, span, "FindOneAndDelete", r.collection().FindOneAndDelete(ctx, filter))
+}
+
+func (r *MongoRepository[T]) findOne(ctx context.Context, method string, filter any) (*T, error) {
+	ctx, cancel, span := r.start(ctx, method)
+	defer span.End()
+	defer cancel()
+
+	return r.decodeOne(ctx, span, method, r.collection().FindOne(ctx, filter))
+}
+
+func (r *MongoRepository[T]) decodeOne(ctx context.Context, span *tracing.Span, method string, result *mongo.SingleResult) (*T, error) {
+	var document T
+	err := result.Decode(&document)
+
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		logger.FromContext(ctx).Debug(r.entity+"Repository."+method+" no document found")
+		return nil, r.notFound()
+	}
+
+	if err != nil {
+		return nil, r.failed(ctx, span, method, err)
+	}
+
+	return &document, nil
+}
+
+func (r *MongoRepository[T]) start(ctx context.Context, method string) (context.Context, context.CancelFunc, *tracing.Span) {
+	ctx, span := tracing.Start(ctx, r.entity+"Repository."+method)
+	ctx, cancel := withOperationTimeout(ctx, r.mongoConfig)
+
+	return ctx, cancel, span
+}
+
+func (r *MongoRepository[T]) failed(ctx context.Context, span *tracing.Span, method string, err error) error {
+	span.RecordError(err)
+	logger.FromContext(ctx).Error(r.entity+"Repository."+method+" failed", "error", err)
+
+	return err
+}
+
+func (r *MongoRepository[T]) notFound() error {
+	return &notFoundError{entity: r.entity}
+}
+
+func (r *MongoRepository[T]) collection() *mongo.Collection {
+	return r.mongoClient.Database(r.mongoConfig.Database).Collection(r.collectionName)
+}
+
+// applied turns the result of a conditional Update into whether it applied,
+// a filter that matches nothing is a refused condition rather than an error.
+func applied(err error) (bool, error) {
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"alpha.com/configuration"
+	"alpha.com/internal/alpha.com/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type IOidcStateRepository interface {
+	Upsert(ctx context.Context, state *domain.OidcState) error
+	Consume(ctx context.Context, stateHash string) (*domain.OidcState, error)
+}
+
+type oidcStateRepository struct {
+	*MongoRepository[domain.OidcState]
+}
+
+func NewOidcStateRepository(mongoClient *mongo.Client, mongoConfig configuration.MongoConfig) IOidcStateRepository {
+	return &oidcStateRepository{
+		MongoRepository: NewMongoRepository[domain.OidcState](mongoClient, mongoConfig, mongoConfig.Collections.OidcStates, "oidcState"),
+	}
+}
+
+func (r *oidcStateRepository) Upsert(ctx context.Context, state *domain.OidcState) error {
+	_, err := r.Insert(ctx, state)
+	return err
+}
+
+// Consume deletes and returns an unexpired state, so every state can complete
+// one callback only. It returns nil when there is no such state.
+func (r *oidcStateRepository) Consume(ctx context.Context, stateHash string) (*domain.OidcState, error) {
+	filter := bson.M{
+		"stateHash": stateHash,
+		"expiresAt": bson.M{"$gt": time.Now()},
+	}
+
+	state, err := r.FindOneAndDelete(ctx, filter)
+
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+
+	return state, err
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"alpha.com/configuration"
+	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"alpha.com/internal/alpha.com/pkg/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IRateLimitRepository interface {
+	Take(ctx context.Context, key string, capacity int, period time.Duration, now time.Time) (domain.RateLimitDecision, error)
+}
+
+type rateLimitRepository struct {
+	mongoClient *mongo.Client
+	mongoConfig configuration.MongoConfig
+}
+
+func NewRateLimitRepository(mongoClient *mongo.Client, mongoConfig configuration.MongoConfig) IRateLimitRepository {
+	return &rateLimitRepository{
+		mongoClient: mongoClient,
+		mongoConfig: mongoConfig,
+	}
+}
+
+// Take refills the bucket and takes a token in a single pipeline update, the
+// same arithmetic as domain.RateLimitBucket.Take, so concurrent requests on
+// different replicas cannot spend the same token.
+func (r *rateLimitRepository) Take(ctx context.Context, key string, capacity int, period time.Duration, now time.Time) (domain.RateLimitDecision, error) {
+	ctx, span := tracing.Start(ctx, "rateLimitRepository.Take")
+	defer span.End()
+
+	ctx, cancel := withOperationTimeout(ctx, r.mongoConfig)
+	defer cancel()
+
+	collection := r.mongoClient.Database(r.mongoConfig.Database).Collection(r.mongoConfig.Collections.RateLimits)
+
+	tokensPerMilli := float64(capacity) / float64(period.Milliseconds())
+	millisPerToken := float64(period.Milliseconds()) / float64(capacity)
+
+	refilled := bson.M{"$min": bson.A{
+		capacity,
+		bson.M{"$add": bson.A{
+			bson.M{"$ifNull": bson.A{"$tokens", capacity}},
+			bson.M{"$multiply": bson.A{
+				bson.M{"$max": bson.A{0, bson.M{"$subtract": bson.A{now, bson.M{"$ifNull": bson.A{"$updatedAt", now}}}}}},
+				tokensPerMilli,
+			}},
+		}},
+	}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"tokens": refilled}}},
+		{{Key: "$set", Value: bson.M{
+			"allowed":   bson.M{"$gte": bson.A{"$tokens", 1}},
+			"tokens":    bson.M{"$cond": bson.A{bson.M{"$gte": bson.A{"$tokens", 1}}, bson.M{"$subtract": bson.A{"$tokens", 1}}, "$tokens"}},
+			"updatedAt": now,
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"expiresAt": bson.M{"$add": bson.A{now, bson.M{"$ceil": bson.M{"$multiply": bson.A{bson.M{"$subtract": bson.A{capacity, "$tokens"}}, millisPerToken}}}}},
+		}}},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var bucket domain.RateLimitBucket
+	err := collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, pipeline, opts).Decode(&bucket)
+
+	if err != nil {
+		logger.FromContext(ctx).Error("rateLimitRepository.Take failed", "error", err)
+		return domain.RateLimitDecision{}, err
+	}
+
+	return bucket.Decision(capacity, period), nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"alpha.com/configuration"
+	"alpha.com/internal/alpha.com/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type IReportRepository interface {
+	GetByStatus(ctx context.Context, status domain.ReportStatus) ([]*domain.Report, error)
+	GetByID(ctx context.Context, reportId string) (*domain.Report, error)
+	Upsert(ctx context.Context, report *domain.Report) error
+	Resolve(ctx context.Context, reportId string, status domain.ReportStatus, resolvedBy primitive.ObjectID, resolution string) error
+}
+
+type reportRepository struct {
+	*MongoRepository[domain.Report]
+}
+
+func NewReportRepository(mongoClient *mongo.Client, mongoConfig configuration.MongoConfig) IReportRepository {
+	return &reportRepository{
+		MongoRepository: NewMongoRepository[domain.Report](mongoClient, mongoConfig, mongoConfig.Collections.Reports, "report"),
+	}
+}
+
+func (r *reportRepository) GetByStatus(ctx context.Context, status domain.ReportStatus) ([]*domain.Report, error) {
+	// oldest reports first so the queue is worked in order
+	return r.Find(ctx, bson.M{"status": status}, FindOptions{Sort: bson.D{{Key: "createdAt", Value: 1}}})
+}
+
+func (r *reportRepository) GetByID(ctx context.Context, reportId string) (*domain.Report, error) {
+	return r.FindByID(ctx, reportId)
+}
+
+func (r *reportRepository) Upsert(ctx context.Context, report *domain.Report) error {
+	_, err := r.Insert(ctx, report)
+	return err
+}
+
+func (r *reportRepository) Resolve(ctx context.Context, reportId string, status domain.ReportStatus, resolvedBy primitive.ObjectID, resolution string) error {
+	update := bson.M{
+		"$set": bson.M{
+			"status":     status,
+			"resolvedBy": resolvedBy,
+			"resolution": resolution,
+			"updatedAt":  time.Now(),
+		},
+	}
+
+	return r.UpdateByID(ctx, reportId, update)
+}
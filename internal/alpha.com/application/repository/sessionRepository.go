@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"alpha.com/configuration"
+	"alpha.com/internal/alpha.com/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type ISessionRepository interface {
+	GetByID(ctx context.Context, sessionId string) (*domain.Session, error)
+	GetActiveByUserID(ctx context.Context, userId string) ([]*domain.Session, error)
+	GetRevokedUnexpiredIDs(ctx context.Context) ([]string, error)
+	Upsert(ctx context.Context, session *domain.Session) error
+	Touch(ctx context.Context, sessionId string, ip string, expiresAt time.Time) error
+	Revoke(ctx context.Context, sessionId string) error
+	RevokeAllByUserID(ctx context.Context, userId string) ([]string, error)
+}
+
+type sessionRepository struct {
+	*MongoRepository[domain.Session]
+}
+
+func NewSessionRepository(mongoClient *mongo.Client, mongoConfig configuration.MongoConfig) ISessionRepository {
+	return &sessionRepository{
+		MongoRepository: NewMongoRepository[domain.Session](mongoClient, mongoConfig, mongoConfig.Collections.Sessions, "session"),
+	}
+}
+
+func (r *sessionRepository) GetByID(ctx context.Context, sessionId string) (*domain.Session, error) {
+	return r.FindByID(ctx, sessionId)
+}
+
+// GetActiveByUserID returns the sessions that are neither revoked nor expired,
+// the most recently used first.
+func (r *sessionRepository) GetActiveByUserID(ctx context.Context, userId string) ([]*domain.Session, error) {
+	objectID, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return make([]*domain.Session, 0), nil
+	}
+
+	filter := bson.M{
+		"userId":    objectID,
+		"revokedAt": bson.M{"$exists": false},
+		"expiresAt": bson.M{"$gt": time.Now()},
+	}
+
+	return r.Find(ctx, filter, FindOptions{Sort: bson.D{{Key: "lastUsedAt", Value: -1}}})
+}
+
+// GetRevokedUnexpiredIDs returns the sessions whose access tokens may still be
+// in circulation. Once a session has expired its tokens are rejected anyway.
+func (r *sessionRepository) GetRevokedUnexpiredIDs(ctx context.Context) ([]string, error) {
+	filter := bson.M{
+		"revokedAt": bson.M{"$exists": true},
+		"expiresAt": bson.M{"$gt": time.Now()},
+	}
+
+	sessions, err := r.Find(ctx, filter, FindOptions{Projection: bson.D{{Key: "_id", Value: 1}}})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		ids = append(ids, session.Id.Hex())
+	}
+
+	return ids, nil
+}
+
+func (r *sessionRepository) Upsert(ctx context.Context, session *domain.Session) error {
+	_, err := r.Insert(ctx, session)
+	return err
+}
+
+// Touch records a refresh of the session, a session that is gone is left as
+// it is.
+func (r *sessionRepository) Touch(ctx context.Context, sessionId string, ip string, expiresAt time.Time) error {
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"ip":         ip,
+			"lastUsedAt": now,
+			"expiresAt":  expiresAt,
+			"updatedAt":  now,
+		},
+	}
+
+	_, err := applied(r.UpdateByID(ctx, sessionId, update))
+	return err
+}
+
+// Revoke keeps the time of the first revocation, revoking a revoked session
+// does nothing.
+func (r *sessionRepository) Revoke(ctx context.Context, sessionId string) error {
+	objectID, err := primitive.ObjectIDFromHex(sessionId)
+	if err != nil {
+		return nil
+	}
+
+	now := time.Now()
+	filter := bson.M{"_id": objectID, "revokedAt": bson.M{"$exists": false}}
+	update := bson.M{
+		"$set": bson.M{
+			"revokedAt": now,
+			"updatedAt": now,
+		},
+	}
+
+	_, err = applied(r.Update(ctx, filter, update))
+	return err
+}
+
+func (r *sessionRepository) RevokeAllByUserID(ctx context.Context, userId string) ([]string, error) {
+	sessions, err := r.GetActiveByUserID(ctx, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sessionIDs := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		if err := r.Revoke(ctx, session.Id.Hex()); err != nil {
+			return sessionIDs, err
+		}
+
+		sessionIDs = append(sessionIDs, session.Id.Hex())
+	}
+
+	return sessionIDs, nil
+}
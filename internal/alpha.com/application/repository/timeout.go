@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+
+	"alpha.com/configuration"
+)
+
+// withOperationTimeout bounds one repository call, the iteration of its cursor
+// included. The deadline of the request, when it comes first, still applies.
+func withOperationTimeout(ctx context.Context, mongoConfig configuration.MongoConfig) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, mongoConfig.OperationTimeout.Duration())
+}
@@ -2,7 +2,9 @@ package repository
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"regexp"
+	"time"
 
 	"alpha.com/configuration"
 	"alpha.com/internal/alpha.com/domain"
@@ -16,110 +18,164 @@ type IUserRepository interface {
 	GetById(ctx context.Context, userId string) (*domain.User, error)
 	GetByEmail(ctx context.Context, email string) (*domain.User, error)
 	Upsert(ctx context.Context, user *domain.User) (string, error)
+	Search(ctx context.Context, search string, status string) ([]*domain.User, error)
+	UpdateStatus(ctx context.Context, userId string, status domain.UserStatus, reason string, until *time.Time) error
+	UpdateMfa(ctx context.Context, userId string, mfa *domain.UserMfa) error
+	UpdatePassword(ctx context.Context, userId string, passwordHash string) error
+	UpdateRoles(ctx context.Context, userId string, roles []domain.Role) error
+	AdvanceMfaStep(ctx context.Context, userId string, step int64) (bool, error)
+	ConsumeMfaRecoveryCode(ctx context.Context, userId string, codeHash string) (bool, error)
+	Replace(ctx context.Context, user *domain.User) error
 }
 
 type userRepository struct {
-	mongoClient *mongo.Client
+	*MongoRepository[domain.User]
 }
 
-func NewUserRepository(mongoClient *mongo.Client) IUserRepository {
+func NewUserRepository(mongoClient *mongo.Client, mongoConfig configuration.MongoConfig) IUserRepository {
 	return &userRepository{
-
-		mongoClient: mongoClient,
+		MongoRepository: NewMongoRepository[domain.User](mongoClient, mongoConfig, mongoConfig.Collections.Users, "user"),
 	}
 }
 
 func (r *userRepository) Get(ctx context.Context) ([]*domain.User, error) {
-	collection := r.mongoClient.Database(configuration.MONGO_DB_NAME).Collection(configuration.MONGO_USERS_DB_NAME)
+	return r.Find(ctx, bson.D{}, FindOptions{})
+}
 
-	var users []*domain.User
-	cursor, err := collection.Find(context.TODO(), bson.D{})
+func (r *userRepository) GetById(ctx context.Context, userId string) (*domain.User, error) {
+	return r.FindByID(ctx, userId)
+}
 
-	if err != nil {
-		fmt.Printf("userRepository.Get ERROR : %s\n", err.Error())
-		return make([]*domain.User, 0), err
-	}
+// GetByEmail returns nil and no error when no user has the email.
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	user, err := r.FindOne(ctx, bson.D{{Key: "email", Value: email}})
 
-	defer cursor.Close(context.TODO())
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
 
-	for cursor.Next(context.TODO()) {
-		var user *domain.User
-		err := cursor.Decode(&user)
-		if err != nil {
-			fmt.Printf("userRepository.Get ERROR : %s\n", err.Error())
-			return make([]*domain.User, 0), err
-		}
+	return user, err
+}
 
-		users = append(users, user)
+func (r *userRepository) Upsert(ctx context.Context, user *domain.User) (string, error) {
+	objectID, err := r.Insert(ctx, user)
+	if err != nil {
+		return "", err
 	}
 
-	if err := cursor.Err(); err != nil {
-		fmt.Printf("userRepository.Get ERROR : %s\n", err.Error())
+	return objectID.Hex(), nil
+}
+
+func (r *userRepository) Search(ctx context.Context, search string, status string) ([]*domain.User, error) {
+	filter := bson.M{}
+
+	if search != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(search), Options: "i"}
+		filter["$or"] = bson.A{
+			bson.M{"email": pattern},
+			bson.M{"firstName": pattern},
+			bson.M{"lastName": pattern},
+		}
 	}
 
-	if users == nil {
-		fmt.Printf("userRepository.Get INFO not found users on datasource\n")
-		return make([]*domain.User, 0), nil
+	if status == string(domain.UserStatusActive) {
+		// users saved before moderation existed have no status field
+		filter["status"] = bson.M{"$in": bson.A{status, nil}}
+	} else if status != "" {
+		filter["status"] = status
 	}
 
-	return users, nil
+	return r.Find(ctx, filter, FindOptions{})
 }
 
-func (r *userRepository) GetById(ctx context.Context, userId string) (*domain.User, error) {
-	collection := r.mongoClient.Database(configuration.MONGO_DB_NAME).Collection(configuration.MONGO_USERS_DB_NAME)
+func (r *userRepository) UpdateStatus(ctx context.Context, userId string, status domain.UserStatus, reason string, until *time.Time) error {
+	update := bson.M{
+		"$set": bson.M{
+			"status":       status,
+			"statusReason": reason,
+			"updatedAt":    time.Now(),
+		},
+	}
 
-	objectID, err := primitive.ObjectIDFromHex(userId)
-	if err != nil {
-		fmt.Printf("userRepository.GetById ERROR :  %s\n", err.Error())
+	if until != nil {
+		update["$set"].(bson.M)["suspendedUntil"] = until
+	} else {
+		update["$unset"] = bson.M{"suspendedUntil": ""}
 	}
 
-	var user *domain.User
-	err = collection.FindOne(context.TODO(), bson.D{{Key: "_id", Value: objectID}}).Decode(&user)
+	return r.UpdateByID(ctx, userId, update)
+}
 
-	if err != nil {
-		fmt.Printf("userRepository.GetById ERROR :  %s\n", err.Error())
-		return nil, err
+// UpdateMfa replaces the MFA enrollment of the user, a nil mfa removes it.
+func (r *userRepository) UpdateMfa(ctx context.Context, userId string, mfa *domain.UserMfa) error {
+	update := bson.M{
+		"$set": bson.M{"updatedAt": time.Now()},
 	}
 
-	fmt.Printf("userRepository.GetById INFO found user by given id: %s\n", userId)
-
-	return user, nil
+	if mfa != nil {
+		update["$set"].(bson.M)["mfa"] = mfa
+	} else {
+		update["$unset"] = bson.M{"mfa": ""}
+	}
 
+	return r.UpdateByID(ctx, userId, update)
 }
 
-func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
-	collection := r.mongoClient.Database(configuration.MONGO_DB_NAME).Collection(configuration.MONGO_USERS_DB_NAME)
+func (r *userRepository) UpdatePassword(ctx context.Context, userId string, passwordHash string) error {
+	update := bson.M{
+		"$set": bson.M{
+			"password":  passwordHash,
+			"updatedAt": time.Now(),
+		},
+	}
 
-	var user *domain.User
-	err := collection.FindOne(context.TODO(), bson.D{{Key: "email", Value: email}}).Decode(&user)
+	return r.UpdateByID(ctx, userId, update)
+}
 
+// AdvanceMfaStep records the time step of an accepted TOTP code. It reports
+// false when the step, or a later one, was already used so a code cannot be
+// replayed within its validity window.
+func (r *userRepository) AdvanceMfaStep(ctx context.Context, userId string, step int64) (bool, error) {
+	objectID, err := primitive.ObjectIDFromHex(userId)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			fmt.Println("userRepository.GetByEmail INFO : No documents found with the given email.")
-			return nil, nil
-		}
-
-		fmt.Printf("userRepository.GetByEmail ERROR :  %s\n", err.Error())
-		return nil, err
+		return false, nil
 	}
 
-	fmt.Printf("userRepository.GetByEmail INFO Not found user by given email: %s\n", email)
+	filter := bson.M{"_id": objectID, "mfa.lastUsedStep": bson.M{"$lt": step}}
+	update := bson.M{"$set": bson.M{"mfa.lastUsedStep": step}}
 
-	return user, nil
+	return applied(r.Update(ctx, filter, update))
 }
 
-func (r *userRepository) Upsert(ctx context.Context, user *domain.User) (string, error) {
-	collection := r.mongoClient.Database(configuration.MONGO_DB_NAME).Collection(configuration.MONGO_USERS_DB_NAME)
-
-	insertResult, err := collection.InsertOne(context.TODO(), user)
-
+// ConsumeMfaRecoveryCode removes the recovery code and reports whether it was
+// still there, which makes each code single-use.
+func (r *userRepository) ConsumeMfaRecoveryCode(ctx context.Context, userId string, codeHash string) (bool, error) {
+	objectID, err := primitive.ObjectIDFromHex(userId)
 	if err != nil {
-		return "", err
+		return false, nil
 	}
 
-	objectID := insertResult.InsertedID.(primitive.ObjectID)
+	filter := bson.M{"_id": objectID, "mfa.recoveryCodeHashes": codeHash}
+	update := bson.M{
+		"$pull": bson.M{"mfa.recoveryCodeHashes": codeHash},
+		"$set":  bson.M{"updatedAt": time.Now()},
+	}
 
-	fmt.Printf("userRepository.Upsert INFO user saved with id: %s\n", objectID.Hex())
+	return applied(r.Update(ctx, filter, update))
+}
 
-	return objectID.Hex(), nil
+func (r *userRepository) UpdateRoles(ctx context.Context, userId string, roles []domain.Role) error {
+	update := bson.M{
+		"$set": bson.M{
+			"roles":     roles,
+			"updatedAt": time.Now(),
+		},
+	}
+
+	return r.UpdateByID(ctx, userId, update)
+}
+
+// Replace writes the whole document, inserting it when its id is unknown.
+func (r *userRepository) Replace(ctx context.Context, user *domain.User) error {
+	return r.ReplaceByID(ctx, user.Id, user)
 }
@@ -1,44 +1,97 @@
 package web
 
 import (
-	"fmt"
-	"net/http"
-
 	"alpha.com/internal/alpha.com/application/controller"
+	"alpha.com/internal/alpha.com/domain"
 	"alpha.com/internal/alpha.com/pkg/server/middlewares"
 	"github.com/gofiber/fiber/v2"
 )
 
 func InitRouter(app *fiber.App,
+	jwtMiddleware fiber.Handler,
+	authMiddleware fiber.Handler,
+	rateLimiter middlewares.IRateLimiter,
 	userController controller.IUserController,
 	jwtController controller.IJwtController,
 	businessAccountController controller.IBusinessAccountController,
 	jobController controller.IJobController,
 	jobApplyController controller.IJobApplyController,
+	reportController controller.IReportController,
+	adminController controller.IAdminController,
+	sessionController controller.ISessionController,
+	jwksController controller.IJwksController,
+	mfaController controller.IMfaController,
+	magicLinkController controller.IMagicLinkController,
+	externalIdentityController controller.IExternalIdentityController,
+	apiKeyController controller.IApiKeyController,
+	healthController controller.IHealthController,
 ) {
 
-	app.Get("/healthcheck", func(context *fiber.Ctx) error {
-		fmt.Printf("Request sent to '/healthcheck' route -> Status: %v\n", http.StatusOK)
-		return context.SendStatus(http.StatusOK)
-	})
+	app.Get("/livez", healthController.Livez)
+	app.Get("/readyz", healthController.Readyz)
+	// kept for the probes configured before /livez existed
+	app.Get("/healthcheck", healthController.Livez)
+
+	app.Get("/.well-known/jwks.json", jwksController.GetJwks)
 
 	alphaRouteGroup := app.Group("/api/v1/alpha")
 
-	alphaRouteGroup.Get("/user", userController.GetUser)
-	alphaRouteGroup.Post("/user", middlewares.IsEmailFormatCorrect, userController.Save)
-	alphaRouteGroup.Post("/user/sign-in", middlewares.IsEmailFormatCorrect, userController.SignIn)
-	alphaRouteGroup.Get("/user/:userId", middlewares.JwtMiddleware, userController.GetUserById)
+	alphaRouteGroup.Get("/user", jwtMiddleware, middlewares.RequireRole(domain.RoleAdmin), userController.GetUser)
+	alphaRouteGroup.Post("/user", rateLimiter.Limit("sign-up"), middlewares.IsEmailFormatCorrect, userController.Save)
+	alphaRouteGroup.Post("/user/sign-in", rateLimiter.Limit("sign-in"), middlewares.IsEmailFormatCorrect, userController.SignIn)
+	alphaRouteGroup.Get("/user/unlock", userController.Unlock)
+	alphaRouteGroup.Get("/user/:userId", jwtMiddleware, userController.GetUserById)
 
-	alphaRouteGroup.Post("/jwt", jwtController.Create)
 	alphaRouteGroup.Post("/jwt/refresh", jwtController.Refresh)
-	alphaRouteGroup.Get("/jwt", jwtController.GetJwt)
+	alphaRouteGroup.Get("/jwt", jwtMiddleware, middlewares.RequireRole(domain.RoleAdmin), jwtController.GetJwt)
+
+	alphaRouteGroup.Post("/auth/logout", jwtMiddleware, sessionController.Logout)
+	alphaRouteGroup.Post("/auth/magic-link", rateLimiter.Limit("sign-in"), magicLinkController.Request)
+	alphaRouteGroup.Get("/auth/magic-link/consume", magicLinkController.Consume)
+	alphaRouteGroup.Get("/auth/oidc/:provider/login", externalIdentityController.Login)
+	alphaRouteGroup.Get("/auth/oidc/:provider/callback", externalIdentityController.Callback)
+	alphaRouteGroup.Post("/auth/oidc/:provider/link", jwtMiddleware, externalIdentityController.Link)
+	alphaRouteGroup.Delete("/auth/oidc/:provider/link", jwtMiddleware, externalIdentityController.Unlink)
+	alphaRouteGroup.Post("/auth/mfa/enroll", jwtMiddleware, mfaController.Enroll)
+	alphaRouteGroup.Post("/auth/mfa/confirm", jwtMiddleware, mfaController.Confirm)
+	alphaRouteGroup.Post("/auth/mfa/disable", jwtMiddleware, mfaController.Disable)
+	alphaRouteGroup.Post("/auth/mfa/recovery-codes", jwtMiddleware, mfaController.RegenerateRecoveryCodes)
+	alphaRouteGroup.Post("/auth/mfa/verify", rateLimiter.Limit("sign-in"), mfaController.Verify)
+	alphaRouteGroup.Get("/me/sessions", jwtMiddleware, sessionController.GetSessions)
+	alphaRouteGroup.Delete("/me/sessions", jwtMiddleware, sessionController.RevokeAllSessions)
+	alphaRouteGroup.Delete("/me/sessions/:sessionId", jwtMiddleware, sessionController.RevokeSession)
+	alphaRouteGroup.Get("/me/identities", jwtMiddleware, externalIdentityController.GetIdentities)
+
+	alphaRouteGroup.Post("/business-account", jwtMiddleware, businessAccountController.Save)
+	alphaRouteGroup.Get("/business-account", rateLimiter.Limit("search"), businessAccountController.GetAllBusinessAccounts)
+	alphaRouteGroup.Post("/business-account/:businessAccountId/api-keys", jwtMiddleware, apiKeyController.Save)
+	alphaRouteGroup.Get("/business-account/:businessAccountId/api-keys", jwtMiddleware, apiKeyController.GetApiKeys)
+	alphaRouteGroup.Delete("/business-account/:businessAccountId/api-keys/:apiKeyId", jwtMiddleware, apiKeyController.Revoke)
+	alphaRouteGroup.Get("/business-account/:businessAccountId/jobs/:jobId/applications", authMiddleware, rateLimiter.Limit("api"), middlewares.RequireScope(domain.PermissionApplicationsRead), middlewares.RequirePermission(domain.PermissionApplicationsRead), jobApplyController.GetJobApplications)
 
-	alphaRouteGroup.Post("/business-account", middlewares.JwtMiddleware, businessAccountController.Save)
-	alphaRouteGroup.Get("/business-account", businessAccountController.GetAllBusinessAccounts)
+	alphaRouteGroup.Post("/job", authMiddleware, rateLimiter.Limit("api"), middlewares.RequireScope(domain.PermissionJobsWrite), middlewares.RequirePermission(domain.PermissionJobsWrite), jobController.Save)
+	alphaRouteGroup.Get("/job", rateLimiter.Limit("search"), jobController.GetAllJobs)
 
-	alphaRouteGroup.Post("/job", middlewares.JwtMiddleware, jobController.Save)
-	alphaRouteGroup.Get("/job", jobController.GetAllJobs)
+	alphaRouteGroup.Post("/job-apply", jwtMiddleware, rateLimiter.Limit("apply"), jobApplyController.Save)
+	alphaRouteGroup.Get("/job-apply", jwtMiddleware, middlewares.RequirePermission(domain.PermissionApplicationsReadAll), jobApplyController.GetAllJobApplies)
+
+	alphaRouteGroup.Post("/report", jwtMiddleware, reportController.Save)
+
+	adminRouteGroup := alphaRouteGroup.Group("/admin", jwtMiddleware, middlewares.RequireRole(domain.RoleAdmin))
+
+	adminRouteGroup.Get("/users", adminController.SearchUsers)
+	adminRouteGroup.Post("/users/:userId/suspend", adminController.SuspendUser)
+	adminRouteGroup.Post("/users/:userId/ban", adminController.BanUser)
+	adminRouteGroup.Post("/users/:userId/unban", adminController.UnbanUser)
+	adminRouteGroup.Post("/jobs/:jobId/unpublish", adminController.UnpublishJob)
+	adminRouteGroup.Delete("/jobs/:jobId", adminController.DeleteJob)
+	adminRouteGroup.Get("/moderation-queue", adminController.GetModerationQueue)
+	adminRouteGroup.Post("/reports/:reportId/resolve", adminController.ResolveReport)
+	adminRouteGroup.Get("/actions", adminController.GetAdminActions)
+}
 
-	alphaRouteGroup.Post("/job-apply", middlewares.JwtMiddleware, jobApplyController.Save)
-	alphaRouteGroup.Get("/job-apply", jobApplyController.GetAllJobApplies)
+// InitMetricsRouter sets up the app served on the metrics port, which is only
+// reachable from inside the cluster.
+func InitMetricsRouter(app *fiber.App, metricsController controller.IMetricsController) {
+	app.Get("/metrics", metricsController.GetMetrics)
 }
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AdminActionType string
+
+const (
+	AdminActionSuspendUser   AdminActionType = "suspendUser"
+	AdminActionBanUser       AdminActionType = "banUser"
+	AdminActionUnbanUser     AdminActionType = "unbanUser"
+	AdminActionUnpublishJob  AdminActionType = "unpublishJob"
+	AdminActionDeleteJob     AdminActionType = "deleteJob"
+	AdminActionResolveReport AdminActionType = "resolveReport"
+)
+
+// AdminAction is an append-only audit record of a moderation action.
+type AdminAction struct {
+	Id         primitive.ObjectID `bson:"_id,omitempty"`
+	AdminID    primitive.ObjectID `bson:"adminId" validate:"required"`
+	Action     AdminActionType    `bson:"action" validate:"required"`
+	TargetType string             `bson:"targetType" validate:"required"`
+	TargetID   primitive.ObjectID `bson:"targetId" validate:"required"`
+	Reason     string             `bson:"reason"`
+	CreatedAt  time.Time          `bson:"createdAt"`
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ApiKeyScopes are the permissions an API key can be granted.
+var ApiKeyScopes = []Permission{PermissionJobsWrite, PermissionApplicationsRead}
+
+// ApiKey lets an integration act for a business account. The key is shown
+// once as "<prefix>.<secret>"; only the prefix, used for lookup, and a hash of
+// the secret are stored.
+type ApiKey struct {
+	Id                primitive.ObjectID `bson:"_id,omitempty"`
+	BusinessAccountID primitive.ObjectID `bson:"businessAccountId" validate:"required"`
+	CreatedBy         primitive.ObjectID `bson:"createdBy" validate:"required"`
+	Name              string             `bson:"name" validate:"required"`
+	Prefix            string             `bson:"prefix"`
+	SecretHash        string             `bson:"secretHash"`
+	Scopes            []Permission       `bson:"scopes"`
+	ExpiresAt         *time.Time         `bson:"expiresAt,omitempty"`
+	LastUsedAt        *time.Time         `bson:"lastUsedAt,omitempty"`
+	RevokedAt         *time.Time         `bson:"revokedAt,omitempty"`
+	CreatedAt         time.Time          `bson:"createdAt"`
+	UpdatedAt         time.Time          `bson:"updatedAt"`
+}
+
+func (k *ApiKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+func (k *ApiKey) HasScope(scope Permission) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
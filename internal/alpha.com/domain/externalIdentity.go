@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ExternalIdentity links an account at an OpenID Connect provider, identified
+// by the provider and its subject, to a user.
+type ExternalIdentity struct {
+	Id         primitive.ObjectID `bson:"_id,omitempty"`
+	UserID     primitive.ObjectID `bson:"userId" validate:"required"`
+	Provider   string             `bson:"provider" validate:"required"`
+	Subject    string             `bson:"subject" validate:"required"`
+	Email      string             `bson:"email"`
+	LinkedAt   time.Time          `bson:"linkedAt"`
+	LastUsedAt time.Time          `bson:"lastUsedAt"`
+}
+
+type OidcStatePurpose string
+
+const (
+	OidcStatePurposeLogin OidcStatePurpose = "login"
+	OidcStatePurposeLink  OidcStatePurpose = "link"
+)
+
+// OidcState is kept between the redirect to the provider and the callback.
+// It is looked up by the hash of the state parameter and deleted when used.
+type OidcState struct {
+	Id           primitive.ObjectID `bson:"_id,omitempty"`
+	StateHash    string             `bson:"stateHash"`
+	Provider     string             `bson:"provider"`
+	Purpose      OidcStatePurpose   `bson:"purpose"`
+	UserID       string             `bson:"userId,omitempty"`
+	Nonce        string             `bson:"nonce"`
+	CodeVerifier string             `bson:"codeVerifier" sensitive:"true"`
+	ExpiresAt    time.Time          `bson:"expiresAt"`
+	CreatedAt    time.Time          `bson:"createdAt"`
+}
@@ -6,6 +6,13 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+type JobStatus string
+
+const (
+	JobStatusPublished   JobStatus = "published"
+	JobStatusUnpublished JobStatus = "unpublished"
+)
+
 type Job struct {
 	Id                primitive.ObjectID `bson:"_id,omitempty"`
 	BusinessAccountID primitive.ObjectID `bson:"businessAccountId" validate:"required"`
@@ -13,6 +20,13 @@ type Job struct {
 	Description       string             `bson:"description" validate:"required"`
 	Price             float32            `bson:"price" validate:"required"`
 	Category          string             `bson:"category" validate:"required"`
+	Status            JobStatus          `bson:"status,omitempty"`
+	ModerationReason  string             `bson:"moderationReason,omitempty"`
 	CreatedAt         time.Time          `bson:"createdAt"`
 	UpdatedAt         time.Time          `bson:"updatedAt"`
 }
+
+// IsPublished treats jobs saved before moderation existed as published.
+func (j *Job) IsPublished() bool {
+	return j.Status != JobStatusUnpublished
+}
@@ -6,11 +6,16 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Jwt is one issued refresh token. Tokens rotated from the same sign-in share
+// a FamilyID so that the whole chain can be revoked when reuse is detected.
 type Jwt struct {
-	Id           primitive.ObjectID `bson:"_id,omitempty"`
-	UserID       primitive.ObjectID `bson:"userId" validate:"required"`
-	AccessToken  string             `bson:"accessToken" validate:"required"`
-	RefreshToken string             `bson:"refreshToken" validate:"required"`
-	CreatedAt    time.Time          `bson:"createdAt"`
-	UpdatedAt    time.Time          `bson:"updatedAt"`
+	Id               primitive.ObjectID `bson:"_id,omitempty"`
+	UserID           primitive.ObjectID `bson:"userId" validate:"required"`
+	FamilyID         string             `bson:"familyId" validate:"required"`
+	RefreshTokenHash string             `bson:"refreshTokenHash" validate:"required"`
+	RotatedAt        *time.Time         `bson:"rotatedAt,omitempty"`
+	RevokedAt        *time.Time         `bson:"revokedAt,omitempty"`
+	ExpiresAt        time.Time          `bson:"expiresAt"`
+	CreatedAt        time.Time          `bson:"createdAt"`
+	UpdatedAt        time.Time          `bson:"updatedAt"`
 }
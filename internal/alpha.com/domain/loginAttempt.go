@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LoginAttempt counts the failed sign-ins of one account or one IP address.
+// Documents are removed by a TTL index once ExpiresAt has passed.
+type LoginAttempt struct {
+	Id              primitive.ObjectID `bson:"_id,omitempty"`
+	Key             string             `bson:"key"`
+	Failures        int                `bson:"failures"`
+	LastFailedAt    time.Time          `bson:"lastFailedAt"`
+	LockedUntil     *time.Time         `bson:"lockedUntil,omitempty"`
+	UnlockTokenHash string             `bson:"unlockTokenHash,omitempty"`
+	ExpiresAt       time.Time          `bson:"expiresAt"`
+	CreatedAt       time.Time          `bson:"createdAt"`
+	UpdatedAt       time.Time          `bson:"updatedAt"`
+}
+
+// LoginAttemptAccountKey is keyed by the submitted email rather than the user
+// id, so unknown emails are throttled exactly like existing ones.
+func LoginAttemptAccountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func LoginAttemptIPKey(ip string) string {
+	return "ip:" + ip
+}
+
+// LoginAttemptMfaKey counts invalid second factor codes of a user.
+func LoginAttemptMfaKey(userID string) string {
+	return "mfa:" + userID
+}
+
+func (a *LoginAttempt) IsLocked(now time.Time) bool {
+	return a.LockedUntil != nil && a.LockedUntil.After(now)
+}
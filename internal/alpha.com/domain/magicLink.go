@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MagicLink is a single-use, passwordless sign-in link. It only stores hashes
+// of the link token and of the device cookie set when the link was requested,
+// so the link only works in the browser that asked for it.
+type MagicLink struct {
+	Id            primitive.ObjectID `bson:"_id,omitempty"`
+	Email         string             `bson:"email"`
+	TokenHash     string             `bson:"tokenHash"`
+	DeviceHash    string             `bson:"deviceHash"`
+	IP            string             `bson:"ip"`
+	CreateAccount bool               `bson:"createAccount"`
+	ConsumedAt    *time.Time         `bson:"consumedAt,omitempty"`
+	ExpiresAt     time.Time          `bson:"expiresAt"`
+	CreatedAt     time.Time          `bson:"createdAt"`
+}
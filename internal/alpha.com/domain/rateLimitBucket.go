@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// RateLimitBucket is the token bucket of one client under one policy. Tokens
+// are refilled continuously, Capacity tokens per Period, up to Capacity.
+// Documents are removed by a TTL index once the bucket would be full again.
+type RateLimitBucket struct {
+	Key       string    `bson:"_id"`
+	Tokens    float64   `bson:"tokens"`
+	Allowed   bool      `bson:"allowed"`
+	UpdatedAt time.Time `bson:"updatedAt"`
+	ExpiresAt time.Time `bson:"expiresAt"`
+}
+
+// RateLimitDecision is the outcome of taking a token and what the RateLimit-*
+// headers report.
+type RateLimitDecision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Refill adds the tokens earned since UpdatedAt. A new bucket starts full.
+func (b *RateLimitBucket) Refill(capacity int, period time.Duration, now time.Time) {
+	if b.UpdatedAt.IsZero() {
+		b.Tokens = float64(capacity)
+	} else if elapsed := now.Sub(b.UpdatedAt); elapsed > 0 {
+		b.Tokens = math.Min(float64(capacity), b.Tokens+elapsed.Seconds()*float64(capacity)/period.Seconds())
+	}
+
+	b.UpdatedAt = now
+}
+
+// Take refills the bucket and removes one token when there is one.
+func (b *RateLimitBucket) Take(capacity int, period time.Duration, now time.Time) RateLimitDecision {
+	b.Refill(capacity, period, now)
+
+	b.Allowed = b.Tokens >= 1
+	if b.Allowed {
+		b.Tokens--
+	}
+
+	b.ExpiresAt = now.Add(b.Decision(capacity, period).Reset)
+
+	return b.Decision(capacity, period)
+}
+
+// Decision describes the bucket as it is, after Take.
+func (b *RateLimitBucket) Decision(capacity int, period time.Duration) RateLimitDecision {
+	perToken := period.Seconds() / float64(capacity)
+
+	decision := RateLimitDecision{
+		Allowed:   b.Allowed,
+		Limit:     capacity,
+		Remaining: int(math.Floor(b.Tokens)),
+		Reset:     secondsToDuration((float64(capacity) - b.Tokens) * perToken),
+	}
+
+	if !b.Allowed {
+		decision.RetryAfter = secondsToDuration((1 - b.Tokens) * perToken)
+	}
+
+	return decision
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ReportTargetType string
+
+const (
+	ReportTargetJob  ReportTargetType = "job"
+	ReportTargetUser ReportTargetType = "user"
+)
+
+type ReportStatus string
+
+const (
+	ReportStatusOpen      ReportStatus = "open"
+	ReportStatusResolved  ReportStatus = "resolved"
+	ReportStatusDismissed ReportStatus = "dismissed"
+)
+
+type Report struct {
+	Id         primitive.ObjectID  `bson:"_id,omitempty"`
+	ReporterID primitive.ObjectID  `bson:"reporterId" validate:"required"`
+	TargetType ReportTargetType    `bson:"targetType" validate:"required"`
+	TargetID   primitive.ObjectID  `bson:"targetId" validate:"required"`
+	Reason     string              `bson:"reason" validate:"required"`
+	Status     ReportStatus        `bson:"status"`
+	ResolvedBy *primitive.ObjectID `bson:"resolvedBy,omitempty"`
+	Resolution string              `bson:"resolution,omitempty"`
+	CreatedAt  time.Time           `bson:"createdAt"`
+	UpdatedAt  time.Time           `bson:"updatedAt"`
+}
@@ -0,0 +1,75 @@
+package domain
+
+type Role string
+
+const (
+	RoleAdmin     Role = "admin"
+	RoleEmployer  Role = "employer"
+	RoleCandidate Role = "candidate"
+)
+
+type Permission string
+
+const (
+	PermissionUsersRead             Permission = "users:read"
+	PermissionTokensRead            Permission = "tokens:read"
+	PermissionBusinessAccountsWrite Permission = "businessAccounts:write"
+	PermissionJobsWrite             Permission = "jobs:write"
+	PermissionApplicationsRead      Permission = "applications:read"
+	PermissionApplicationsWrite     Permission = "applications:write"
+	// PermissionApplicationsReadAll reads the applications to the jobs of
+	// every business account, not only of the accounts a user owns.
+	PermissionApplicationsReadAll Permission = "applications:readAll"
+)
+
+var RolePermissions = map[Role][]Permission{
+	RoleAdmin: {
+		PermissionUsersRead,
+		PermissionTokensRead,
+		PermissionBusinessAccountsWrite,
+		PermissionJobsWrite,
+		PermissionApplicationsRead,
+		PermissionApplicationsWrite,
+		PermissionApplicationsReadAll,
+	},
+	RoleEmployer: {
+		PermissionBusinessAccountsWrite,
+		PermissionJobsWrite,
+		PermissionApplicationsRead,
+	},
+	RoleCandidate: {
+		PermissionApplicationsWrite,
+	},
+}
+
+func HasPermission(roles []Role, permission Permission) bool {
+	for _, role := range roles {
+		for _, p := range RolePermissions[role] {
+			if p == permission {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func RolesToStrings(roles []Role) []string {
+	result := make([]string, 0, len(roles))
+
+	for _, role := range roles {
+		result = append(result, string(role))
+	}
+
+	return result
+}
+
+func RolesFromStrings(values []string) []Role {
+	result := make([]Role, 0, len(values))
+
+	for _, value := range values {
+		result = append(result, Role(value))
+	}
+
+	return result
+}
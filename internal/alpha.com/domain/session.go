@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Session is one signed-in device. Its hex id is the family id of the refresh
+// tokens issued for it and the sid claim of its access tokens.
+type Session struct {
+	Id         primitive.ObjectID `bson:"_id,omitempty"`
+	UserID     primitive.ObjectID `bson:"userId" validate:"required"`
+	Device     string             `bson:"device"`
+	IP         string             `bson:"ip"`
+	UserAgent  string             `bson:"userAgent"`
+	LastUsedAt time.Time          `bson:"lastUsedAt"`
+	ExpiresAt  time.Time          `bson:"expiresAt"`
+	RevokedAt  *time.Time         `bson:"revokedAt,omitempty"`
+	CreatedAt  time.Time          `bson:"createdAt"`
+	UpdatedAt  time.Time          `bson:"updatedAt"`
+}
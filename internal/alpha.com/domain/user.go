@@ -6,13 +6,53 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+type UserStatus string
+
+const (
+	UserStatusActive    UserStatus = "active"
+	UserStatusSuspended UserStatus = "suspended"
+	UserStatusBanned    UserStatus = "banned"
+)
+
 type User struct {
-	Id        primitive.ObjectID `bson:"_id,omitempty"`
-	FirstName string             `bson:"firstName" validate:"required"`
-	LastName  string             `bson:"lastName" validate:"required"`
-	Email     string             `bson:"email" validate:"required,email"`
-	Password  string             `bson:"password" validate:"required,min=6"`
-	Age       int32              `bson:"age" validate:"gte=0,lte=130"`
-	CreatedAt time.Time          `bson:"createdAt"`
-	UpdatedAt time.Time          `bson:"updatedAt"`
+	Id             primitive.ObjectID `bson:"_id,omitempty"`
+	FirstName      string             `bson:"firstName" validate:"required"`
+	LastName       string             `bson:"lastName" validate:"required"`
+	Email          string             `bson:"email" validate:"required,email"`
+	Password       string             `bson:"password" validate:"required,min=6" sensitive:"true"`
+	Age            int32              `bson:"age" validate:"gte=0,lte=130"`
+	Roles          []Role             `bson:"roles"`
+	Status         UserStatus         `bson:"status,omitempty"`
+	StatusReason   string             `bson:"statusReason,omitempty"`
+	SuspendedUntil *time.Time         `bson:"suspendedUntil,omitempty"`
+	Mfa            *UserMfa           `bson:"mfa,omitempty"`
+	CreatedAt      time.Time          `bson:"createdAt"`
+	UpdatedAt      time.Time          `bson:"updatedAt"`
+}
+
+func (u *User) HasRole(role Role) bool {
+	for _, r := range u.Roles {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (u *User) MfaEnabled() bool {
+	return u.Mfa != nil && u.Mfa.Enabled
+}
+
+// IsBlocked reports whether the user is banned or currently suspended.
+// A suspension without an end date lasts until an admin lifts it.
+func (u *User) IsBlocked(now time.Time) bool {
+	switch u.Status {
+	case UserStatusBanned:
+		return true
+	case UserStatusSuspended:
+		return u.SuspendedUntil == nil || now.Before(*u.SuspendedUntil)
+	default:
+		return false
+	}
 }
@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// UserMfa is the TOTP enrollment of a user. Secrets are stored encrypted and
+// recovery codes only as hashes. PendingSecret holds a secret that was issued
+// but not yet confirmed with a first code.
+type UserMfa struct {
+	Enabled            bool       `bson:"enabled"`
+	Secret             string     `bson:"secret,omitempty" sensitive:"true"`
+	PendingSecret      string     `bson:"pendingSecret,omitempty" sensitive:"true"`
+	RecoveryCodeHashes []string   `bson:"recoveryCodeHashes,omitempty" sensitive:"true"`
+	LastUsedStep       int64      `bson:"lastUsedStep"`
+	EnabledAt          *time.Time `bson:"enabledAt,omitempty"`
+}
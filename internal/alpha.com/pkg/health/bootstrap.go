@@ -0,0 +1,83 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+var errBootstrapRunning = errors.New("not finished yet")
+
+type bootstrapStep struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// Bootstrap is the work that must be done once before the service is ready,
+// like creating indexes. It runs in the background so that the server answers
+// /livez meanwhile, and reports not ready until every step succeeded.
+type Bootstrap struct {
+	name  string
+	steps []bootstrapStep
+
+	mu  sync.Mutex
+	err error
+}
+
+func NewBootstrap(name string) *Bootstrap {
+	return &Bootstrap{name: name, err: errBootstrapRunning}
+}
+
+// Add registers a step, steps must all be added before Run.
+func (b *Bootstrap) Add(name string, run func(ctx context.Context) error) {
+	b.steps = append(b.steps, bootstrapStep{name: name, run: run})
+}
+
+// Run executes the steps in order, retrying the failed one every
+// retryInterval until it succeeds or ctx is done.
+func (b *Bootstrap) Run(ctx context.Context, retryInterval time.Duration) {
+	for _, step := range b.steps {
+		for {
+			err := step.run(ctx)
+			if err == nil {
+				break
+			}
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			slog.Error("Bootstrap step failed, retrying", "bootstrap", b.name, "step", step.name, "error", err, "retry_in", retryInterval)
+			b.setErr(errors.New(step.name + ": " + err.Error()))
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryInterval):
+			}
+		}
+	}
+
+	b.setErr(nil)
+
+	slog.Info("Bootstrap finished", "bootstrap", b.name, "steps", len(b.steps))
+}
+
+func (b *Bootstrap) setErr(err error) {
+	b.mu.Lock()
+	b.err = err
+	b.mu.Unlock()
+}
+
+func (b *Bootstrap) Name() string {
+	return b.name
+}
+
+func (b *Bootstrap) Check(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.err
+}
@@ -0,0 +1,105 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HealthChecker is one dependency the service needs to answer requests.
+// Check returns nil when it is usable, it must give up when ctx is done.
+type HealthChecker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+const (
+	StatusOk   = "ok"
+	StatusFail = "fail"
+)
+
+var errShuttingDown = errors.New("shutting down")
+
+// Report is the body of /readyz, one entry per check.
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckReport `json:"checks"`
+}
+
+type CheckReport struct {
+	Status     string `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+func (r Report) Ok() bool {
+	return r.Status == StatusOk
+}
+
+// Readiness runs every checker concurrently, each within timeout, and fails
+// as soon as shutdown has begun so that load balancers stop sending requests
+// before the server stops accepting them.
+type Readiness struct {
+	checkers     []HealthChecker
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+func NewReadiness(timeout time.Duration, checkers ...HealthChecker) *Readiness {
+	return &Readiness{
+		checkers: checkers,
+		timeout:  timeout,
+	}
+}
+
+// ShuttingDown is called once the shutdown begins, it can not be undone.
+func (r *Readiness) ShuttingDown() {
+	r.shuttingDown.Store(true)
+}
+
+func (r *Readiness) Check(ctx context.Context) Report {
+	report := Report{Status: StatusOk, Checks: make(map[string]CheckReport, len(r.checkers)+1)}
+
+	shutdown := CheckReport{Status: StatusOk}
+	if r.shuttingDown.Load() {
+		shutdown = CheckReport{Status: StatusFail, Error: errShuttingDown.Error()}
+		report.Status = StatusFail
+	}
+	report.Checks["shutdown"] = shutdown
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, checker := range r.checkers {
+		wg.Add(1)
+
+		go func(checker HealthChecker) {
+			defer wg.Done()
+
+			start := time.Now()
+			err := checker.Check(ctx)
+			result := CheckReport{Status: StatusOk, DurationMs: time.Since(start).Milliseconds()}
+
+			if err != nil {
+				result.Status = StatusFail
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			report.Checks[checker.Name()] = result
+			if err != nil {
+				report.Status = StatusFail
+			}
+			mu.Unlock()
+		}(checker)
+	}
+
+	wg.Wait()
+
+	return report
+}
@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+type contextKey struct{}
+
+// New returns a logger writing text lines or JSON objects to w. Every value is
+// passed through Redact, so fields tagged sensitive:"true" and attributes
+// named like passwords, tokens or secrets never reach the output.
+func New(w io.Writer, format string, level string) *slog.Logger {
+	options := &slog.HandlerOptions{
+		Level:       parseLevel(level),
+		ReplaceAttr: replaceAttr,
+	}
+
+	if format == "json" {
+		return slog.New(slog.NewJSONHandler(w, options))
+	}
+
+	return slog.New(slog.NewTextHandler(w, options))
+}
+
+func parseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// WithContext returns a copy of ctx carrying logger.
+func WithContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger of the request ctx belongs to, with its
+// request id, route and user attached, or the default logger outside of a
+// request.
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+			return logger
+		}
+	}
+
+	return slog.Default()
+}
+
+// With returns a copy of ctx whose logger adds args to every line.
+func With(ctx context.Context, args ...any) context.Context {
+	return WithContext(ctx, FromContext(ctx).With(args...))
+}
+
+// WithLazy returns a logger adding attrs to every line like With, except that
+// slog.LogValuer values are resolved when each line is written instead of
+// once, for values that change over the life of the logger.
+func WithLazy(logger *slog.Logger, attrs ...slog.Attr) *slog.Logger {
+	return slog.New(&lazyHandler{Handler: logger.Handler(), attrs: attrs})
+}
+
+type lazyHandler struct {
+	slog.Handler
+	attrs []slog.Attr
+}
+
+func (h *lazyHandler) Handle(ctx context.Context, record slog.Record) error {
+	record = record.Clone()
+	record.AddAttrs(h.attrs...)
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *lazyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &lazyHandler{Handler: h.Handler.WithAttrs(attrs), attrs: h.attrs}
+}
+
+func (h *lazyHandler) WithGroup(name string) slog.Handler {
+	return &lazyHandler{Handler: h.Handler.WithGroup(name), attrs: h.attrs}
+}
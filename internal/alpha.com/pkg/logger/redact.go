@@ -0,0 +1,187 @@
+package logger
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// maxDepth stops Redact on self referencing values.
+const maxDepth = 6
+
+// sensitiveKeyParts mark an attribute or field as sensitive wherever they
+// appear in its name, sensitiveKeys only when they are the whole name. Names
+// are compared in lower case without dashes and underscores.
+var (
+	sensitiveKeyParts = []string{"password", "token", "secret", "authorization", "cookie"}
+	sensitiveKeys     = []string{"code", "codes", "recoverycodes", "apikey", "rawkey"}
+)
+
+var (
+	errorType         = reflect.TypeOf((*error)(nil)).Elem()
+	stringerType      = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	logValuerType     = reflect.TypeOf((*slog.LogValuer)(nil)).Elem()
+)
+
+func replaceAttr(_ []string, a slog.Attr) slog.Attr {
+	if IsSensitiveKey(a.Key) && !(a.Value.Kind() == slog.KindString && a.Value.String() == "") {
+		return slog.String(a.Key, redacted)
+	}
+
+	if a.Value.Kind() == slog.KindAny {
+		a.Value = Redact(a.Value.Any())
+	}
+
+	return a
+}
+
+// IsSensitiveKey reports whether an attribute or field with this name is
+// redacted regardless of its tags.
+func IsSensitiveKey(key string) bool {
+	normalized := strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(normalized, part) {
+			return true
+		}
+	}
+
+	for _, sensitiveKey := range sensitiveKeys {
+		if normalized == sensitiveKey {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Redact turns structs, maps and slices of them into groups, with every field
+// tagged sensitive:"true" or named like a secret replaced by [REDACTED]. Fields
+// are named by their json tag. Other values are returned as they are.
+func Redact(v any) slog.Value {
+	return redactValue(reflect.ValueOf(v), 0)
+}
+
+func redactValue(value reflect.Value, depth int) slog.Value {
+	if !value.IsValid() {
+		return slog.AnyValue(nil)
+	}
+
+	if depth > maxDepth {
+		return slog.StringValue("...")
+	}
+
+	if value.Type().Implements(logValuerType) && value.CanInterface() {
+		if value.Kind() == reflect.Pointer && value.IsNil() {
+			return slog.AnyValue(nil)
+		}
+		return value.Interface().(slog.LogValuer).LogValue().Resolve()
+	}
+
+	if formatsItself(value.Type()) && value.CanInterface() {
+		return slog.AnyValue(value.Interface())
+	}
+
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return slog.AnyValue(nil)
+		}
+		return redactValue(value.Elem(), depth+1)
+	case reflect.Struct:
+		attrs := make([]slog.Attr, 0, value.NumField())
+
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			name := fieldName(field)
+			if name == "-" {
+				continue
+			}
+
+			attrs = append(attrs, redactAttr(name, value.Field(i), field.Tag.Get("sensitive") == "true", depth))
+		}
+
+		return slog.GroupValue(attrs...)
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			break
+		}
+
+		attrs := make([]slog.Attr, 0, value.Len())
+
+		iter := value.MapRange()
+		for iter.Next() {
+			attrs = append(attrs, redactAttr(iter.Key().String(), iter.Value(), false, depth))
+		}
+
+		return slog.GroupValue(attrs...)
+	case reflect.Slice, reflect.Array:
+		if !isComposite(value.Type().Elem()) {
+			break
+		}
+
+		attrs := make([]slog.Attr, 0, value.Len())
+
+		for i := 0; i < value.Len(); i++ {
+			attrs = append(attrs, slog.Attr{Key: strconv.Itoa(i), Value: redactValue(value.Index(i), depth+1)})
+		}
+
+		return slog.GroupValue(attrs...)
+	}
+
+	if value.CanInterface() {
+		return slog.AnyValue(value.Interface())
+	}
+
+	return slog.StringValue(value.String())
+}
+
+func redactAttr(name string, value reflect.Value, sensitive bool, depth int) slog.Attr {
+	if (sensitive || IsSensitiveKey(name)) && !value.IsZero() {
+		return slog.String(name, redacted)
+	}
+
+	return slog.Attr{Key: name, Value: redactValue(value, depth+1)}
+}
+
+func fieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
+
+// formatsItself is true for errors, times, ids and the like, which are logged
+// the way they print.
+func formatsItself(t reflect.Type) bool {
+	for _, formatter := range []reflect.Type{errorType, stringerType, textMarshalerType, jsonMarshalerType} {
+		if t.Implements(formatter) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isComposite(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Pointer, reflect.Interface:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,17 @@
+package metrics
+
+// BusinessMetrics count the domain events product follows, next to the
+// technical metrics.
+type BusinessMetrics struct {
+	SignUps         *Counter
+	JobsPosted      *Counter
+	JobApplications *Counter
+}
+
+func NewBusinessMetrics(registry *Registry) *BusinessMetrics {
+	return &BusinessMetrics{
+		SignUps:         registry.NewCounter("alpha_sign_ups_total", "Accounts created, by password, magic link or an external identity."),
+		JobsPosted:      registry.NewCounter("alpha_jobs_posted_total", "Jobs posted by business accounts."),
+		JobApplications: registry.NewCounter("alpha_job_applications_total", "Applications sent to jobs."),
+	}
+}
@@ -0,0 +1,217 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// CounterVec is a family of counters that only go up, one per combination of
+// label values.
+type CounterVec struct {
+	desc
+	mu     sync.Mutex
+	series map[string]float64
+}
+
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{
+		desc:   desc{name: name, help: help, metricType: "counter", labels: labels},
+		series: map[string]float64{},
+	}
+
+	r.register(name, c)
+	return c
+}
+
+// NewCounter is a counter without labels.
+func (r *Registry) NewCounter(name, help string) *Counter {
+	return r.NewCounterVec(name, help).With()
+}
+
+// With returns the counter of the given label values, in the order the labels
+// were declared.
+func (c *CounterVec) With(values ...string) *Counter {
+	key := c.key(values)
+
+	// exported as 0 from now on, a series appearing from nowhere breaks rate()
+	c.mu.Lock()
+	if _, ok := c.series[key]; !ok {
+		c.series[key] = 0
+	}
+	c.mu.Unlock()
+
+	return &Counter{vec: c, key: key}
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.writeHeader(w)
+	for _, key := range sortedSeriesKeys(c.series) {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, c.labelPairs(splitKey(key, len(c.labels))), formatFloat(c.series[key]))
+	}
+}
+
+type Counter struct {
+	vec *CounterVec
+	key string
+}
+
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+// Add panics on negative values, counters never go down.
+func (c *Counter) Add(value float64) {
+	if value < 0 {
+		panic(fmt.Sprintf("counter %s can not decrease", c.vec.name))
+	}
+
+	c.vec.mu.Lock()
+	c.vec.series[c.key] += value
+	c.vec.mu.Unlock()
+}
+
+// GaugeVec is a family of values that go up and down.
+type GaugeVec struct {
+	desc
+	mu     sync.Mutex
+	series map[string]float64
+}
+
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{
+		desc:   desc{name: name, help: help, metricType: "gauge", labels: labels},
+		series: map[string]float64{},
+	}
+
+	r.register(name, g)
+	return g
+}
+
+func (g *GaugeVec) With(values ...string) *Gauge {
+	return &Gauge{vec: g, key: g.key(values)}
+}
+
+func (g *GaugeVec) write(w *bufio.Writer) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.writeHeader(w)
+	for _, key := range sortedSeriesKeys(g.series) {
+		fmt.Fprintf(w, "%s%s %s\n", g.name, g.labelPairs(splitKey(key, len(g.labels))), formatFloat(g.series[key]))
+	}
+}
+
+type Gauge struct {
+	vec *GaugeVec
+	key string
+}
+
+func (g *Gauge) Set(value float64) {
+	g.vec.mu.Lock()
+	g.vec.series[g.key] = value
+	g.vec.mu.Unlock()
+}
+
+func (g *Gauge) Add(value float64) {
+	g.vec.mu.Lock()
+	g.vec.series[g.key] += value
+	g.vec.mu.Unlock()
+}
+
+func (g *Gauge) Inc() { g.Add(1) }
+func (g *Gauge) Dec() { g.Add(-1) }
+
+// gaugeFunc reads its value when it is scraped.
+type gaugeFunc struct {
+	desc
+	fn func() float64
+}
+
+// NewGaugeFunc registers a gauge whose value is read from fn on every scrape.
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.register(name, &gaugeFunc{
+		desc: desc{name: name, help: help, metricType: "gauge"},
+		fn:   fn,
+	})
+}
+
+func (g *gaugeFunc) write(w *bufio.Writer) {
+	g.writeHeader(w)
+	fmt.Fprintf(w, "%s %s\n", g.name, formatFloat(g.fn()))
+}
+
+// HistogramVec counts observations into cumulative buckets, one histogram per
+// combination of label values.
+type HistogramVec struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*histogram
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+
+	h := &HistogramVec{
+		desc:    desc{name: name, help: help, metricType: "histogram", labels: labels},
+		buckets: sorted,
+		series:  map[string]*histogram{},
+	}
+
+	r.register(name, h)
+	return h
+}
+
+// Observe records value for the given label values.
+func (h *HistogramVec) Observe(value float64, values ...string) {
+	key := h.key(values)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	series, ok := h.series[key]
+	if !ok {
+		series = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.series[key] = series
+	}
+
+	for i, upperBound := range h.buckets {
+		if value <= upperBound {
+			series.counts[i]++
+		}
+	}
+
+	series.count++
+	series.sum += value
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.writeHeader(w)
+	for _, key := range sortedSeriesKeys(h.series) {
+		series := h.series[key]
+		values := splitKey(key, len(h.labels))
+
+		for i, upperBound := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelPairs(values, "le", formatFloat(upperBound)), series.counts[i])
+		}
+
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelPairs(values, "le", "+Inf"), series.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, h.labelPairs(values), formatFloat(series.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, h.labelPairs(values), series.count)
+	}
+}
@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"runtime"
+	"time"
+)
+
+// RegisterRuntimeMetrics adds the process metrics every dashboard starts with.
+func RegisterRuntimeMetrics(registry *Registry) {
+	startTime := float64(time.Now().Unix())
+
+	registry.NewGaugeFunc("process_start_time_seconds", "Start time of the process since unix epoch in seconds.", func() float64 {
+		return startTime
+	})
+
+	registry.NewGaugeFunc("go_goroutines", "Number of goroutines that currently exist.", func() float64 {
+		return float64(runtime.NumGoroutine())
+	})
+
+	registry.NewGaugeFunc("go_memstats_heap_alloc_bytes", "Number of heap bytes allocated and still in use.", func() float64 {
+		var memStats runtime.MemStats
+		runtime.ReadMemStats(&memStats)
+
+		return float64(memStats.HeapAlloc)
+	})
+}
@@ -0,0 +1,138 @@
+package migration
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Migration is one versioned change of the database. Up creates Indexes after
+// Transform ran, so that a transform can fix the documents a new unique index
+// would reject, and Down drops them before Revert runs. A migration with a
+// Transform but no Revert can not be reverted.
+type Migration struct {
+	Version   int
+	Name      string
+	Indexes   []Index
+	Transform func(ctx context.Context, db *mongo.Database) error
+	Revert    func(ctx context.Context, db *mongo.Database) error
+}
+
+// Index is named like the driver names indexes by default, e.g. email_1, so
+// that indexes created before migrations existed are recognised.
+type Index struct {
+	Collection string
+	Keys       bson.D
+	Unique     bool
+	Sparse     bool
+	// ExpireAfter makes a TTL index when set, 0 expires documents at the
+	// time stored in the field
+	ExpireAfter *time.Duration
+}
+
+func (i Index) Name() string {
+	parts := make([]string, 0, len(i.Keys))
+	for _, key := range i.Keys {
+		parts = append(parts, fmt.Sprintf("%s_%v", key.Key, key.Value))
+	}
+
+	return strings.Join(parts, "_")
+}
+
+func (i Index) model() mongo.IndexModel {
+	indexOptions := options.Index().SetName(i.Name())
+
+	if i.Unique {
+		indexOptions.SetUnique(true)
+	}
+
+	if i.Sparse {
+		indexOptions.SetSparse(true)
+	}
+
+	if i.ExpireAfter != nil {
+		indexOptions.SetExpireAfterSeconds(int32(i.ExpireAfter.Seconds()))
+	}
+
+	return mongo.IndexModel{Keys: i.Keys, Options: indexOptions}
+}
+
+// ExpireAt is the ExpireAfter of a TTL index on a field holding the expiry.
+func ExpireAt() *time.Duration {
+	expireAfter := time.Duration(0)
+	return &expireAfter
+}
+
+// Checksum covers the version, the name and the indexes. The code of Transform
+// can not be hashed, a changed transform must be a new migration.
+func (m Migration) Checksum() string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%d\n%s\n", m.Version, m.Name)
+
+	for _, index := range m.Indexes {
+		fmt.Fprintf(hash, "%s %s unique=%t sparse=%t", index.Collection, index.Name(), index.Unique, index.Sparse)
+		if index.ExpireAfter != nil {
+			fmt.Fprintf(hash, " expireAfter=%s", *index.ExpireAfter)
+		}
+		fmt.Fprintln(hash)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (m Migration) up(ctx context.Context, db *mongo.Database) error {
+	if m.Transform != nil {
+		if err := m.Transform(ctx, db); err != nil {
+			return fmt.Errorf("transform: %w", err)
+		}
+	}
+
+	for _, index := range m.Indexes {
+		if _, err := db.Collection(index.Collection).Indexes().CreateOne(ctx, index.model()); err != nil {
+			return fmt.Errorf("index %s.%s: %w", index.Collection, index.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+func (m Migration) down(ctx context.Context, db *mongo.Database) error {
+	if m.Transform != nil && m.Revert == nil {
+		return fmt.Errorf("migration %d %s can not be reverted", m.Version, m.Name)
+	}
+
+	for i := len(m.Indexes) - 1; i >= 0; i-- {
+		index := m.Indexes[i]
+
+		if _, err := db.Collection(index.Collection).Indexes().DropOne(ctx, index.Name()); err != nil && !isIndexNotFound(err) {
+			return fmt.Errorf("index %s.%s: %w", index.Collection, index.Name(), err)
+		}
+	}
+
+	if m.Revert != nil {
+		if err := m.Revert(ctx, db); err != nil {
+			return fmt.Errorf("revert: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// isIndexNotFound tolerates indexes dropped by hand, or collections that were
+// never created.
+func isIndexNotFound(err error) bool {
+	var commandError mongo.CommandError
+	if errors.As(err, &commandError) {
+		return commandError.Code == 27 || commandError.Code == 26 // IndexNotFound, NamespaceNotFound
+	}
+
+	return false
+}
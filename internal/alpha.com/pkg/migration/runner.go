@@ -0,0 +1,311 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	lockID = "lock"
+	// lockTime is renewed before every migration, a replica that died while
+	// holding the lock blocks the others for at most this long
+	lockTime         = 5 * time.Minute
+	lockPollInterval = time.Second
+)
+
+var ErrChecksumMismatch = errors.New("applied migration was changed")
+
+// appliedMigration is the record of a migration in the migrations collection,
+// next to the lock document whose _id is "lock".
+type appliedMigration struct {
+	Version    int       `bson:"_id"`
+	Name       string    `bson:"name"`
+	Checksum   string    `bson:"checksum"`
+	AppliedAt  time.Time `bson:"appliedAt"`
+	DurationMs int64     `bson:"durationMs"`
+}
+
+const (
+	StateApplied = "applied"
+	StatePending = "pending"
+	// StateChanged is an applied migration whose definition was edited since
+	StateChanged = "changed"
+	// StateUnknown is an applied migration this binary does not know, e.g.
+	// after a rollback to an older release
+	StateUnknown = "unknown"
+)
+
+type Status struct {
+	Version   int
+	Name      string
+	State     string
+	AppliedAt *time.Time
+}
+
+// Runner applies migrations in version order. A lock document makes sure a
+// single replica migrates at a time, the others wait for it.
+type Runner struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+	migrations []Migration
+	owner      string
+}
+
+// NewRunner panics on duplicate versions, they are a programming error.
+func NewRunner(db *mongo.Database, collectionName string, migrations []Migration) *Runner {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Version == sorted[i-1].Version {
+			panic(fmt.Sprintf("migration version %d is used twice", sorted[i].Version))
+		}
+	}
+
+	hostname, _ := os.Hostname()
+
+	return &Runner{
+		db:         db,
+		collection: db.Collection(collectionName),
+		migrations: sorted,
+		owner:      hostname + "/" + uuid.NewString(),
+	}
+}
+
+// Status lists every known and every applied migration by version.
+func (r *Runner) Status(ctx context.Context) ([]Status, error) {
+	applied, err := r.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []Status
+
+	for _, migration := range r.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name, State: StatePending}
+
+		if record, ok := applied[migration.Version]; ok {
+			status.State = StateApplied
+			status.AppliedAt = &record.AppliedAt
+
+			if record.Checksum != migration.Checksum() {
+				status.State = StateChanged
+			}
+
+			delete(applied, migration.Version)
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	for _, record := range applied {
+		appliedAt := record.AppliedAt
+		statuses = append(statuses, Status{Version: record.Version, Name: record.Name, State: StateUnknown, AppliedAt: &appliedAt})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+
+	return statuses, nil
+}
+
+// Pending is the number of migrations Up would apply.
+func (r *Runner) Pending(ctx context.Context) (int, error) {
+	statuses, err := r.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	pending := 0
+	for _, status := range statuses {
+		if status.State == StatePending {
+			pending++
+		}
+	}
+
+	return pending, nil
+}
+
+// Up applies the pending migrations. It refuses to run when an applied
+// migration was changed, the database would not match the code.
+func (r *Runner) Up(ctx context.Context) (int, error) {
+	if err := r.lock(ctx); err != nil {
+		return 0, err
+	}
+	defer r.unlock()
+
+	statuses, err := r.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, status := range statuses {
+		if status.State == StateChanged {
+			return 0, fmt.Errorf("migration %d %s: %w", status.Version, status.Name, ErrChecksumMismatch)
+		}
+	}
+
+	pending := make(map[int]bool)
+	for _, status := range statuses {
+		if status.State == StatePending {
+			pending[status.Version] = true
+		}
+	}
+
+	count := 0
+
+	for _, migration := range r.migrations {
+		if !pending[migration.Version] {
+			continue
+		}
+
+		if err := r.renewLock(ctx); err != nil {
+			return count, err
+		}
+
+		slog.Info("Applying migration", "version", migration.Version, "name", migration.Name)
+		start := time.Now()
+
+		if err := migration.up(ctx, r.db); err != nil {
+			return count, fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+
+		record := appliedMigration{
+			Version:    migration.Version,
+			Name:       migration.Name,
+			Checksum:   migration.Checksum(),
+			AppliedAt:  time.Now(),
+			DurationMs: time.Since(start).Milliseconds(),
+		}
+
+		if _, err := r.collection.InsertOne(ctx, record); err != nil {
+			return count, fmt.Errorf("migration %d %s applied but not recorded: %w", migration.Version, migration.Name, err)
+		}
+
+		slog.Info("Migration applied", "version", migration.Version, "name", migration.Name, "duration_ms", record.DurationMs)
+		count++
+	}
+
+	return count, nil
+}
+
+// Down reverts the last steps applied migrations, newest first.
+func (r *Runner) Down(ctx context.Context, steps int) (int, error) {
+	if err := r.lock(ctx); err != nil {
+		return 0, err
+	}
+	defer r.unlock()
+
+	applied, err := r.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+
+	for i := len(r.migrations) - 1; i >= 0 && count < steps; i-- {
+		migration := r.migrations[i]
+
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		if err := r.renewLock(ctx); err != nil {
+			return count, err
+		}
+
+		slog.Info("Reverting migration", "version", migration.Version, "name", migration.Name)
+
+		if err := migration.down(ctx, r.db); err != nil {
+			return count, fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+
+		if _, err := r.collection.DeleteOne(ctx, bson.M{"_id": migration.Version}); err != nil {
+			return count, fmt.Errorf("migration %d %s reverted but still recorded: %w", migration.Version, migration.Name, err)
+		}
+
+		count++
+	}
+
+	return count, nil
+}
+
+func (r *Runner) applied(ctx context.Context) (map[int]appliedMigration, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$ne": lockID}})
+	if err != nil {
+		return nil, err
+	}
+
+	var records []appliedMigration
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]appliedMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+
+	return applied, nil
+}
+
+// lock waits until the lock document is free or expired and takes it.
+func (r *Runner) lock(ctx context.Context) error {
+	for waiting := false; ; waiting = true {
+		err := r.renewLock(ctx)
+		if err == nil {
+			return nil
+		}
+
+		if !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+
+		if !waiting {
+			slog.Info("Migrations are locked by another replica, waiting")
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+// renewLock takes the lock when it is free, expired or already ours. When
+// another owner holds it the upsert collides with its _id.
+func (r *Runner) renewLock(ctx context.Context) error {
+	now := time.Now()
+	filter := bson.M{
+		"_id": lockID,
+		"$or": bson.A{
+			bson.M{"owner": r.owner},
+			bson.M{"lockedUntil": bson.M{"$lt": now}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"owner": r.owner, "lockedUntil": now.Add(lockTime)}}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// unlock runs on its own context, the lock must be released even when the
+// migration was cancelled.
+func (r *Runner) unlock() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.collection.DeleteOne(ctx, bson.M{"_id": lockID, "owner": r.owner}); err != nil {
+		slog.Error("Migration lock could not be released, it expires by itself", "error", err)
+	}
+}
@@ -0,0 +1,26 @@
+package mongodb
+
+import (
+	"context"
+
+	"alpha.com/internal/alpha.com/pkg/health"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type healthChecker struct {
+	client *mongo.Client
+}
+
+// NewHealthChecker reports MongoDB as down when the primary does not answer a
+// ping, every write would fail.
+func NewHealthChecker(client *mongo.Client) health.HealthChecker {
+	return &healthChecker{client: client}
+}
+
+func (h *healthChecker) Name() string {
+	return "mongo"
+}
+
+func (h *healthChecker) Check(ctx context.Context) error {
+	return PingMongoDB(ctx, h.client)
+}
@@ -2,45 +2,67 @@ package mongodb
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"log/slog"
 	"time"
 
 	"alpha.com/configuration"
+	"alpha.com/internal/alpha.com/pkg/metrics"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
-func ConnectMongoDB() *mongo.Client {
+// ConnectMongoDB fails only on invalid options, e.g. a malformed URI, or when
+// ctx is done. The driver connects in the background, a server that is down at
+// startup is reported by the first ping, which waits ConnectTimeout at most,
+// and by /readyz until it is reachable.
+func ConnectMongoDB(ctx context.Context, mongoConfig configuration.MongoConfig, registry *metrics.Registry) (*mongo.Client, error) {
+	connectTimeout := mongoConfig.ConnectTimeout.Duration()
+
 	// Set client options
-	clientOptions := options.Client().ApplyURI(configuration.MONGO_URI).SetMaxPoolSize(4).
+	clientOptions := options.Client().ApplyURI(mongoConfig.URI).SetMaxPoolSize(4).
 		SetMinPoolSize(2).
-		SetMaxConnIdleTime(1 * time.Second)
+		SetMaxConnIdleTime(1 * time.Second).
+		SetConnectTimeout(connectTimeout).
+		SetMonitor(newCommandMonitor(registry)).
+		SetPoolMonitor(newPoolMonitor(registry))
+
+	ctx, cancel := context.WithTimeout(ctx, connectTimeout)
+	defer cancel()
 
 	// Connect to MongoDB
-	client, err := mongo.Connect(context.TODO(), clientOptions)
+	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
-		fmt.Println(err.Error())
+		return nil, err
 	}
 
-	PingMongoDB(client)
-
-	return client
-}
+	if err := PingMongoDB(ctx, client); err != nil {
+		// stopped while starting, there is nothing left to wait for
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return nil, errors.Join(err, client.Disconnect(context.Background()))
+		}
 
-func DisconnectMongoDB(client *mongo.Client) {
-	// Disconnect from MongoDB
-	if err := client.Disconnect(context.TODO()); err != nil {
-		fmt.Printf("MongoDB Shutdown Error: %v\n", err)
+		slog.Warn("MongoDB is not reachable yet", "error", err)
+	} else {
+		slog.Info("Connected to MongoDB")
 	}
-	fmt.Println("Connection to MongoDB closed.")
+
+	return client, nil
 }
 
-func PingMongoDB(client *mongo.Client) {
-	// Check the connection
-	err := client.Ping(context.TODO(), nil)
-	if err != nil {
-		fmt.Println(err.Error())
+// DisconnectMongoDB waits for the operations in progress, within the deadline
+// of ctx.
+func DisconnectMongoDB(ctx context.Context, client *mongo.Client) error {
+	if err := client.Disconnect(ctx); err != nil {
+		return err
 	}
 
-	fmt.Println("Connected to MongoDB!")
+	slog.Info("Connection to MongoDB closed")
+	return nil
+}
+
+// PingMongoDB checks that the primary answers, within the deadline of ctx.
+func PingMongoDB(ctx context.Context, client *mongo.Client) error {
+	return client.Ping(ctx, readpref.Primary())
 }
@@ -0,0 +1,86 @@
+package mongodb
+
+import (
+	"context"
+	"sync"
+
+	"alpha.com/internal/alpha.com/pkg/metrics"
+	"alpha.com/internal/alpha.com/pkg/tracing"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// newCommandMonitor observes the latency of every command sent to MongoDB by
+// command name, e.g. find, insert or findAndModify, and records a client span
+// for the commands sent within a trace. Commands of contexts without a span,
+// like the driver's own heartbeats, are not traced.
+func newCommandMonitor(registry *metrics.Registry) *event.CommandMonitor {
+	durations := registry.NewHistogramVec("mongodb_command_duration_seconds", "MongoDB command latencies in seconds.", metrics.DefaultBuckets, "command", "outcome")
+
+	// spans by request id, unique per client, until the command is answered
+	var spans sync.Map
+
+	endSpan := func(requestID int64, failure string) {
+		if value, ok := spans.LoadAndDelete(requestID); ok {
+			span := value.(*tracing.Span)
+			if failure != "" {
+				span.SetError(failure)
+			}
+			span.End()
+		}
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			if tracing.SpanFromContext(ctx) == nil {
+				return
+			}
+
+			_, span := tracing.StartKind(ctx, "mongodb."+e.CommandName, tracing.SpanKindClient)
+			span.SetAttribute("db.system", "mongodb")
+			span.SetAttribute("db.name", e.DatabaseName)
+			span.SetAttribute("db.operation", e.CommandName)
+			if first, err := e.Command.IndexErr(0); err == nil {
+				if collection, ok := first.Value().StringValueOK(); ok {
+					span.SetAttribute("db.mongodb.collection", collection)
+				}
+			}
+
+			spans.Store(e.RequestID, span)
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			durations.Observe(e.Duration.Seconds(), e.CommandName, "success")
+			endSpan(e.RequestID, "")
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			durations.Observe(e.Duration.Seconds(), e.CommandName, "failure")
+			endSpan(e.RequestID, e.Failure)
+		},
+	}
+}
+
+// newPoolMonitor keeps track of the connections of the pool, a pool that is
+// always fully in use means requests queue for a connection.
+func newPoolMonitor(registry *metrics.Registry) *event.PoolMonitor {
+	open := registry.NewGaugeVec("mongodb_pool_open_connections", "Connections open to MongoDB.").With()
+	inUse := registry.NewGaugeVec("mongodb_pool_in_use_connections", "Connections checked out of the pool.").With()
+	checkoutFailures := registry.NewCounterVec("mongodb_pool_checkout_failures_total", "Connections that could not be checked out of the pool, by reason.", "reason")
+	checkoutWait := registry.NewHistogramVec("mongodb_pool_checkout_duration_seconds", "Time spent waiting for a connection in seconds.", metrics.DefaultBuckets)
+
+	return &event.PoolMonitor{
+		Event: func(e *event.PoolEvent) {
+			switch e.Type {
+			case event.ConnectionCreated:
+				open.Inc()
+			case event.ConnectionClosed:
+				open.Dec()
+			case event.GetSucceeded:
+				inUse.Inc()
+				checkoutWait.Observe(e.Duration.Seconds())
+			case event.ConnectionReturned:
+				inUse.Dec()
+			case event.GetFailed:
+				checkoutFailures.With(e.Reason).Inc()
+			}
+		},
+	}
+}
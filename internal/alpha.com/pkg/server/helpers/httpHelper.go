@@ -1,22 +1,48 @@
 package helpers
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net/http"
+
+	"alpha.com/internal/alpha.com/pkg/tracing"
 )
 
-func HttpPostHelper(url string, bodyOfReq io.Reader) ([]byte, error) {
-	resp, err := http.Post(url, "application/json", bodyOfReq)
+// HttpPostHelper posts a JSON body within a client span and passes the trace
+// on to the callee in the traceparent header.
+func HttpPostHelper(ctx context.Context, url string, bodyOfReq io.Reader) ([]byte, error) {
+	ctx, span := tracing.StartKind(ctx, "HTTP POST", tracing.SpanKindClient)
+	defer span.End()
 
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bodyOfReq)
 	if err != nil {
+		span.RecordError(err)
+		return nil, errors.New("request could not be send")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(tracing.TraceparentHeader, span.SpanContext().Traceparent())
+	span.SetAttribute("http.method", http.MethodPost)
+	span.SetAttribute("http.url", req.URL.Redacted())
+
+	resp, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		span.RecordError(err)
 		return nil, errors.New("request could not be send")
 	}
 
 	defer resp.Body.Close()
 
+	span.SetAttribute("http.status_code", resp.StatusCode)
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetError(resp.Status)
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
+		span.RecordError(err)
 		return nil, errors.New("answer could not be read")
 	}
 
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrForcedShutdown is returned by Shutdown when the deadline passed before
+// every component stopped, work may have been lost.
+var ErrForcedShutdown = errors.New("shutdown deadline exceeded, stopped by force")
+
+type component struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+// Lifecycle stops the parts of the process, the HTTP server, MongoDB, the
+// tracer and the background workers, in the reverse order they were
+// registered, so that nothing still running loses a dependency. Register a
+// component right after it is started.
+type Lifecycle struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu         sync.Mutex
+	components []component
+}
+
+func NewLifecycle() *Lifecycle {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Lifecycle{ctx: ctx, cancel: cancel}
+}
+
+// Context is the root context of the process. It is done once Shutdown has
+// returned, background work derives its context from it.
+func (l *Lifecycle) Context() context.Context {
+	return l.ctx
+}
+
+// Register adds a component, stop must return once it is stopped or ctx is
+// done.
+func (l *Lifecycle) Register(name string, stop func(ctx context.Context) error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.components = append(l.components, component{name: name, stop: stop})
+}
+
+// Go runs a background worker until it is stopped, its context is cancelled
+// on shutdown and the shutdown waits for run to return.
+func (l *Lifecycle) Go(name string, run func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(l.ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		run(ctx)
+	}()
+
+	l.Register(name, func(stopCtx context.Context) error {
+		cancel()
+
+		select {
+		case <-done:
+			return nil
+		case <-stopCtx.Done():
+			return stopCtx.Err()
+		}
+	})
+}
+
+// Shutdown stops every component, last registered first, within the deadline
+// of ctx. A component that does not stop in time is left behind and the
+// remaining ones are skipped, ErrForcedShutdown is returned then.
+func (l *Lifecycle) Shutdown(ctx context.Context) error {
+	defer l.cancel()
+
+	l.mu.Lock()
+	components := append([]component(nil), l.components...)
+	l.mu.Unlock()
+
+	var errs []error
+
+	for i := len(components) - 1; i >= 0; i-- {
+		c := components[i]
+
+		if ctx.Err() != nil {
+			slog.Error("Component not stopped, shutdown deadline exceeded", "component", c.name)
+			errs = append(errs, fmt.Errorf("%s: %w", c.name, ErrForcedShutdown))
+			continue
+		}
+
+		start := time.Now()
+		err := stopWithin(ctx, c.stop)
+
+		switch {
+		case errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled):
+			slog.Error("Component did not stop before the shutdown deadline", "component", c.name, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", c.name, ErrForcedShutdown))
+		case err != nil:
+			slog.Error("Component stopped with an error", "component", c.name, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", c.name, err))
+		default:
+			slog.Info("Component stopped", "component", c.name, "duration_ms", time.Since(start).Milliseconds())
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// stopWithin returns when stop does or ctx is done, whichever comes first, a
+// component ignoring ctx must not hold the process.
+func stopWithin(ctx context.Context, stop func(ctx context.Context) error) error {
+	result := make(chan error, 1)
+
+	go func() {
+		result <- stop(ctx)
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,119 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"alpha.com/internal/alpha.com/application/query"
+	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"alpha.com/internal/alpha.com/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+const ApiKeyHeader = "X-Api-Key"
+
+// IApiKeyAuthenticator resolves the key sent in X-Api-Key.
+type IApiKeyAuthenticator interface {
+	Authenticate(ctx context.Context, rawKey string) (*domain.ApiKey, *domain.BusinessAccount, error)
+}
+
+type authMiddleware struct {
+	jwtMiddleware       fiber.Handler
+	apiKeyAuthenticator IApiKeyAuthenticator
+	userStatusChecker   IUserStatusChecker
+}
+
+// NewAuthMiddleware accepts an API key in X-Api-Key and falls back to the JWT
+// middleware otherwise. Both set the same utils.UserContext, so it is used on
+// the routes integrations call and the handlers need not care which it was.
+func NewAuthMiddleware(jwtMiddleware fiber.Handler, apiKeyAuthenticator IApiKeyAuthenticator, userStatusChecker IUserStatusChecker) fiber.Handler {
+	m := &authMiddleware{
+		jwtMiddleware:       jwtMiddleware,
+		apiKeyAuthenticator: apiKeyAuthenticator,
+		userStatusChecker:   userStatusChecker,
+	}
+
+	return m.AuthMiddleware
+}
+
+func (m *authMiddleware) AuthMiddleware(c *fiber.Ctx) error {
+	rawKey := c.Get(ApiKeyHeader)
+
+	if rawKey == "" {
+		return m.jwtMiddleware(c)
+	}
+
+	apiKey, businessAccount, err := m.apiKeyAuthenticator.Authenticate(c.UserContext(), rawKey)
+
+	if errors.Is(err, query.ErrInvalidApiKey) {
+		return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+	}
+
+	if err != nil {
+		logger.FromContext(c.UserContext()).Error("AuthMiddleware error while checking api key", "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Api key could not be verified")
+	}
+
+	ownerID := businessAccount.UserID.Hex()
+
+	blocked, err := m.userStatusChecker.IsBlocked(c.UserContext(), ownerID)
+	if err != nil {
+		logger.FromContext(c.UserContext()).Error("AuthMiddleware error while checking user status", "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "User status could not be verified")
+	}
+
+	if blocked {
+		return fiber.NewError(fiber.StatusUnauthorized, "Owner of the api key is suspended or banned")
+	}
+
+	scopes := make([]string, 0, len(apiKey.Scopes))
+	for _, scope := range apiKey.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+
+	userCtx := &utils.UserContext{
+		UserID:            ownerID,
+		Roles:             []string{},
+		ApiKeyID:          apiKey.Id.Hex(),
+		BusinessAccountID: businessAccount.Id.Hex(),
+		Scopes:            scopes,
+	}
+	ctx := context.WithValue(c.UserContext(), "user", userCtx)
+	ctx = logger.With(ctx, "user_id", userCtx.UserID, "api_key_id", userCtx.ApiKeyID)
+	c.SetUserContext(ctx)
+
+	return c.Next()
+}
+
+// RequireScope must be registered after AuthMiddleware. Requests made with an
+// API key need every given scope; requests made with a JWT are left to
+// RequirePermission and pass through.
+func RequireScope(scopes ...domain.Permission) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userCtx, ok := c.UserContext().Value("user").(*utils.UserContext)
+		if !ok {
+			return fiber.NewError(fiber.StatusUnauthorized, "Missing or malformed credentials")
+		}
+
+		if !userCtx.IsApiKey() {
+			return c.Next()
+		}
+
+		for _, scope := range scopes {
+			granted := false
+			for _, userScope := range userCtx.Scopes {
+				if userScope == string(scope) {
+					granted = true
+				}
+			}
+
+			if !granted {
+				return fiber.NewError(fiber.StatusForbidden, fmt.Sprintf("Api key is missing the %s scope", scope))
+			}
+		}
+
+		return c.Next()
+	}
+}
@@ -2,18 +2,43 @@ package middlewares
 
 import (
 	"context"
-	"fmt"
 	"strings"
 
-	"alpha.com/configuration"
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"alpha.com/internal/alpha.com/pkg/server/services"
 	"alpha.com/internal/alpha.com/pkg/utils"
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gofiber/fiber/v2"
 )
 
-var JWTSecret = []byte(configuration.JWT_SECRET)
+// IUserStatusChecker reports whether a user has been banned or suspended
+// so that their still-valid tokens can be rejected.
+type IUserStatusChecker interface {
+	IsBlocked(ctx context.Context, userID string) (bool, error)
+}
+
+// ISessionRevocationChecker reports whether the session an access token was
+// issued for has been signed out.
+type ISessionRevocationChecker interface {
+	IsRevoked(ctx context.Context, sessionID string) (bool, error)
+}
+
+type jwtMiddleware struct {
+	jwtService               services.IJwtService
+	userStatusChecker        IUserStatusChecker
+	sessionRevocationChecker ISessionRevocationChecker
+}
+
+func NewJwtMiddleware(jwtService services.IJwtService, userStatusChecker IUserStatusChecker, sessionRevocationChecker ISessionRevocationChecker) fiber.Handler {
+	m := &jwtMiddleware{
+		jwtService:               jwtService,
+		userStatusChecker:        userStatusChecker,
+		sessionRevocationChecker: sessionRevocationChecker,
+	}
+
+	return m.JwtMiddleware
+}
 
-func JwtMiddleware(c *fiber.Ctx) error {
+func (m *jwtMiddleware) JwtMiddleware(c *fiber.Ctx) error {
 	authHeader := c.Get("Authorization")
 
 	if authHeader == "" {
@@ -26,25 +51,42 @@ func JwtMiddleware(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusUnauthorized, "Invalid Authorization header format")
 	}
 
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	claims, err := m.jwtService.ParseAccessToken(tokenString)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+	}
+
+	if claims.SessionID != "" {
+		revoked, err := m.sessionRevocationChecker.IsRevoked(c.UserContext(), claims.SessionID)
+		if err != nil {
+			logger.FromContext(c.UserContext()).Error("JwtMiddleware error while checking session revocation", "error", err)
+			return fiber.NewError(fiber.StatusInternalServerError, "Session could not be verified")
 		}
-		return JWTSecret, nil
-	})
 
+		if revoked {
+			return fiber.NewError(fiber.StatusUnauthorized, "Session has been signed out")
+		}
+	}
+
+	blocked, err := m.userStatusChecker.IsBlocked(c.UserContext(), claims.Subject)
 	if err != nil {
-		return fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired JWT")
+		logger.FromContext(c.UserContext()).Error("JwtMiddleware error while checking user status", "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "User status could not be verified")
+	}
+
+	if blocked {
+		return fiber.NewError(fiber.StatusUnauthorized, "User is suspended or banned")
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		userID := claims["userID"].(string)
-		userCtx := &utils.UserContext{UserID: userID}
-		ctx := context.WithValue(c.UserContext(), "user", userCtx)
-		c.SetUserContext(ctx)
-	} else {
-		return fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired JWT")
+	roles := claims.Roles
+	if roles == nil {
+		roles = []string{}
 	}
 
+	userCtx := &utils.UserContext{UserID: claims.Subject, SessionID: claims.SessionID, Roles: roles}
+	ctx := context.WithValue(c.UserContext(), "user", userCtx)
+	ctx = logger.With(ctx, "user_id", userCtx.UserID)
+	c.SetUserContext(ctx)
+
 	return c.Next()
 }
@@ -0,0 +1,65 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"alpha.com/internal/alpha.com/pkg/metrics"
+	"github.com/gofiber/fiber/v2"
+	fiberUtils "github.com/gofiber/fiber/v2/utils"
+)
+
+// unmatchedRoute labels requests no route matched, their paths would make a
+// new series each.
+const unmatchedRoute = "unmatched"
+
+// NewHttpMetrics counts requests and their duration by method, route and
+// status. Routes are the patterns, e.g. /api/v1/alpha/job/:id, so the number
+// of series stays bounded.
+func NewHttpMetrics(registry *metrics.Registry) fiber.Handler {
+	requests := registry.NewCounterVec("http_requests_total", "HTTP requests handled.", "method", "route", "status")
+	durations := registry.NewHistogramVec("http_request_duration_seconds", "HTTP request latencies in seconds.", metrics.DefaultBuckets, "method", "route", "status")
+	inFlight := registry.NewGaugeVec("http_requests_in_flight", "HTTP requests being handled.").With()
+
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		err := c.Next()
+
+		status := responseStatus(c, err)
+
+		route := fiberUtils.CopyString(c.Route().Path)
+		if status == fiber.StatusNotFound && route == "/" {
+			route = unmatchedRoute
+		}
+
+		method := fiberUtils.CopyString(c.Method())
+		statusLabel := strconv.Itoa(status)
+
+		requests.With(method, route, statusLabel).Inc()
+		durations.Observe(time.Since(start).Seconds(), method, route, statusLabel)
+
+		return err
+	}
+}
+
+// responseStatus is the status the error handler will send for err, which
+// runs only after the middlewares have returned.
+func responseStatus(c *fiber.Ctx, err error) int {
+	var fiberError *fiber.Error
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return fiber.StatusGatewayTimeout
+	case errors.As(err, &fiberError):
+		return fiberError.Code
+	case err != nil:
+		return fiber.StatusInternalServerError
+	}
+
+	return c.Response().StatusCode()
+}
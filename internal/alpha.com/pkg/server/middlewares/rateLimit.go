@@ -0,0 +1,103 @@
+package middlewares
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"alpha.com/internal/alpha.com/pkg/server/services"
+	"alpha.com/internal/alpha.com/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Clients a policy is counted per. User falls back to the IP address for
+// anonymous requests, api key falls back to the user and then the IP address.
+const (
+	RateLimitByIP     = "ip"
+	RateLimitByUser   = "user"
+	RateLimitByApiKey = "apiKey"
+)
+
+// RateLimitPolicy allows Capacity requests per Period to every client, with
+// tokens refilled evenly over the period.
+type RateLimitPolicy struct {
+	Capacity int
+	Period   time.Duration
+	KeyBy    string
+}
+
+type IRateLimiter interface {
+	Limit(policyName string) fiber.Handler
+}
+
+type rateLimiter struct {
+	store    services.IRateLimitStore
+	policies map[string]RateLimitPolicy
+}
+
+func NewRateLimiter(store services.IRateLimitStore, policies map[string]RateLimitPolicy) IRateLimiter {
+	return &rateLimiter{
+		store:    store,
+		policies: policies,
+	}
+}
+
+// Limit returns the middleware of the named policy. Routes keyed by user or
+// api key must register it after the authentication middleware. It panics on
+// an unknown policy, which can only happen while the router is set up.
+func (r *rateLimiter) Limit(policyName string) fiber.Handler {
+	policy, ok := r.policies[policyName]
+	if !ok {
+		panic(fmt.Sprintf("unknown rate limit policy: %s", policyName))
+	}
+
+	if policy.Capacity <= 0 || policy.Period <= 0 {
+		panic(fmt.Sprintf("invalid rate limit policy: %s", policyName))
+	}
+
+	policyHeader := fmt.Sprintf("%d;w=%d", policy.Capacity, int(policy.Period.Seconds()))
+
+	return func(c *fiber.Ctx) error {
+		key := policyName + ":" + rateLimitClientKey(c, policy.KeyBy)
+
+		decision, err := r.store.Take(c.UserContext(), key, policy.Capacity, policy.Period, time.Now())
+
+		// the limiter fails open, an unavailable store must not take the api down
+		if err != nil {
+			logger.FromContext(c.UserContext()).Error("RateLimiter error while taking a token", "policy", policyName, "error", err)
+			return c.Next()
+		}
+
+		c.Set("RateLimit-Policy", policyHeader)
+		c.Set("RateLimit-Limit", strconv.Itoa(decision.Limit))
+		c.Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		c.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(decision.Reset)))
+
+		if !decision.Allowed {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(ceilSeconds(decision.RetryAfter)))
+			return fiber.NewError(fiber.StatusTooManyRequests, "Too many requests, please try again later")
+		}
+
+		return c.Next()
+	}
+}
+
+func rateLimitClientKey(c *fiber.Ctx, keyBy string) string {
+	userCtx, _ := c.UserContext().Value("user").(*utils.UserContext)
+
+	if keyBy == RateLimitByApiKey && userCtx != nil && userCtx.IsApiKey() {
+		return "apiKey:" + userCtx.ApiKeyID
+	}
+
+	if (keyBy == RateLimitByApiKey || keyBy == RateLimitByUser) && userCtx != nil {
+		return "user:" + userCtx.UserID
+	}
+
+	return "ip:" + c.IP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
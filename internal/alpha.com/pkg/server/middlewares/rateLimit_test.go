@@ -0,0 +1,69 @@
+package middlewares
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"alpha.com/configuration"
+	"alpha.com/internal/alpha.com/pkg/server"
+	"alpha.com/internal/alpha.com/pkg/server/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// newRateLimitedApp allows one request per client, requests made with
+// app.Test come from 0.0.0.0.
+func newRateLimitedApp(trustedProxies configuration.List) *fiber.App {
+	app := fiber.New(server.WithProxies(fiber.Config{}, configuration.ServerConfig{
+		ProxyHeader:    fiber.HeaderXForwardedFor,
+		TrustedProxies: trustedProxies,
+	}))
+
+	limiter := NewRateLimiter(services.NewMemoryRateLimitStore(), map[string]RateLimitPolicy{
+		"test": {Capacity: 1, Period: time.Minute, KeyBy: RateLimitByIP},
+	})
+
+	app.Get("/", limiter.Limit("test"), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	return app
+}
+
+func statusFor(t *testing.T, app *fiber.App, forwardedFor string) int {
+	t.Helper()
+
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	req.Header.Set(fiber.HeaderXForwardedFor, forwardedFor)
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	return resp.StatusCode
+}
+
+func TestRateLimitKeysClientsBehindTrustedProxy(t *testing.T) {
+	app := newRateLimitedApp(configuration.List{"0.0.0.0/32"})
+
+	for _, client := range []string{"203.0.113.1", "203.0.113.2"} {
+		if status := statusFor(t, app, client); status != fiber.StatusNoContent {
+			t.Errorf("first request of %s returned %d, want its own bucket", client, status)
+		}
+	}
+
+	if status := statusFor(t, app, "203.0.113.1"); status != fiber.StatusTooManyRequests {
+		t.Errorf("second request of 203.0.113.1 returned %d, want %d", status, fiber.StatusTooManyRequests)
+	}
+}
+
+func TestRateLimitIgnoresForwardedForFromUntrustedPeer(t *testing.T) {
+	app := newRateLimitedApp(configuration.List{"10.0.0.0/8"})
+
+	statusFor(t, app, "203.0.113.1")
+
+	if status := statusFor(t, app, "203.0.113.2"); status != fiber.StatusTooManyRequests {
+		t.Errorf("a forwarded address from an untrusted peer returned %d, want the bucket of the peer", status)
+	}
+}
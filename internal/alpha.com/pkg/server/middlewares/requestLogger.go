@@ -0,0 +1,72 @@
+package middlewares
+
+import (
+	"log/slog"
+	"regexp"
+	"time"
+
+	"alpha.com/internal/alpha.com/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	fiberUtils "github.com/gofiber/fiber/v2/utils"
+	"github.com/google/uuid"
+)
+
+const RequestIDHeader = "X-Request-Id"
+
+// requestIDPattern limits the request ids taken over from the caller, anything
+// else is replaced so that a client can not forge log lines.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// NewRequestLogger must be the first middleware. It takes the request id from
+// X-Request-Id or generates one, echoes it in the response and puts a logger
+// carrying it, the method and the route into the user context, where
+// logger.FromContext finds it. Every request is logged once it is done.
+func NewRequestLogger(baseLogger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		requestID := c.Get(RequestIDHeader)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Set(RequestIDHeader, requestID)
+
+		requestLogger := logger.WithLazy(
+			baseLogger.With("request_id", fiberUtils.CopyString(requestID), "method", c.Method()),
+			slog.Any("route", routeValue{c: c}),
+		)
+		c.SetUserContext(logger.WithContext(c.UserContext(), requestLogger))
+
+		err := c.Next()
+
+		status := responseStatus(c, err)
+
+		level := slog.LevelInfo
+		if status >= fiber.StatusInternalServerError {
+			level = slog.LevelError
+		}
+
+		// the logger in the user context has the user id attached by then
+		logger.FromContext(c.UserContext()).Log(c.UserContext(), level, "request completed",
+			"path", c.Path(),
+			"status", status,
+			"duration_ms", time.Since(start).Milliseconds(),
+			"ip", c.IP(),
+		)
+
+		return err
+	}
+}
+
+// routeValue is resolved when a line is written, by then fiber has matched the
+// route, e.g. /api/v1/alpha/job/:id, whereas this middleware only sees "/".
+// Request loggers must therefore not be used after the request is done.
+type routeValue struct {
+	c *fiber.Ctx
+}
+
+func (r routeValue) LogValue() slog.Value {
+	return slog.StringValue(fiberUtils.CopyString(r.c.Route().Path))
+}
@@ -0,0 +1,55 @@
+package middlewares
+
+import (
+	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireRole must be registered after JwtMiddleware. It lets the request
+// through when the authenticated user holds at least one of the given roles.
+func RequireRole(roles ...domain.Role) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userCtx, ok := c.UserContext().Value("user").(*utils.UserContext)
+		if !ok {
+			return fiber.NewError(fiber.StatusUnauthorized, "Missing or malformed JWT")
+		}
+
+		for _, userRole := range domain.RolesFromStrings(userCtx.Roles) {
+			for _, role := range roles {
+				if userRole == role {
+					return c.Next()
+				}
+			}
+		}
+
+		return fiber.NewError(fiber.StatusForbidden, "You do not have the required role to access this resource")
+	}
+}
+
+// RequirePermission must be registered after JwtMiddleware or AuthMiddleware.
+// It lets the request through when the roles of the authenticated user grant
+// all given permissions. Requests made with an API key carry no roles, they
+// are left to RequireScope.
+func RequirePermission(permissions ...domain.Permission) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userCtx, ok := c.UserContext().Value("user").(*utils.UserContext)
+		if !ok {
+			return fiber.NewError(fiber.StatusUnauthorized, "Missing or malformed JWT")
+		}
+
+		if userCtx.IsApiKey() {
+			return c.Next()
+		}
+
+		userRoles := domain.RolesFromStrings(userCtx.Roles)
+
+		for _, permission := range permissions {
+			if !domain.HasPermission(userRoles, permission) {
+				return fiber.NewError(fiber.StatusForbidden, "You do not have the required permission to access this resource")
+			}
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,49 @@
+package middlewares
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestRequirePermission(t *testing.T) {
+	tests := []struct {
+		name    string
+		userCtx *utils.UserContext
+		status  int
+	}{
+		{"employer", &utils.UserContext{UserID: "user-1", Roles: []string{"employer"}}, fiber.StatusNoContent},
+		{"candidate", &utils.UserContext{UserID: "user-1", Roles: []string{"candidate"}}, fiber.StatusForbidden},
+		{"no role", &utils.UserContext{UserID: "user-1"}, fiber.StatusForbidden},
+		{"api key left to its scopes", &utils.UserContext{UserID: "user-1", ApiKeyID: "key-1"}, fiber.StatusNoContent},
+		{"unauthenticated", nil, fiber.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			app := fiber.New()
+
+			app.Post("/job", func(c *fiber.Ctx) error {
+				if test.userCtx != nil {
+					c.SetUserContext(context.WithValue(c.UserContext(), "user", test.userCtx))
+				}
+				return c.Next()
+			}, RequirePermission(domain.PermissionJobsWrite), func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusNoContent)
+			})
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodPost, "/job", nil))
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+
+			if resp.StatusCode != test.status {
+				t.Errorf("RequirePermission returned %d, want %d", resp.StatusCode, test.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"alpha.com/internal/alpha.com/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// NewTimeout puts the deadline of the request into its user context, the
+// repositories hand it to the driver so the queries still running are
+// cancelled once it passes. Handlers are not interrupted; an error they return
+// after the deadline is reported as context.DeadlineExceeded, answered with
+// 504 whatever the controller made of it.
+func NewTimeout(timeout time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(c.UserContext(), timeout)
+		defer cancel()
+
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			logger.FromContext(ctx).Warn("TimeoutMiddleware request deadline exceeded", "timeout_ms", timeout.Milliseconds(), "error", err)
+
+			if !errors.Is(err, context.DeadlineExceeded) {
+				return fmt.Errorf("%w: %v", context.DeadlineExceeded, err)
+			}
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,54 @@
+package middlewares
+
+import (
+	"strconv"
+
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"alpha.com/internal/alpha.com/pkg/tracing"
+
+	"github.com/gofiber/fiber/v2"
+	fiberUtils "github.com/gofiber/fiber/v2/utils"
+)
+
+// NewTracing opens the server span of every request, continuing the trace of
+// the caller when a valid traceparent header is sent. It comes right after
+// the request logger so that every line of the request carries trace_id and
+// span_id, and echoes traceparent so callers can look the trace up.
+func NewTracing() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+		if remote, ok := tracing.ParseTraceparent(c.Get(tracing.TraceparentHeader)); ok {
+			ctx = tracing.ContextWithRemoteSpanContext(ctx, remote)
+		}
+
+		ctx, span := tracing.StartKind(ctx, "HTTP", tracing.SpanKindServer)
+		defer span.End()
+
+		spanContext := span.SpanContext()
+		ctx = logger.With(ctx, "trace_id", spanContext.TraceID.String(), "span_id", spanContext.SpanID.String())
+		c.SetUserContext(ctx)
+		c.Set(tracing.TraceparentHeader, spanContext.Traceparent())
+
+		err := c.Next()
+
+		status := responseStatus(c, err)
+		route := fiberUtils.CopyString(c.Route().Path)
+		if status == fiber.StatusNotFound && route == "/" {
+			route = unmatchedRoute
+		}
+
+		method := fiberUtils.CopyString(c.Method())
+		span.SetName(method + " " + route)
+		span.SetAttribute("http.method", method)
+		span.SetAttribute("http.route", route)
+		span.SetAttribute("http.target", fiberUtils.CopyString(c.Path()))
+		span.SetAttribute("http.status_code", status)
+
+		if status >= fiber.StatusInternalServerError {
+			span.SetError(strconv.Itoa(status) + " " + fiberUtils.StatusMessage(status))
+			span.RecordError(err)
+		}
+
+		return err
+	}
+}
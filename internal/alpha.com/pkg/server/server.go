@@ -3,55 +3,111 @@ package server
 import (
 	"context"
 	"fmt"
-	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
+	"log/slog"
 	"time"
 
 	"alpha.com/configuration"
-	"alpha.com/internal/alpha.com/pkg/mongodb"
+	"alpha.com/internal/alpha.com/pkg/health"
 	"github.com/gofiber/fiber/v2"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type server struct {
-	app *fiber.App
+	app          *fiber.App
+	metricsApp   *fiber.App
+	serverConfig configuration.ServerConfig
+	readiness    *health.Readiness
 }
 
-func NewServer(app *fiber.App) *server {
+func NewServer(app *fiber.App, metricsApp *fiber.App, serverConfig configuration.ServerConfig, readiness *health.Readiness) *server {
 	return &server{
-		app: app,
+		app:          app,
+		metricsApp:   metricsApp,
+		serverConfig: serverConfig,
+		readiness:    readiness,
 	}
 }
 
-func (s *server) StartHttpServer(mongoClient *mongo.Client) {
+// WithProxies sets how fiber reads the client address for ctx.IP(), from the
+// proxy header on requests whose peer is a trusted proxy and from the peer
+// otherwise. Rate limits, login lockouts and logs all key on that address.
+func WithProxies(config fiber.Config, serverConfig configuration.ServerConfig) fiber.Config {
+	if serverConfig.ProxyHeader == "" {
+		return config
+	}
+
+	config.ProxyHeader = serverConfig.ProxyHeader
+	config.EnableTrustedProxyCheck = true
+	config.TrustedProxies = serverConfig.TrustedProxies
+	config.EnableIPValidation = true
+
+	return config
+}
+
+// StartHttpServer serves app on Port and metricsApp on MetricsPort until ctx
+// is done, on SIGINT or SIGTERM, and then shuts the lifecycle down, the HTTP
+// server first and the metrics server once it has drained, within
+// ShutdownTimeout. It returns ErrForcedShutdown when the deadline was
+// exceeded.
+func (s *server) StartHttpServer(ctx context.Context, lifecycle *Lifecycle) error {
+	listenErr := make(chan error, 2)
+
 	go func() {
-		gracefulShutdown(s.app, mongoClient)
+		listenErr <- s.metricsApp.Listen(fmt.Sprintf(":%s", s.serverConfig.MetricsPort))
 	}()
 
-	if err := s.app.Listen(fmt.Sprintf(":%s", configuration.Port)); err != nil && err != http.ErrServerClosed {
-		fmt.Printf("Cannot start server - ERROR: %v\n", err)
-		panic("cannot start server")
+	lifecycle.Register("metrics", s.metricsApp.ShutdownWithContext)
+
+	go func() {
+		listenErr <- s.app.Listen(fmt.Sprintf(":%s", s.serverConfig.Port))
+	}()
+
+	lifecycle.Register("http", s.shutdown)
+
+	var serveErr error
+
+	select {
+	case <-ctx.Done():
+		slog.Info("Shutdown Server")
+	case serveErr = <-listenErr:
+		slog.Error("Cannot start server", "error", serveErr)
+	}
+
+	shutdownContext, cancel := context.WithTimeout(context.Background(), s.serverConfig.ShutdownTimeout.Duration())
+	defer cancel()
+
+	err := lifecycle.Shutdown(shutdownContext)
+
+	slog.Info("Server exiting")
+
+	if serveErr != nil {
+		return serveErr
 	}
+
+	return err
 }
 
-func gracefulShutdown(app *fiber.App, mongoClient *mongo.Client) {
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-	fmt.Println("Shutdown Server")
+// shutdown reports the replica as not ready first and keeps serving for
+// ShutdownDelay, long enough for the readiness probe to notice and the load
+// balancer to stop sending new requests, then waits for the requests in
+// flight.
+func (s *server) shutdown(ctx context.Context) error {
+	s.readiness.ShuttingDown()
 
-	_, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-	defer cancel()
+	if delay := s.serverConfig.ShutdownDelay.Duration(); delay > 0 {
+		slog.Info("Waiting for the load balancer to stop routing requests", "delay", delay)
 
-	if err := app.Shutdown(); err != nil {
-		fmt.Printf("Server Shutdown Error: %v\n", err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 	}
 
-	fmt.Println("Fiber server gracefully stopped.")
+	if err := s.app.ShutdownWithContext(ctx); err != nil {
+		return err
+	}
 
-	mongodb.DisconnectMongoDB(mongoClient)
+	slog.Info("Fiber server gracefully stopped")
 
-	fmt.Println("Server exiting")
+	return nil
 }
@@ -1,10 +1,9 @@
 package services
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
-	"fmt"
-	"strconv"
-	"strings"
 	"time"
 
 	"alpha.com/configuration"
@@ -12,33 +11,66 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+	TokenTypeMfa     = "mfa"
+)
+
+// Token validation errors. Their messages are returned to clients as the
+// reason of a 401 response.
+var (
+	ErrTokenMalformed        = errors.New("token is malformed")
+	ErrTokenSignatureInvalid = errors.New("token signature is invalid")
+	ErrTokenUnknownKey       = errors.New("token is signed with an unknown key")
+	ErrTokenAlgorithm        = errors.New("token is signed with an unexpected algorithm")
+	ErrTokenExpired          = errors.New("token has expired")
+	ErrTokenNotYetValid      = errors.New("token is not valid yet")
+	ErrTokenIssuer           = errors.New("token issuer is not accepted")
+	ErrTokenAudience         = errors.New("token audience is not accepted")
+	ErrTokenType             = errors.New("token type is not accepted for this operation")
+	ErrTokenSubject          = errors.New("token has no subject")
+)
+
 type IJwtService interface {
-	CreateTokens(userID string) (string, string, error)
+	CreateTokens(userID, sessionID string, roles []string) (string, string, error)
+	ParseAccessToken(accessToken string) (*Claims, error)
 	ParseRefreshToken(refresh string) (string, error)
-	CreateAccessToken(userID string) (string, error)
+	CreateAccessToken(userID, sessionID string, roles []string) (string, error)
 	CreateRefreshToken(userID string) (string, error)
+	CreateMfaChallengeToken(userID string) (string, error)
+	ParseMfaChallengeToken(challenge string) (string, error)
+	RefreshTokenTTL() time.Duration
+	HashToken(token string) string
 }
 
 type jwtService struct {
+	keyRing               IKeyRing
+	jwtConfig             configuration.JwtConfig
+	mfaChallengeTokenTime time.Duration
 }
 
-func NewJwtService() IJwtService {
-	return &jwtService{}
+// NewJwtService signs tokens with the active key of the ring so that other
+// services can verify access tokens through the published JWKS.
+func NewJwtService(keyRing IKeyRing, jwtConfig configuration.JwtConfig, mfaChallengeTokenTime time.Duration) IJwtService {
+	return &jwtService{
+		keyRing:               keyRing,
+		jwtConfig:             jwtConfig,
+		mfaChallengeTokenTime: mfaChallengeTokenTime,
+	}
 }
 
-var JWTSecret = []byte(configuration.JWT_SECRET)
-var RefreshSecret = []byte(configuration.REFRESH_SECRET)
-
-var AccessTokenTime = configuration.ACCESS_TOKEN_TIME
-var RefreshTokenTime = configuration.REFRESH_TOKEN_TIME
-
+// Claims are the registered claims plus typ, which keeps access and refresh
+// tokens from being used in place of each other.
 type Claims struct {
-	UserID string `json:"userID"`
+	TokenType string   `json:"typ"`
+	SessionID string   `json:"sid,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
 	jwt.StandardClaims
 }
 
-func (j *jwtService) CreateTokens(userID string) (string, string, error) {
-	accessToken, err := j.CreateAccessToken(userID)
+func (j *jwtService) CreateTokens(userID, sessionID string, roles []string) (string, string, error) {
+	accessToken, err := j.CreateAccessToken(userID, sessionID, roles)
 
 	if err != nil {
 		return "", "", err
@@ -53,96 +85,168 @@ func (j *jwtService) CreateTokens(userID string) (string, string, error) {
 	return accessToken, refreshTokenString, nil
 }
 
+func (j *jwtService) ParseAccessToken(accessToken string) (*Claims, error) {
+	return j.parse(accessToken, TokenTypeAccess)
+}
+
 func (j *jwtService) ParseRefreshToken(refresh string) (string, error) {
-	token, err := jwt.Parse(refresh, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return RefreshSecret, nil
-	})
+	claims, err := j.parse(refresh, TokenTypeRefresh)
 
 	if err != nil {
-		return "", errors.New("invalid or expired JWT")
+		return "", err
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		userID := claims["userID"].(string)
-		return userID, nil
+	return claims.Subject, nil
+}
+
+func (j *jwtService) CreateAccessToken(userID, sessionID string, roles []string) (string, error) {
+	claims := &Claims{
+		TokenType:      TokenTypeAccess,
+		SessionID:      sessionID,
+		Roles:          roles,
+		StandardClaims: j.standardClaims(userID, j.jwtConfig.AccessTokenTime.Duration()),
 	}
 
-	return "", errors.New("invalid or expired JWT")
+	return j.sign(claims)
 }
 
-func (j *jwtService) CreateAccessToken(userID string) (string, error) {
-	expirationDuration, err := parseDuration(AccessTokenTime)
-	if err != nil {
-		return "", err
+func (j *jwtService) CreateRefreshToken(userID string) (string, error) {
+	refreshClaims := &Claims{
+		TokenType:      TokenTypeRefresh,
+		StandardClaims: j.standardClaims(userID, j.jwtConfig.RefreshTokenTime.Duration()),
 	}
 
-	expirationTime := time.Now().Add(expirationDuration)
-	claims := &Claims{
-		UserID: userID,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: expirationTime.Unix(),
-		},
-	}
+	return j.sign(refreshClaims)
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	accessToken, err := token.SignedString(JWTSecret)
-	if err != nil {
-		return "", err
+// CreateMfaChallengeToken proves that the password was checked. It is only
+// accepted by the MFA verification endpoint, never as an access token.
+func (j *jwtService) CreateMfaChallengeToken(userID string) (string, error) {
+	challengeClaims := &Claims{
+		TokenType:      TokenTypeMfa,
+		StandardClaims: j.standardClaims(userID, j.mfaChallengeTokenTime),
 	}
 
-	return accessToken, nil
+	return j.sign(challengeClaims)
 }
 
-func (j *jwtService) CreateRefreshToken(userID string) (string, error) {
-	expirationDuration, err := parseDuration(RefreshTokenTime)
+func (j *jwtService) ParseMfaChallengeToken(challenge string) (string, error) {
+	claims, err := j.parse(challenge, TokenTypeMfa)
+
 	if err != nil {
 		return "", err
 	}
 
-	refreshExpirationTime := time.Now().Add(expirationDuration)
-	refreshClaims := &Claims{
-		UserID: userID,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: refreshExpirationTime.Unix(),
-			Id:        uuid.New().String(),
-		},
+	return claims.Subject, nil
+}
+
+func (j *jwtService) standardClaims(userID string, expirationDuration time.Duration) jwt.StandardClaims {
+	now := time.Now()
+
+	return jwt.StandardClaims{
+		Subject:   userID,
+		Issuer:    j.jwtConfig.Issuer,
+		Audience:  j.jwtConfig.Audience,
+		IssuedAt:  now.Unix(),
+		NotBefore: now.Unix(),
+		ExpiresAt: now.Add(expirationDuration).Unix(),
+		Id:        uuid.New().String(),
 	}
+}
 
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	refreshTokenString, err := refreshToken.SignedString(RefreshSecret)
+func (j *jwtService) sign(claims *Claims) (string, error) {
+	signingKey, err := j.keyRing.SigningKey()
 	if err != nil {
 		return "", err
 	}
 
-	return refreshTokenString, nil
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = signingKey.ID
+
+	return token.SignedString(signingKey.PrivateKey)
 }
 
-func parseDuration(durationStr string) (time.Duration, error) {
-	var duration time.Duration
+func (j *jwtService) parse(tokenString, tokenType string) (*Claims, error) {
+	claims := &Claims{}
+
+	// claims are validated below with leeway, the library has none in v3
+	parser := &jwt.Parser{SkipClaimsValidation: true}
+
+	_, err := parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodRS256 {
+			return nil, ErrTokenAlgorithm
+		}
+
+		kid, _ := token.Header["kid"].(string)
+
+		publicKey, ok := j.keyRing.PublicKey(kid)
+		if !ok {
+			return nil, ErrTokenUnknownKey
+		}
 
-	numStr := durationStr[:len(durationStr)-1]
-	unitStr := strings.ToLower(string(durationStr[len(durationStr)-1]))
+		return publicKey, nil
+	})
 
-	num, err := strconv.Atoi(numStr)
 	if err != nil {
-		return duration, fmt.Errorf("invalid duration format: %v", err)
+		var validationError *jwt.ValidationError
+		if !errors.As(err, &validationError) {
+			return nil, ErrTokenMalformed
+		}
+
+		switch {
+		case validationError.Inner == ErrTokenAlgorithm || validationError.Inner == ErrTokenUnknownKey:
+			return nil, validationError.Inner
+		case validationError.Errors&jwt.ValidationErrorSignatureInvalid != 0:
+			return nil, ErrTokenSignatureInvalid
+		default:
+			return nil, ErrTokenMalformed
+		}
+	}
+
+	if err := j.validateClaims(claims, tokenType, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (j *jwtService) validateClaims(claims *Claims, tokenType string, now time.Time) error {
+	leeway := j.jwtConfig.ClockSkew.Duration()
+
+	if claims.TokenType != tokenType {
+		return ErrTokenType
+	}
+
+	if claims.Issuer != j.jwtConfig.Issuer {
+		return ErrTokenIssuer
+	}
+
+	if claims.Audience != j.jwtConfig.Audience {
+		return ErrTokenAudience
 	}
 
-	switch unitStr {
-	case "s":
-		duration = time.Duration(num) * time.Second
-	case "m":
-		duration = time.Duration(num) * time.Minute
-	case "h":
-		duration = time.Duration(num) * time.Hour
-	case "d":
-		duration = time.Duration(num) * 24 * time.Hour
-	default:
-		return duration, fmt.Errorf("unknown duration unit: %v", unitStr)
+	if claims.Subject == "" {
+		return ErrTokenSubject
 	}
 
-	return duration, nil
+	if claims.ExpiresAt == 0 || now.Add(-leeway).Unix() > claims.ExpiresAt {
+		return ErrTokenExpired
+	}
+
+	if now.Add(leeway).Unix() < claims.NotBefore || now.Add(leeway).Unix() < claims.IssuedAt {
+		return ErrTokenNotYetValid
+	}
+
+	return nil
+}
+
+func (j *jwtService) RefreshTokenTTL() time.Duration {
+	return j.jwtConfig.RefreshTokenTime.Duration()
+}
+
+// HashToken returns the value stored in place of a refresh token so that a
+// database leak does not hand out usable tokens.
+func (j *jwtService) HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
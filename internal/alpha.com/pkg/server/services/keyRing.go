@@ -0,0 +1,446 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+const (
+	signingKeyBits = 2048
+	// activatedAtHeader is the PEM header holding the activation time, the file
+	// times change when a key directory is copied or restored.
+	activatedAtHeader = "Activated-At"
+)
+
+// JWKSMaxAge is how long verifiers may cache the published key set.
+const JWKSMaxAge = 5 * time.Minute
+
+// ErrNoSigningKey is returned by NewKeyRing for an empty key directory when it
+// may not generate the first key.
+var ErrNoSigningKey = errors.New("no signing key found, run alpha rotate-keys")
+
+// SigningKey is one entry of the key ring. Only the active key and a key
+// waiting for its ActivatedAt have a private part, retired keys are kept as
+// public keys to verify tokens that were signed before a rotation.
+type SigningKey struct {
+	ID          string
+	PrivateKey  *rsa.PrivateKey
+	PublicKey   *rsa.PublicKey
+	ActivatedAt time.Time
+}
+
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+type IKeyRing interface {
+	SigningKey() (*SigningKey, error)
+	LatestKey() (*SigningKey, error)
+	PublicKey(kid string) (*rsa.PublicKey, bool)
+	JWKS() JSONWebKeySet
+	Reload() error
+	Rotate() error
+	Prune() error
+	StartReload(ctx context.Context, reloadInterval time.Duration)
+}
+
+// keyRing loads RSA keys from PEM files in a directory. The file name without
+// extension is the kid and the newest private key whose ActivatedAt has passed
+// signs new tokens. Replicas share the directory and pick up new keys on
+// reload, the directory has one writer only, the rotate-keys command, so that
+// every replica signs with the key the others verify.
+type keyRing struct {
+	dir             string
+	retention       time.Duration
+	activationDelay time.Duration
+	mutex           sync.RWMutex
+	keys            map[string]*SigningKey
+	// signing holds the keys with a private part, newest first, the keys
+	// still waiting for their activation and the active key
+	signing []*SigningKey
+	// retiredPrivate are the retired keys whose file still has the private
+	// part, Prune rewrites them
+	retiredPrivate []*SigningKey
+}
+
+// NewKeyRing loads the keys in dir. An empty directory gets a first key when
+// generate is set, for a single local instance, and is ErrNoSigningKey
+// otherwise. A rotated key only signs once activationDelay has passed, every
+// replica and every verifier caching the key set must know it by then.
+// Retired keys are pruned once retention has passed since they were replaced,
+// which must be longer than any token signed with them can live.
+func NewKeyRing(dir string, retention time.Duration, activationDelay time.Duration, generate bool) (IKeyRing, error) {
+	k := &keyRing{
+		dir:             dir,
+		retention:       retention,
+		activationDelay: activationDelay,
+		keys:            make(map[string]*SigningKey),
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("key directory could not be created: %v", err)
+	}
+
+	if err := k.Reload(); err != nil {
+		return nil, err
+	}
+
+	if len(k.signing) == 0 {
+		if !generate {
+			return nil, fmt.Errorf("%s: %w", dir, ErrNoSigningKey)
+		}
+
+		slog.Info("keyRing no signing key found, generating one", "dir", dir)
+
+		if err := k.Rotate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return k, nil
+}
+
+// SigningKey is the key new tokens are signed with, the newest key that has
+// been activated. A key becomes active at its ActivatedAt even between two
+// reloads.
+func (k *keyRing) SigningKey() (*SigningKey, error) {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+
+	now := time.Now()
+
+	for _, key := range k.signing {
+		if !key.ActivatedAt.After(now) {
+			return key, nil
+		}
+	}
+
+	return nil, errors.New("no active signing key")
+}
+
+// LatestKey is the key written by the last rotation, it may not sign yet.
+func (k *keyRing) LatestKey() (*SigningKey, error) {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+
+	if len(k.signing) == 0 {
+		return nil, errors.New("no signing key")
+	}
+
+	return k.signing[0], nil
+}
+
+func (k *keyRing) PublicKey(kid string) (*rsa.PublicKey, bool) {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, false
+	}
+
+	return key.PublicKey, true
+}
+
+func (k *keyRing) JWKS() JSONWebKeySet {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+
+	set := JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(k.keys))}
+
+	for _, key := range k.sortedKeys() {
+		set.Keys = append(set.Keys, JSONWebKey{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: jwt.SigningMethodRS256.Alg(),
+			Kid: key.ID,
+			N:   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+		})
+	}
+
+	return set
+}
+
+func (k *keyRing) Reload() error {
+	paths, err := filepath.Glob(filepath.Join(k.dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]*SigningKey, len(paths))
+
+	for _, path := range paths {
+		key, err := loadSigningKey(path)
+		if err != nil {
+			return fmt.Errorf("key %s could not be loaded: %v", path, err)
+		}
+
+		keys[key.ID] = key
+	}
+
+	newestFirst := make([]*SigningKey, 0, len(keys))
+	for _, key := range keys {
+		newestFirst = append(newestFirst, key)
+	}
+
+	sort.Slice(newestFirst, func(i, j int) bool {
+		return newestFirst[i].ActivatedAt.After(newestFirst[j].ActivatedAt)
+	})
+
+	// keys older than the active key are retired, they only verify even when
+	// their file still has the private part
+	now := time.Now()
+	active := false
+	signing := make([]*SigningKey, 0, 2)
+	retiredPrivate := make([]*SigningKey, 0)
+
+	for _, key := range newestFirst {
+		if key.PrivateKey == nil {
+			continue
+		}
+
+		if active {
+			key.PrivateKey = nil
+			retiredPrivate = append(retiredPrivate, key)
+			continue
+		}
+
+		signing = append(signing, key)
+		active = !key.ActivatedAt.After(now)
+	}
+
+	k.mutex.Lock()
+	k.keys = keys
+	k.signing = signing
+	k.retiredPrivate = retiredPrivate
+	k.mutex.Unlock()
+
+	return nil
+}
+
+// Rotate generates a new key that signs once the activation delay has passed,
+// published meanwhile so that replicas and verifiers know it before the first
+// token signed with it. The first key of a ring is active at once. Tokens
+// signed with the previous key stay valid because its public key remains in
+// the ring.
+func (k *keyRing) Rotate() error {
+	privateKey, err := rsa.GenerateKey(rand.Reader, signingKeyBits)
+	if err != nil {
+		return err
+	}
+
+	activatedAt := time.Now()
+	if _, err := k.SigningKey(); err == nil {
+		activatedAt = activatedAt.Add(k.activationDelay)
+	}
+
+	kid := keyThumbprint(&privateKey.PublicKey)
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}
+
+	if err := k.writeKey(kid, block, activatedAt); err != nil {
+		return err
+	}
+
+	slog.Info("keyRing rotated signing key", "kid", kid, "activated_at", activatedAt)
+
+	if err := k.Reload(); err != nil {
+		return err
+	}
+
+	return k.Prune()
+}
+
+// Prune rewrites the retired keys without their private part and removes the
+// keys that were retired longer than the retention period ago. A key is
+// retired once the next key has been activated, which happens after Rotate
+// returned, so the rotate-keys job prunes on every run.
+func (k *keyRing) Prune() error {
+	k.mutex.RLock()
+	retiredPrivate := k.retiredPrivate
+	k.mutex.RUnlock()
+
+	for _, key := range retiredPrivate {
+		publicKey, err := x509.MarshalPKIXPublicKey(key.PublicKey)
+		if err != nil {
+			return err
+		}
+
+		if err := k.writeKey(key.ID, &pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}, key.ActivatedAt); err != nil {
+			return err
+		}
+
+		slog.Info("keyRing dropped the private part of a retired key", "kid", key.ID)
+	}
+
+	k.prune()
+
+	return nil
+}
+
+// writeKey replaces the file of kid at once, replicas reloading meanwhile read
+// either the old or the new file.
+func (k *keyRing) writeKey(kid string, block *pem.Block, activatedAt time.Time) error {
+	block.Headers = map[string]string{activatedAtHeader: activatedAt.UTC().Format(time.RFC3339Nano)}
+
+	path := filepath.Join(k.dir, kid+".pem")
+
+	if err := os.WriteFile(path+".tmp", pem.EncodeToMemory(block), 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(path+".tmp", path)
+}
+
+func (k *keyRing) prune() {
+	k.mutex.RLock()
+	keys := k.sortedKeys()
+	k.mutex.RUnlock()
+
+	// a key is retired when the next newer key was activated, a key waiting
+	// for its activation has not retired any yet
+	for i := 0; i+1 < len(keys); i++ {
+		if time.Since(keys[i+1].ActivatedAt) > k.retention {
+			path := filepath.Join(k.dir, keys[i].ID+".pem")
+
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				slog.Error("keyRing retired key could not be removed", "kid", keys[i].ID, "error", err)
+				continue
+			}
+
+			slog.Info("keyRing removed retired key", "kid", keys[i].ID)
+		}
+	}
+
+	if err := k.Reload(); err != nil {
+		slog.Error("keyRing keys could not be reloaded", "error", err)
+	}
+}
+
+// StartReload reloads the ring from disk every reloadInterval, which picks up
+// the keys rotate-keys writes. The activation delay must be longer than
+// reloadInterval. It returns when ctx is done.
+func (k *keyRing) StartReload(ctx context.Context, reloadInterval time.Duration) {
+	ticker := time.NewTicker(reloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := k.Reload(); err != nil {
+				slog.Error("keyRing keys could not be reloaded", "error", err)
+			}
+		}
+	}
+}
+
+func (k *keyRing) sortedKeys() []*SigningKey {
+	keys := make([]*SigningKey, 0, len(k.keys))
+	for _, key := range k.keys {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].ActivatedAt.Before(keys[j].ActivatedAt)
+	})
+
+	return keys
+}
+
+func loadSigningKey(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	activatedAt, err := keyActivatedAt(path, block)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &SigningKey{
+		ID:          strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		ActivatedAt: activatedAt,
+	}
+
+	if strings.Contains(block.Type, "PRIVATE KEY") {
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+		if err != nil {
+			return nil, err
+		}
+
+		key.PrivateKey = privateKey
+		key.PublicKey = &privateKey.PublicKey
+
+		return key, nil
+	}
+
+	publicKey, err := jwt.ParseRSAPublicKeyFromPEM(data)
+	if err != nil {
+		return nil, err
+	}
+
+	key.PublicKey = publicKey
+
+	return key, nil
+}
+
+// keyActivatedAt reads the activation time from the PEM header. Keys written
+// before the header existed fall back to the file time.
+func keyActivatedAt(path string, block *pem.Block) (time.Time, error) {
+	if value, ok := block.Headers[activatedAtHeader]; ok {
+		return time.Parse(time.RFC3339Nano, value)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return info.ModTime(), nil
+}
+
+// keyThumbprint is the RFC 7638 thumbprint of the key, a kid that cannot
+// collide however often keys are rotated.
+func keyThumbprint(publicKey *rsa.PublicKey) string {
+	members := fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`,
+		base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()))
+
+	sum := sha256.Sum256([]byte(members))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,119 @@
+package services_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"alpha.com/internal/alpha.com/pkg/server/services"
+)
+
+func TestKeyRingRefusesEmptyDirectoryWithoutGenerate(t *testing.T) {
+	_, err := services.NewKeyRing(t.TempDir(), time.Hour, 0, false)
+	if !errors.Is(err, services.ErrNoSigningKey) {
+		t.Errorf("NewKeyRing returned %v, want ErrNoSigningKey", err)
+	}
+}
+
+func TestKeyRingRotate(t *testing.T) {
+	dir := t.TempDir()
+
+	writer, err := services.NewKeyRing(dir, time.Hour, 0, true)
+	if err != nil {
+		t.Fatalf("NewKeyRing returned %v", err)
+	}
+
+	first, _ := writer.SigningKey()
+
+	if err := writer.Rotate(); err != nil {
+		t.Fatalf("Rotate returned %v", err)
+	}
+
+	second, _ := writer.SigningKey()
+
+	if second.ID == first.ID || !second.ActivatedAt.After(first.ActivatedAt) {
+		t.Fatalf("Rotate activated %q at %v after %q at %v", second.ID, second.ActivatedAt, first.ID, first.ActivatedAt)
+	}
+
+	// a replica loading the shared directory signs with the same key
+	replica, err := services.NewKeyRing(dir, time.Hour, 0, false)
+	if err != nil {
+		t.Fatalf("NewKeyRing returned %v", err)
+	}
+
+	signingKey, _ := replica.SigningKey()
+	if signingKey.ID != second.ID || !signingKey.ActivatedAt.Equal(second.ActivatedAt) {
+		t.Errorf("replica signs with %q activated at %v, want %q at %v", signingKey.ID, signingKey.ActivatedAt, second.ID, second.ActivatedAt)
+	}
+
+	if _, ok := replica.PublicKey(first.ID); !ok {
+		t.Errorf("retired key %q no longer verifies", first.ID)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, first.ID+".pem"))
+	if err != nil {
+		t.Fatalf("retired key could not be read: %v", err)
+	}
+
+	if strings.Contains(string(data), "PRIVATE KEY") {
+		t.Errorf("retired key %q was kept with its private part", first.ID)
+	}
+}
+
+func TestKeyRingPublishesRotatedKeyBeforeSigning(t *testing.T) {
+	dir := t.TempDir()
+	activationDelay := 200 * time.Millisecond
+
+	writer, err := services.NewKeyRing(dir, time.Hour, activationDelay, true)
+	if err != nil {
+		t.Fatalf("NewKeyRing returned %v", err)
+	}
+
+	first, _ := writer.SigningKey()
+
+	if err := writer.Rotate(); err != nil {
+		t.Fatalf("Rotate returned %v", err)
+	}
+
+	// replicas load the new key before it signs
+	replica, err := services.NewKeyRing(dir, time.Hour, activationDelay, false)
+	if err != nil {
+		t.Fatalf("NewKeyRing returned %v", err)
+	}
+
+	next, _ := replica.LatestKey()
+	if next.ID == first.ID {
+		t.Fatalf("Rotate did not write a new key")
+	}
+
+	if signingKey, _ := replica.SigningKey(); signingKey.ID != first.ID {
+		t.Errorf("replica signs with %q before its activation, want %q", signingKey.ID, first.ID)
+	}
+
+	if _, ok := replica.PublicKey(next.ID); !ok {
+		t.Errorf("key %q is not published before its activation", next.ID)
+	}
+
+	published := false
+	for _, key := range replica.JWKS().Keys {
+		published = published || key.Kid == next.ID
+	}
+
+	if !published {
+		t.Errorf("key %q is missing from the key set", next.ID)
+	}
+
+	// the activation does not wait for the next reload
+	time.Sleep(activationDelay)
+
+	if signingKey, _ := replica.SigningKey(); signingKey.ID != next.ID {
+		t.Errorf("replica signs with %q after the activation, want %q", signingKey.ID, next.ID)
+	}
+
+	if _, ok := replica.PublicKey(first.ID); !ok {
+		t.Errorf("retired key %q no longer verifies", first.ID)
+	}
+}
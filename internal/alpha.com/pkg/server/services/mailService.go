@@ -0,0 +1,62 @@
+package services
+
+import (
+	"log/slog"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"alpha.com/configuration"
+)
+
+type IMailService interface {
+	Send(to, subject, body string) error
+}
+
+type mailService struct {
+	addr     string
+	username string
+	password string
+	from     string
+}
+
+// NewMailService sends plain text mails through the SMTP server of the mail
+// config. Without an SMTP server configured the mails are printed instead,
+// which is enough locally.
+func NewMailService(mailConfig configuration.MailConfig) IMailService {
+	return &mailService{
+		addr:     mailConfig.SmtpAddr,
+		username: mailConfig.SmtpUsername,
+		password: mailConfig.SmtpPassword,
+		from:     mailConfig.From,
+	}
+}
+
+func (s *mailService) Send(to, subject, body string) error {
+	if s.addr == "" {
+		slog.Info("mailService.Send smtp is not configured, mail logged instead", "to", to, "subject", subject)
+		slog.Debug("mailService.Send mail body", "body", body)
+		return nil
+	}
+
+	var auth smtp.Auth
+	if s.username != "" {
+		host, _, err := net.SplitHostPort(s.addr)
+		if err != nil {
+			return err
+		}
+
+		auth = smtp.PlainAuth("", s.username, s.password, host)
+	}
+
+	message := strings.Join([]string{
+		"From: " + s.from,
+		"To: " + to,
+		"Subject: " + subject,
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+
+	return smtp.SendMail(s.addr, auth, s.from, []string{to}, []byte(message))
+}
@@ -0,0 +1,425 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"alpha.com/internal/alpha.com/pkg/logger"
+
+	"github.com/dgrijalva/jwt-go"
+	"golang.org/x/sync/singleflight"
+)
+
+var (
+	ErrOidcDiscovery    = errors.New("identity provider configuration could not be loaded")
+	ErrOidcExchange     = errors.New("authorization code could not be exchanged")
+	ErrOidcInvalidToken = errors.New("identity provider returned an invalid id token")
+)
+
+// OidcConfig describes one OpenID Connect provider. Everything else is read
+// from the provider's discovery document.
+type OidcConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	ClockSkew    time.Duration
+}
+
+// OidcIdentity is the verified content of an ID token.
+type OidcIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type IOidcClient interface {
+	AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error)
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*OidcIdentity, error)
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+// oidcClient caches the discovery document and the key set. They are fetched
+// outside of mutex, which only guards the swap, and concurrent logins that
+// miss the cache wait for the one fetch in flight.
+type oidcClient struct {
+	config     OidcConfig
+	httpClient *http.Client
+	fetches    singleflight.Group
+	mutex      sync.RWMutex
+	discovery  *oidcDiscovery
+	keys       map[string]*rsa.PublicKey
+}
+
+// NewOidcClient runs the authorization code flow with PKCE against the
+// provider at config.IssuerURL. The http client is a parameter so that the
+// flow can be run against a local stub provider.
+func NewOidcClient(config OidcConfig, httpClient *http.Client) IOidcClient {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "email", "profile"}
+	}
+
+	return &oidcClient{
+		config:     config,
+		httpClient: httpClient,
+		keys:       make(map[string]*rsa.PublicKey),
+	}
+}
+
+// NewPkceVerifier returns a random code verifier, RFC 7636 section 4.1.
+func NewPkceVerifier() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func pkceChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func (c *oidcClient) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	discovery, err := c.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", c.config.ClientID)
+	query.Set("redirect_uri", c.config.RedirectURL)
+	query.Set("scope", strings.Join(c.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", pkceChallenge(codeVerifier))
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return discovery.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange redeems the code and returns the identity from the verified ID
+// token. The nonce must be the one sent with the authorization request.
+func (c *oidcClient) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*OidcIdentity, error) {
+	discovery, err := c.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", c.config.RedirectURL)
+	form.Set("client_id", c.config.ClientID)
+	form.Set("client_secret", c.config.ClientSecret)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		logger.FromContext(ctx).Error("oidcClient.Exchange failed", "issuer", c.config.IssuerURL, "error", err)
+		return nil, ErrOidcExchange
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		logger.FromContext(ctx).Error("oidcClient.Exchange token endpoint returned an error", "issuer", c.config.IssuerURL, "status", resp.StatusCode, "body", string(body))
+		return nil, ErrOidcExchange
+	}
+
+	var tokenResponse struct {
+		IDToken string `json:"id_token"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResponse); err != nil || tokenResponse.IDToken == "" {
+		return nil, ErrOidcExchange
+	}
+
+	return c.verifyIDToken(ctx, discovery, tokenResponse.IDToken, nonce)
+}
+
+type oidcIDTokenClaims struct {
+	Issuer          string      `json:"iss"`
+	Subject         string      `json:"sub"`
+	Audience        interface{} `json:"aud"`
+	AuthorizedParty string      `json:"azp"`
+	ExpiresAt       int64       `json:"exp"`
+	IssuedAt        int64       `json:"iat"`
+	Nonce           string      `json:"nonce"`
+	Email           string      `json:"email"`
+	EmailVerified   interface{} `json:"email_verified"`
+	Name            string      `json:"name"`
+}
+
+// Valid is left to verifyIDToken, which checks the claims with clock skew.
+func (c *oidcIDTokenClaims) Valid() error {
+	return nil
+}
+
+func (c *oidcIDTokenClaims) audiences() []string {
+	switch aud := c.Audience.(type) {
+	case string:
+		return []string{aud}
+	case []interface{}:
+		audiences := make([]string, 0, len(aud))
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				audiences = append(audiences, s)
+			}
+		}
+		return audiences
+	default:
+		return nil
+	}
+}
+
+// emailVerified accepts the boolean of the spec as well as the "true" string
+// some providers send.
+func (c *oidcIDTokenClaims) emailVerified() bool {
+	switch v := c.EmailVerified.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	default:
+		return false
+	}
+}
+
+func (c *oidcClient) verifyIDToken(ctx context.Context, discovery *oidcDiscovery, rawIDToken, nonce string) (*OidcIdentity, error) {
+	claims := &oidcIDTokenClaims{}
+	parser := &jwt.Parser{SkipClaimsValidation: true}
+
+	_, err := parser.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, ErrTokenAlgorithm
+		}
+
+		kid, _ := token.Header["kid"].(string)
+
+		return c.publicKey(ctx, discovery, kid)
+	})
+
+	if err != nil {
+		logger.FromContext(ctx).Warn("oidcClient.verifyIDToken invalid id token", "issuer", c.config.IssuerURL, "error", err)
+		return nil, ErrOidcInvalidToken
+	}
+
+	leeway := c.config.ClockSkew
+
+	now := time.Now()
+
+	if claims.Issuer != discovery.Issuer || claims.Subject == "" || claims.Nonce != nonce {
+		return nil, ErrOidcInvalidToken
+	}
+
+	if !containsString(claims.audiences(), c.config.ClientID) {
+		return nil, ErrOidcInvalidToken
+	}
+
+	if len(claims.audiences()) > 1 && claims.AuthorizedParty != c.config.ClientID {
+		return nil, ErrOidcInvalidToken
+	}
+
+	if claims.ExpiresAt == 0 || now.Add(-leeway).Unix() > claims.ExpiresAt || now.Add(leeway).Unix() < claims.IssuedAt {
+		return nil, ErrOidcInvalidToken
+	}
+
+	return &OidcIdentity{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.emailVerified(),
+		Name:          claims.Name,
+	}, nil
+}
+
+func (c *oidcClient) discover(ctx context.Context) (*oidcDiscovery, error) {
+	c.mutex.RLock()
+	discovery := c.discovery
+	c.mutex.RUnlock()
+
+	if discovery != nil {
+		return discovery, nil
+	}
+
+	result, err, _ := c.fetches.Do("discovery", func() (interface{}, error) {
+		var discovery oidcDiscovery
+		wellKnown := strings.TrimSuffix(c.config.IssuerURL, "/") + "/.well-known/openid-configuration"
+
+		if err := c.getJSON(context.WithoutCancel(ctx), wellKnown, &discovery); err != nil {
+			logger.FromContext(ctx).Error("oidcClient.discover failed", "issuer", c.config.IssuerURL, "error", err)
+			return nil, ErrOidcDiscovery
+		}
+
+		// the issuer in the document must be the one that was configured, otherwise
+		// a compromised document could point the flow at another provider
+		if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(c.config.IssuerURL, "/") {
+			logger.FromContext(ctx).Error("oidcClient.discover issuer mismatch", "issuer", c.config.IssuerURL, "discovered_issuer", discovery.Issuer)
+			return nil, ErrOidcDiscovery
+		}
+
+		if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JwksURI == "" {
+			return nil, ErrOidcDiscovery
+		}
+
+		c.mutex.Lock()
+		c.discovery = &discovery
+		c.mutex.Unlock()
+
+		return &discovery, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*oidcDiscovery), nil
+}
+
+// publicKey returns the provider key for kid. An unknown kid refetches the key
+// set, which picks up a provider key rotation however soon it follows the last
+// fetch. ID tokens only come from the token endpoint, clients cannot send
+// unknown kids at will to make the client refetch.
+func (c *oidcClient) publicKey(ctx context.Context, discovery *oidcDiscovery, kid string) (*rsa.PublicKey, error) {
+	if key, ok := c.cachedKey(kid); ok {
+		return key, nil
+	}
+
+	// a fetch is shared by the logins waiting for it, one of them giving up must
+	// not fail the others, the http client timeout bounds it
+	_, err, _ := c.fetches.Do("keys", func() (interface{}, error) {
+		var keySet JSONWebKeySet
+		if err := c.getJSON(context.WithoutCancel(ctx), discovery.JwksURI, &keySet); err != nil {
+			return nil, err
+		}
+
+		keys := make(map[string]*rsa.PublicKey)
+		for _, jwk := range keySet.Keys {
+			if jwk.Kty != "RSA" {
+				continue
+			}
+
+			key, err := rsaPublicKeyFromJWK(jwk)
+			if err != nil {
+				continue
+			}
+
+			keys[jwk.Kid] = key
+		}
+
+		c.mutex.Lock()
+		c.keys = keys
+		c.mutex.Unlock()
+
+		return nil, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if key, ok := c.cachedKey(kid); ok {
+		return key, nil
+	}
+
+	return nil, ErrTokenUnknownKey
+}
+
+func (c *oidcClient) cachedKey(kid string) (*rsa.PublicKey, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	key, ok := c.keys[kid]
+
+	return key, ok
+}
+
+func (c *oidcClient) getJSON(ctx context.Context, url string, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d", url, resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(target)
+}
+
+func rsaPublicKeyFromJWK(jwk JSONWebKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,205 @@
+package services_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"alpha.com/internal/alpha.com/pkg/server/services"
+	"alpha.com/internal/alpha.com/pkg/server/services/oidctest"
+)
+
+const testClientID = "alpha-client"
+
+func newTestOidcClient(provider *oidctest.Provider) services.IOidcClient {
+	return services.NewOidcClient(services.OidcConfig{
+		IssuerURL:    provider.URL,
+		ClientID:     testClientID,
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost/callback",
+		ClockSkew:    30 * time.Second,
+	}, provider.Client())
+}
+
+// exchange runs the flow with the nonce claim set to nonce, the nonce of the
+// authorization request is always "nonce-1".
+func exchange(t *testing.T, provider *oidctest.Provider, client services.IOidcClient, nonce string) (*services.OidcIdentity, error) {
+	t.Helper()
+
+	provider.Set(func(p *oidctest.Provider) { p.Claims["nonce"] = nonce })
+
+	return client.Exchange(context.Background(), "code-1", "verifier-1", "nonce-1")
+}
+
+func TestOidcClientExchange(t *testing.T) {
+	provider := oidctest.NewProvider(testClientID)
+	defer provider.Close()
+
+	client := newTestOidcClient(provider)
+
+	identity, err := exchange(t, provider, client, "nonce-1")
+	if err != nil {
+		t.Fatalf("Exchange returned %v", err)
+	}
+
+	if identity.Subject != "subject-1" || identity.Email != "user@example.com" || !identity.EmailVerified {
+		t.Errorf("Exchange returned %+v", identity)
+	}
+}
+
+func TestOidcClientSendsPkce(t *testing.T) {
+	provider := oidctest.NewProvider(testClientID)
+	defer provider.Close()
+
+	client := newTestOidcClient(provider)
+
+	authURL, err := client.AuthCodeURL(context.Background(), "state-1", "nonce-1", "verifier-1")
+	if err != nil {
+		t.Fatalf("AuthCodeURL returned %v", err)
+	}
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("AuthCodeURL returned an invalid url %q", authURL)
+	}
+
+	sum := sha256.Sum256([]byte("verifier-1"))
+	query := parsed.Query()
+
+	if query.Get("code_challenge") != base64.RawURLEncoding.EncodeToString(sum[:]) || query.Get("code_challenge_method") != "S256" {
+		t.Errorf("AuthCodeURL sent challenge %q with method %q", query.Get("code_challenge"), query.Get("code_challenge_method"))
+	}
+
+	if query.Get("state") != "state-1" || query.Get("nonce") != "nonce-1" {
+		t.Errorf("AuthCodeURL sent state %q and nonce %q", query.Get("state"), query.Get("nonce"))
+	}
+
+	if _, err := exchange(t, provider, client, "nonce-1"); err != nil {
+		t.Fatalf("Exchange returned %v", err)
+	}
+
+	provider.Get(func(p *oidctest.Provider) {
+		if p.CodeVerifier != "verifier-1" {
+			t.Errorf("token request sent code_verifier %q", p.CodeVerifier)
+		}
+	})
+}
+
+func TestOidcClientRejectsDiscoveredIssuerMismatch(t *testing.T) {
+	provider := oidctest.NewProvider(testClientID)
+	defer provider.Close()
+
+	provider.Set(func(p *oidctest.Provider) { p.Issuer = "https://attacker.example.com" })
+
+	_, err := newTestOidcClient(provider).AuthCodeURL(context.Background(), "state-1", "nonce-1", "verifier-1")
+	if !errors.Is(err, services.ErrOidcDiscovery) {
+		t.Errorf("AuthCodeURL returned %v, want ErrOidcDiscovery", err)
+	}
+}
+
+func TestOidcClientRejectsInvalidIDTokens(t *testing.T) {
+	tests := []struct {
+		name   string
+		nonce  string
+		change func(p *oidctest.Provider)
+	}{
+		{"issuer mismatch", "nonce-1", func(p *oidctest.Provider) { p.Claims["iss"] = "https://attacker.example.com" }},
+		{"bad nonce", "nonce-2", func(p *oidctest.Provider) {}},
+		{"missing nonce", "", func(p *oidctest.Provider) {}},
+		{"wrong audience", "nonce-1", func(p *oidctest.Provider) { p.Claims["aud"] = "other-client" }},
+		{"wrong authorized party", "nonce-1", func(p *oidctest.Provider) {
+			p.Claims["aud"] = []string{testClientID, "other-client"}
+			p.Claims["azp"] = "other-client"
+		}},
+		{"missing authorized party", "nonce-1", func(p *oidctest.Provider) {
+			p.Claims["aud"] = []string{testClientID, "other-client"}
+		}},
+		{"expired", "nonce-1", func(p *oidctest.Provider) {
+			p.Claims["exp"] = time.Now().Add(-time.Minute).Unix()
+		}},
+		{"issued in the future", "nonce-1", func(p *oidctest.Provider) {
+			p.Claims["iat"] = time.Now().Add(time.Hour).Unix()
+		}},
+		{"unknown kid", "nonce-1", func(p *oidctest.Provider) { p.SigningKid = "key-unknown" }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			provider := oidctest.NewProvider(testClientID)
+			defer provider.Close()
+
+			provider.Set(test.change)
+
+			_, err := exchange(t, provider, newTestOidcClient(provider), test.nonce)
+			if !errors.Is(err, services.ErrOidcInvalidToken) {
+				t.Errorf("Exchange returned %v, want ErrOidcInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestOidcClientAcceptsTokenWithinClockSkew(t *testing.T) {
+	provider := oidctest.NewProvider(testClientID)
+	defer provider.Close()
+
+	provider.Set(func(p *oidctest.Provider) { p.Claims["exp"] = time.Now().Add(-10 * time.Second).Unix() })
+
+	if _, err := exchange(t, provider, newTestOidcClient(provider), "nonce-1"); err != nil {
+		t.Errorf("Exchange returned %v for a token expired within the clock skew", err)
+	}
+}
+
+func TestOidcClientReportsUnverifiedEmail(t *testing.T) {
+	for _, emailVerified := range []interface{}{false, "false", nil} {
+		provider := oidctest.NewProvider(testClientID)
+
+		provider.Set(func(p *oidctest.Provider) { p.Claims["email_verified"] = emailVerified })
+
+		identity, err := exchange(t, provider, newTestOidcClient(provider), "nonce-1")
+		provider.Close()
+
+		if err != nil {
+			t.Fatalf("Exchange returned %v", err)
+		}
+
+		if identity.EmailVerified {
+			t.Errorf("email_verified %v was reported as verified", emailVerified)
+		}
+	}
+}
+
+func TestOidcClientPicksUpRotatedKey(t *testing.T) {
+	provider := oidctest.NewProvider(testClientID)
+	defer provider.Close()
+
+	client := newTestOidcClient(provider)
+
+	if _, err := exchange(t, provider, client, "nonce-1"); err != nil {
+		t.Fatalf("Exchange returned %v", err)
+	}
+
+	// rotated right after the key set was fetched
+	provider.Set(func(p *oidctest.Provider) {
+		p.Keys["key-2"] = oidctest.NewKey()
+		p.SigningKid = "key-2"
+	})
+
+	if _, err := exchange(t, provider, client, "nonce-1"); err != nil {
+		t.Fatalf("Exchange returned %v for a token signed with a rotated key", err)
+	}
+
+	// known keys are not fetched again
+	if _, err := exchange(t, provider, client, "nonce-1"); err != nil {
+		t.Fatalf("Exchange returned %v", err)
+	}
+
+	provider.Get(func(p *oidctest.Provider) {
+		if p.KeySetFetches != 2 {
+			t.Errorf("key set was fetched %d times, want 2", p.KeySetFetches)
+		}
+	})
+}
@@ -0,0 +1,168 @@
+// Package oidctest runs a stub OpenID Connect provider for tests of the OIDC
+// client and of the flows built on it.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// Provider serves discovery, the key set and a token endpoint that answers
+// every code with an ID token made of Claims. Fields may be changed between
+// requests.
+type Provider struct {
+	*httptest.Server
+
+	mutex sync.Mutex
+	// Issuer is the issuer of the discovery document, the server URL by default.
+	Issuer string
+	// Keys are published in the key set by kid, a "key-1" key is made by NewProvider.
+	Keys map[string]*rsa.PrivateKey
+	// SigningKid is the key ID tokens are signed with and the kid of their header.
+	SigningKid string
+	// Claims are the claims of the next ID tokens.
+	Claims jwt.MapClaims
+	// CodeVerifier is the PKCE verifier of the last token request.
+	CodeVerifier string
+	// KeySetFetches counts the requests for the key set.
+	KeySetFetches int
+}
+
+// NewProvider starts a provider whose ID tokens are valid for clientID, the
+// nonce claim is left to the test. Close stops it.
+func NewProvider(clientID string) *Provider {
+	p := &Provider{
+		Keys:       map[string]*rsa.PrivateKey{"key-1": NewKey()},
+		SigningKid: "key-1",
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/jwks", p.keySet)
+	mux.HandleFunc("/token", p.token)
+
+	p.Server = httptest.NewServer(mux)
+	p.Issuer = p.URL
+
+	now := time.Now()
+	p.Claims = jwt.MapClaims{
+		"iss":            p.URL,
+		"sub":            "subject-1",
+		"aud":            clientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+		"email":          "user@example.com",
+		"email_verified": true,
+	}
+
+	return p
+}
+
+// NewKey returns a key small enough to keep the tests fast.
+func NewKey() *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		panic(err)
+	}
+
+	return key
+}
+
+// Set changes the provider under its lock, for fields changed while requests
+// may be served.
+func (p *Provider) Set(change func(p *Provider)) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	change(p)
+}
+
+// Get reads the provider under its lock.
+func (p *Provider) Get(read func(p *Provider)) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	read(p)
+}
+
+func (p *Provider) discovery(w http.ResponseWriter, r *http.Request) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 p.Issuer,
+		"authorization_endpoint": p.URL + "/authorize",
+		"token_endpoint":         p.URL + "/token",
+		"jwks_uri":               p.URL + "/jwks",
+	})
+}
+
+func (p *Provider) keySet(w http.ResponseWriter, r *http.Request) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.KeySetFetches++
+
+	keys := make([]map[string]string, 0, len(p.Keys))
+	for kid, key := range p.Keys {
+		keys = append(keys, map[string]string{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": kid,
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"keys": keys})
+}
+
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("code") == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	p.CodeVerifier = r.PostForm.Get("code_verifier")
+
+	claims := jwt.MapClaims{}
+	for name, value := range p.Claims {
+		claims[name] = value
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = p.SigningKid
+
+	key, ok := p.Keys[p.SigningKid]
+	if !ok {
+		// a kid missing from the key set is signed with an unpublished key
+		key = NewKey()
+	}
+
+	idToken, err := token.SignedString(key)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"access_token": "access", "token_type": "Bearer", "id_token": idToken})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"alpha.com/internal/alpha.com/domain"
+)
+
+// IRateLimitStore holds the token buckets of the rate limiter, keyed by
+// policy and client.
+type IRateLimitStore interface {
+	Take(ctx context.Context, key string, capacity int, period time.Duration, now time.Time) (domain.RateLimitDecision, error)
+}
+
+// memoryRateLimitStore keeps the buckets of this process only. It suits a
+// single replica, MongoDB backed buckets are shared across replicas.
+type memoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*domain.RateLimitBucket
+	lastSweep time.Time
+}
+
+func NewMemoryRateLimitStore() IRateLimitStore {
+	return &memoryRateLimitStore{
+		buckets: make(map[string]*domain.RateLimitBucket),
+	}
+}
+
+func (s *memoryRateLimitStore) Take(ctx context.Context, key string, capacity int, period time.Duration, now time.Time) (domain.RateLimitDecision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &domain.RateLimitBucket{Key: key}
+		s.buckets[key] = bucket
+	}
+
+	return bucket.Take(capacity, period, now), nil
+}
+
+// sweep drops full buckets once a minute, they are the same as missing ones.
+func (s *memoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+
+	for key, bucket := range s.buckets {
+		if !bucket.ExpiresAt.After(now) {
+			delete(s.buckets, key)
+		}
+	}
+
+	s.lastSweep = now
+}
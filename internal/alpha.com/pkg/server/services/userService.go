@@ -1,25 +1,163 @@
 package services
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"alpha.com/configuration"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrUnknownPasswordHash = errors.New("unknown password hash format")
+
+// Argon2Params are the argon2id cost parameters, Memory is in KiB.
+type Argon2Params struct {
+	Memory  uint32
+	Time    uint32
+	Threads uint8
+	SaltLen uint32
+	KeyLen  uint32
+}
 
 type IUserService interface {
 	HashPassword(password string) (string, error)
 	CheckPasswordHash(password, hash string) bool
+	NeedsRehash(hash string) bool
 }
 
 type userService struct {
+	params Argon2Params
 }
 
-func NewUserService() IUserService {
-	return &userService{}
+func NewUserService(passwordConfig configuration.PasswordConfig) IUserService {
+	return &userService{
+		params: Argon2Params{
+			Memory:  passwordConfig.Argon2Memory,
+			Time:    passwordConfig.Argon2Time,
+			Threads: passwordConfig.Argon2Threads,
+			SaltLen: 16,
+			KeyLen:  32,
+		},
+	}
 }
 
+// HashPassword hashes with argon2id in the PHC string format,
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>, so the algorithm and its
+// parameters travel with every hash. Hashes starting with $2a$, $2b$ or $2y$
+// are bcrypt hashes from before and are still accepted by CheckPasswordHash.
 func (s *userService) HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(bytes), err
+	salt := make([]byte, s.params.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, s.params.Time, s.params.Memory, s.params.Threads, s.params.KeyLen)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		s.params.Memory,
+		s.params.Time,
+		s.params.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
 }
 
+// CheckPasswordHash reports whether password matches hash. Users who signed up
+// through a provider or a magic link have no hash, the password is run
+// through argon2id all the same so that they take as long to refuse as a
+// wrong password and cannot be told apart by timing.
 func (s *userService) CheckPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+	if hash == "" {
+		dummySalt := make([]byte, s.params.SaltLen)
+		dummyKey := make([]byte, s.params.KeyLen)
+		otherKey := argon2.IDKey([]byte(password), dummySalt, s.params.Time, s.params.Memory, s.params.Threads, s.params.KeyLen)
+
+		subtle.ConstantTimeCompare(dummyKey, otherKey)
+
+		return false
+	}
+
+	if isBcryptHash(hash) {
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		return err == nil
+	}
+
+	params, salt, key, err := decodeArgon2Hash(hash)
+	if err != nil {
+		slog.Error("userService.CheckPasswordHash failed", "error", err)
+		return false
+	}
+
+	otherKey := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, params.KeyLen)
+
+	return subtle.ConstantTimeCompare(key, otherKey) == 1
+}
+
+// NeedsRehash reports whether the hash was made with bcrypt or with argon2id
+// parameters other than the configured ones. It is called after a successful
+// sign-in, the only time the plain password is at hand.
+func (s *userService) NeedsRehash(hash string) bool {
+	if isBcryptHash(hash) {
+		return true
+	}
+
+	params, _, _, err := decodeArgon2Hash(hash)
+	if err != nil {
+		return true
+	}
+
+	return params.Memory != s.params.Memory ||
+		params.Time != s.params.Time ||
+		params.Threads != s.params.Threads ||
+		params.SaltLen != s.params.SaltLen ||
+		params.KeyLen != s.params.KeyLen
+}
+
+func isBcryptHash(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+func decodeArgon2Hash(hash string) (Argon2Params, []byte, []byte, error) {
+	var params Argon2Params
+
+	// "", "argon2id", "v=19", "m=..,t=..,p=..", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, ErrUnknownPasswordHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return params, nil, nil, ErrUnknownPasswordHash
+	}
+
+	if version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2 version: %d", version)
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads); err != nil {
+		return params, nil, nil, ErrUnknownPasswordHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrUnknownPasswordHash
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, ErrUnknownPasswordHash
+	}
+
+	params.SaltLen = uint32(len(salt))
+	params.KeyLen = uint32(len(key))
+
+	return params, salt, key, nil
 }
@@ -0,0 +1,40 @@
+package services_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"alpha.com/configuration"
+	"alpha.com/internal/alpha.com/pkg/server/services"
+)
+
+func TestCheckPasswordHashRefusesPasswordlessUsersQuietly(t *testing.T) {
+	var output bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&output, nil)))
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	userService := services.NewUserService(configuration.Default().Password)
+
+	if userService.CheckPasswordHash("", "") {
+		t.Errorf("CheckPasswordHash accepted an empty password for a user without one")
+	}
+
+	if userService.CheckPasswordHash("secret", "") {
+		t.Errorf("CheckPasswordHash accepted a password for a user without one")
+	}
+
+	if output.Len() > 0 {
+		t.Errorf("CheckPasswordHash logged a user without a password: %s", output.String())
+	}
+
+	hash, err := userService.HashPassword("secret")
+	if err != nil {
+		t.Fatalf("HashPassword failed: %v", err)
+	}
+
+	if !userService.CheckPasswordHash("secret", hash) {
+		t.Errorf("CheckPasswordHash refused the right password")
+	}
+}
@@ -0,0 +1,255 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// stdoutExporter writes one JSON object per span, for local development.
+type stdoutExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewStdoutExporter(w io.Writer) IExporter {
+	return &stdoutExporter{w: w}
+}
+
+type stdoutSpan struct {
+	TraceID       string         `json:"trace_id"`
+	SpanID        string         `json:"span_id"`
+	ParentSpanID  string         `json:"parent_span_id,omitempty"`
+	Name          string         `json:"name"`
+	Kind          string         `json:"kind"`
+	Service       string         `json:"service"`
+	Start         time.Time      `json:"start"`
+	DurationMs    float64        `json:"duration_ms"`
+	Status        string         `json:"status"`
+	StatusMessage string         `json:"status_message,omitempty"`
+	Attributes    map[string]any `json:"attributes,omitempty"`
+}
+
+func (e *stdoutExporter) Export(ctx context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	encoder := json.NewEncoder(e.w)
+	for _, span := range spans {
+		line := stdoutSpan{
+			TraceID:       span.SpanContext.TraceID.String(),
+			SpanID:        span.SpanContext.SpanID.String(),
+			Name:          span.Name,
+			Kind:          span.Kind.String(),
+			Service:       span.ServiceName,
+			Start:         span.Start,
+			DurationMs:    float64(span.End.Sub(span.Start).Microseconds()) / 1000,
+			Status:        "ok",
+			StatusMessage: span.StatusMessage,
+			Attributes:    span.Attributes,
+		}
+
+		if span.Parent.IsValid() {
+			line.ParentSpanID = span.Parent.SpanID.String()
+		}
+
+		if span.Error {
+			line.Status = "error"
+		}
+
+		if err := encoder.Encode(line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *stdoutExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// otlpHttpExporter posts spans to an OpenTelemetry collector, or anything
+// speaking OTLP/HTTP with JSON encoding, at <endpoint>/v1/traces.
+type otlpHttpExporter struct {
+	url    string
+	client *http.Client
+}
+
+// NewOtlpHttpExporter exports to endpoint, e.g. http://localhost:4318. A
+// collector is not needed locally, the stdout exporter is the simpler choice
+// and any HTTP server answering 2xx works as a stub.
+func NewOtlpHttpExporter(endpoint string, client *http.Client) IExporter {
+	if client == nil {
+		client = &http.Client{Timeout: exportTimeout}
+	}
+
+	return &otlpHttpExporter{
+		url:    strings.TrimSuffix(endpoint, "/") + "/v1/traces",
+		client: client,
+	}
+}
+
+func (e *otlpHttpExporter) Export(ctx context.Context, spans []SpanData) error {
+	body, err := json.Marshal(newOtlpRequest(spans))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("otlp collector answered %s", res.Status)
+	}
+
+	return nil
+}
+
+func (e *otlpHttpExporter) Shutdown(ctx context.Context) error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+// The types below are the JSON mapping of the OTLP ExportTraceServiceRequest,
+// ids are hex and 64 bit integers strings as the mapping requires.
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+func newOtlpRequest(spans []SpanData) otlpRequest {
+	byService := map[string][]otlpSpan{}
+	var services []string
+
+	for _, span := range spans {
+		if _, ok := byService[span.ServiceName]; !ok {
+			services = append(services, span.ServiceName)
+		}
+
+		converted := otlpSpan{
+			TraceID:           span.SpanContext.TraceID.String(),
+			SpanID:            span.SpanContext.SpanID.String(),
+			Name:              span.Name,
+			Kind:              int(span.Kind),
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Status:            otlpStatus{Code: 1},
+		}
+
+		if span.Parent.IsValid() {
+			converted.ParentSpanID = span.Parent.SpanID.String()
+		}
+
+		if span.Error {
+			converted.Status = otlpStatus{Code: 2, Message: span.StatusMessage}
+		}
+
+		for key, value := range span.Attributes {
+			converted.Attributes = append(converted.Attributes, otlpKeyValue{Key: key, Value: newOtlpAnyValue(value)})
+		}
+
+		byService[span.ServiceName] = append(byService[span.ServiceName], converted)
+	}
+
+	request := otlpRequest{}
+	for _, service := range services {
+		request.ResourceSpans = append(request.ResourceSpans, otlpResourceSpans{
+			Resource: otlpResource{Attributes: []otlpKeyValue{
+				{Key: "service.name", Value: newOtlpAnyValue(service)},
+			}},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: "alpha.com/internal/alpha.com/pkg/tracing"},
+				Spans: byService[service],
+			}},
+		})
+	}
+
+	return request
+}
+
+func newOtlpAnyValue(value any) otlpAnyValue {
+	switch v := value.(type) {
+	case string:
+		return otlpAnyValue{StringValue: &v}
+	case bool:
+		return otlpAnyValue{BoolValue: &v}
+	case int:
+		s := strconv.FormatInt(int64(v), 10)
+		return otlpAnyValue{IntValue: &s}
+	case int32:
+		s := strconv.FormatInt(int64(v), 10)
+		return otlpAnyValue{IntValue: &s}
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		return otlpAnyValue{IntValue: &s}
+	case float64:
+		return otlpAnyValue{DoubleValue: &v}
+	default:
+		s := fmt.Sprint(v)
+		return otlpAnyValue{StringValue: &s}
+	}
+}
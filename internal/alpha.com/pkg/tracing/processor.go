@@ -0,0 +1,122 @@
+package tracing
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// IExporter sends ended spans to where they are looked at.
+type IExporter interface {
+	Export(ctx context.Context, spans []SpanData) error
+	Shutdown(ctx context.Context) error
+}
+
+const (
+	maxQueueSize       = 2048
+	maxExportBatchSize = 512
+	exportInterval     = 5 * time.Second
+	exportTimeout      = 10 * time.Second
+)
+
+// BatchProcessor queues ended spans and exports them in batches from a single
+// goroutine, so a slow collector never delays a request. Spans are dropped
+// when the queue is full.
+type BatchProcessor struct {
+	exporter IExporter
+	queue    chan SpanData
+	flush    chan chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewBatchProcessor(exporter IExporter) *BatchProcessor {
+	p := &BatchProcessor{
+		exporter: exporter,
+		queue:    make(chan SpanData, maxQueueSize),
+		flush:    make(chan chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	go p.run()
+
+	return p
+}
+
+func (p *BatchProcessor) onEnd(span SpanData) {
+	select {
+	case <-p.done:
+	case p.queue <- span:
+	default:
+		slog.Warn("tracing queue is full, span dropped", "span", span.Name)
+	}
+}
+
+func (p *BatchProcessor) run() {
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+
+	batch := make([]SpanData, 0, maxExportBatchSize)
+
+	export := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+		if err := p.exporter.Export(ctx, batch); err != nil {
+			slog.Error("tracing export failed", "spans", len(batch), "error", err)
+		}
+		cancel()
+
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case span := <-p.queue:
+			batch = append(batch, span)
+			if len(batch) >= maxExportBatchSize {
+				export()
+			}
+		case <-ticker.C:
+			export()
+		case flushed := <-p.flush:
+			for len(p.queue) > 0 {
+				batch = append(batch, <-p.queue)
+				if len(batch) >= maxExportBatchSize {
+					export()
+				}
+			}
+			export()
+			close(flushed)
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// Shutdown exports the queued spans and stops the processor, spans ended
+// afterwards are dropped.
+func (p *BatchProcessor) Shutdown(ctx context.Context) error {
+	flushed := make(chan struct{})
+
+	select {
+	case p.flush <- flushed:
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-flushed:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	p.stopOnce.Do(func() { close(p.done) })
+
+	return p.exporter.Shutdown(ctx)
+}
@@ -0,0 +1,259 @@
+package tracing
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type SpanKind int
+
+// Values match the OTLP SpanKind enumeration.
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+)
+
+func (k SpanKind) String() string {
+	switch k {
+	case SpanKindServer:
+		return "server"
+	case SpanKindClient:
+		return "client"
+	default:
+		return "internal"
+	}
+}
+
+// SpanData is what exporters receive once a span has ended.
+type SpanData struct {
+	Name          string
+	Kind          SpanKind
+	SpanContext   SpanContext
+	Parent        SpanContext
+	Start         time.Time
+	End           time.Time
+	Attributes    map[string]any
+	Error         bool
+	StatusMessage string
+	ServiceName   string
+}
+
+// Span is one timed operation of a trace. A nil span is valid and does
+// nothing, so callers never check what Start returned.
+type Span struct {
+	tracer *Tracer
+	mu     sync.Mutex
+	data   SpanData
+	ended  bool
+}
+
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+
+	return s.data.SpanContext
+}
+
+// SetName replaces the name given to Start, for names only known at the end,
+// like the route an HTTP request matched.
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.data.Name = name
+	s.mu.Unlock()
+}
+
+// SetAttribute accepts strings, bools, integers and floats, anything else is
+// exported with fmt formatting.
+func (s *Span) SetAttribute(key string, value any) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.data.Attributes == nil {
+		s.data.Attributes = map[string]any{}
+	}
+	s.data.Attributes[key] = value
+	s.mu.Unlock()
+}
+
+// RecordError marks the span as failed. A nil err is ignored.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.data.Error = true
+	s.data.StatusMessage = err.Error()
+	s.mu.Unlock()
+}
+
+// SetError marks the span as failed without an error value, e.g. for 5xx
+// responses.
+func (s *Span) SetError(message string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.data.Error = true
+	s.data.StatusMessage = message
+	s.mu.Unlock()
+}
+
+// End hands the span over to the exporter, calls after the first are ignored.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	if data.SpanContext.Sampled && s.tracer.processor != nil {
+		s.tracer.processor.onEnd(data)
+	}
+}
+
+// Tracer starts spans and sends the sampled ones to its processor.
+type Tracer struct {
+	serviceName string
+	sampleRatio float64
+	processor   *BatchProcessor
+}
+
+// NewTracer returns a tracer sampling sampleRatio of the new traces, traces
+// continued from a traceparent keep the caller's decision. A nil processor
+// still creates spans, e.g. for the trace ids in logs, but exports nothing.
+func NewTracer(serviceName string, sampleRatio float64, processor *BatchProcessor) *Tracer {
+	return &Tracer{
+		serviceName: serviceName,
+		sampleRatio: math.Max(0, math.Min(1, sampleRatio)),
+		processor:   processor,
+	}
+}
+
+// Shutdown exports the spans still buffered.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if t.processor == nil {
+		return nil
+	}
+
+	return t.processor.Shutdown(ctx)
+}
+
+// Start opens a span, child of the span in ctx, or of the remote span put
+// there by ContextWithRemoteSpanContext, or else the root of a new trace.
+func (t *Tracer) Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	parent := SpanContextFromContext(ctx)
+
+	spanContext := SpanContext{SpanID: newSpanID()}
+	if parent.IsValid() {
+		spanContext.TraceID = parent.TraceID
+		spanContext.Sampled = parent.Sampled
+	} else {
+		spanContext.TraceID = newTraceID()
+		spanContext.Sampled = t.sampleRatio >= 1 || rand.Float64() < t.sampleRatio
+	}
+
+	span := &Span{
+		tracer: t,
+		data: SpanData{
+			Name:        name,
+			Kind:        kind,
+			SpanContext: spanContext,
+			Parent:      parent,
+			Start:       time.Now(),
+			ServiceName: t.serviceName,
+		},
+	}
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+type spanKey struct{}
+
+type remoteSpanContextKey struct{}
+
+// ContextWithRemoteSpanContext makes sc, taken from an incoming traceparent,
+// the parent of the next span started from the returned context.
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteSpanContextKey{}, sc)
+}
+
+// SpanFromContext returns the current span of ctx, or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// SpanContextFromContext returns the context of the current span, falling
+// back to a remote one.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.SpanContext()
+	}
+
+	if ctx != nil {
+		if sc, ok := ctx.Value(remoteSpanContextKey{}).(SpanContext); ok {
+			return sc
+		}
+	}
+
+	return SpanContext{}
+}
+
+var defaultTracer atomic.Pointer[Tracer]
+
+func init() {
+	defaultTracer.Store(NewTracer("alpha", 1, nil))
+}
+
+// SetDefault makes t the tracer of Start, like slog.SetDefault.
+func SetDefault(t *Tracer) {
+	defaultTracer.Store(t)
+}
+
+func Default() *Tracer {
+	return defaultTracer.Load()
+}
+
+// Start opens an internal span with the default tracer. Repositories, command
+// handlers and query services open one per method:
+//
+//	ctx, span := tracing.Start(ctx, "jobRepository.Save")
+//	defer span.End()
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	return Default().Start(ctx, name, SpanKindInternal)
+}
+
+// StartKind is Start for server and client spans.
+func StartKind(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	return Default().Start(ctx, name, kind)
+}
@@ -1,5 +1,17 @@
 package utils
 
+// UserContext is the authenticated principal of a request. Requests made with
+// an API key act as the owner of its business account, limited to Scopes.
 type UserContext struct {
-	UserID string
+	UserID    string
+	SessionID string
+	Roles     []string
+
+	ApiKeyID          string
+	BusinessAccountID string
+	Scopes            []string
+}
+
+func (u *UserContext) IsApiKey() bool {
+	return u.ApiKeyID != ""
 }
@@ -0,0 +1,168 @@
+package validation
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const passwordField = "Password"
+
+// PasswordPolicy is the rule set new passwords are checked against.
+//
+// BreachedPasswordsDir holds the breached-password list split the way the
+// k-anonymity range API of Have I Been Pwned serves it: one file per first
+// five hex characters of the upper-case SHA-1, named <PREFIX>.txt, with a
+// <SUFFIX>:<COUNT> line per password. Only the file of the checked prefix is
+// read. An empty directory setting turns the check off.
+type PasswordPolicy struct {
+	MinLength            int
+	RequireUppercase     bool
+	RequireLowercase     bool
+	RequireDigit         bool
+	RequireSymbol        bool
+	ForbidPersonalInfo   bool
+	BreachedPasswordsDir string
+}
+
+type IPasswordPolicy interface {
+	Check(password string, personalInfo ...string) []CustomValidationError
+}
+
+type passwordPolicy struct {
+	policy PasswordPolicy
+}
+
+func NewPasswordPolicy(policy PasswordPolicy) IPasswordPolicy {
+	if policy.BreachedPasswordsDir != "" {
+		if _, err := os.Stat(policy.BreachedPasswordsDir); err != nil {
+			slog.Warn("validation.NewPasswordPolicy breached password list is not available", "error", err)
+		}
+	}
+
+	return &passwordPolicy{policy: policy}
+}
+
+// Check returns one error per violated rule, nil when the password is
+// accepted. personalInfo are values of the account, like the email and the
+// names, that must not appear in the password. The password itself is never
+// put in the errors.
+func (p *passwordPolicy) Check(password string, personalInfo ...string) []CustomValidationError {
+	var customValidationErrors []CustomValidationError
+
+	violation := func(tag, param string) {
+		customValidationErrors = append(customValidationErrors, CustomValidationError{
+			HasError: true,
+			Field:    passwordField,
+			Tag:      tag,
+			Param:    param,
+		})
+	}
+
+	if len([]rune(password)) < p.policy.MinLength {
+		violation("min", strconv.Itoa(p.policy.MinLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.policy.RequireUppercase && !hasUpper {
+		violation("uppercase", "")
+	}
+
+	if p.policy.RequireLowercase && !hasLower {
+		violation("lowercase", "")
+	}
+
+	if p.policy.RequireDigit && !hasDigit {
+		violation("digit", "")
+	}
+
+	if p.policy.RequireSymbol && !hasSymbol {
+		violation("symbol", "")
+	}
+
+	if p.policy.ForbidPersonalInfo && containsPersonalInfo(password, personalInfo) {
+		violation("personal_info", "")
+	}
+
+	if p.policy.BreachedPasswordsDir != "" && p.isBreached(password) {
+		violation("breached", "")
+	}
+
+	return customValidationErrors
+}
+
+// containsPersonalInfo compares case-insensitively. Emails are checked as a
+// whole and by their local part. Values shorter than three characters are
+// skipped, they would reject too many passwords.
+func containsPersonalInfo(password string, personalInfo []string) bool {
+	lowerPassword := strings.ToLower(password)
+
+	for _, info := range personalInfo {
+		candidates := []string{info}
+
+		if localPart, _, found := strings.Cut(info, "@"); found {
+			candidates = append(candidates, localPart)
+		}
+
+		for _, candidate := range candidates {
+			candidate = strings.ToLower(strings.TrimSpace(candidate))
+
+			if len([]rune(candidate)) >= 3 && strings.Contains(lowerPassword, candidate) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isBreached fails open, a missing or unreadable list does not block sign-ups.
+func (p *passwordPolicy) isBreached(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	file, err := os.Open(filepath.Join(p.policy.BreachedPasswordsDir, prefix+".txt"))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			slog.Error("passwordPolicy.isBreached failed", "error", err)
+		}
+		return false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineSuffix, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+
+		if strings.EqualFold(lineSuffix, suffix) {
+			return true
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		slog.Error("passwordPolicy.isBreached failed", "error", err)
+	}
+
+	return false
+}
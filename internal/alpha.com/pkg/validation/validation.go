@@ -1,6 +1,10 @@
 package validation
 
-import "github.com/go-playground/validator/v10"
+import (
+	"log/slog"
+
+	"github.com/go-playground/validator/v10"
+)
 
 // custom validation error struct
 type CustomValidationError struct {
@@ -11,6 +15,16 @@ type CustomValidationError struct {
 	Value    interface{}
 }
 
+// LogValue leaves out Value, it is whatever the client sent and may be a
+// password.
+func (e CustomValidationError) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("field", e.Field),
+		slog.String("tag", e.Tag),
+		slog.String("param", e.Param),
+	)
+}
+
 type ICustomValidator interface {
 	Validate(data interface{}) []CustomValidationError
 }
@@ -1,26 +1,49 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
 	"alpha.com/configuration"
 	_ "alpha.com/docs"
 	"alpha.com/internal/alpha.com/application/controller"
 	"alpha.com/internal/alpha.com/application/controller/response"
+	"alpha.com/internal/alpha.com/application/handler/admin"
+	"alpha.com/internal/alpha.com/application/handler/apiKey"
 	"alpha.com/internal/alpha.com/application/handler/businessAccount"
+	"alpha.com/internal/alpha.com/application/handler/externalIdentity"
 	"alpha.com/internal/alpha.com/application/handler/job"
 	"alpha.com/internal/alpha.com/application/handler/jobApply"
 	"alpha.com/internal/alpha.com/application/handler/jwt"
+	"alpha.com/internal/alpha.com/application/handler/magicLink"
+	"alpha.com/internal/alpha.com/application/handler/mfa"
+	"alpha.com/internal/alpha.com/application/handler/report"
+	"alpha.com/internal/alpha.com/application/handler/session"
 	"alpha.com/internal/alpha.com/application/handler/user"
 	"alpha.com/internal/alpha.com/application/query"
 	"alpha.com/internal/alpha.com/application/repository"
 	"alpha.com/internal/alpha.com/application/web"
+	"alpha.com/internal/alpha.com/pkg/health"
+	"alpha.com/internal/alpha.com/pkg/logger"
+	"alpha.com/internal/alpha.com/pkg/metrics"
 	"alpha.com/internal/alpha.com/pkg/mongodb"
 	"alpha.com/internal/alpha.com/pkg/server"
+	"alpha.com/internal/alpha.com/pkg/server/middlewares"
 	"alpha.com/internal/alpha.com/pkg/server/services"
+	"alpha.com/internal/alpha.com/pkg/tracing"
 	"alpha.com/internal/alpha.com/pkg/validation"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/gofiber/swagger"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // @title			Alpha Fiber Rest Api
@@ -29,8 +52,23 @@ import (
 // @contact.name	Alpha
 // @contact.email	alpha@gmail.com
 func main() {
+	os.Exit(runCommand(os.Args[1:]))
+}
+
+// runServe runs "alpha serve", the HTTP server, until SIGINT or SIGTERM.
+func runServe(args []string) int {
+	config := loadConfig(newFlagSet("serve", "", "starts the HTTP server"), args)
+
+	// Tracing
+	tracer := newTracer(config.Tracing)
+	tracing.SetDefault(tracer)
+
+	// components are stopped in the reverse order they are registered in
+	lifecycle := server.NewLifecycle()
+	lifecycle.Register("tracing", tracer.Shutdown)
+
 	// fiber framework http server
-	app := fiber.New(
+	app := fiber.New(server.WithProxies(
 		fiber.Config{
 			// Override default error handler
 			ErrorHandler: func(ctx *fiber.Ctx, err error) error {
@@ -41,7 +79,14 @@ func main() {
 				// Retrieve the custom error from fiber's context if it exists
 				var customError response.CustomError
 
-				if e, ok := err.(*fiber.Error); ok {
+				if errors.Is(err, context.DeadlineExceeded) {
+					// the request deadline passed before the handler was done
+					statusCode = fiber.StatusGatewayTimeout
+					customError = response.CustomError{
+						StatusCode: statusCode,
+						Message:    "request timed out",
+					}
+				} else if e, ok := err.(*fiber.Error); ok {
 					// Fiber error, use its status code and message
 					statusCode = e.Code
 					customError = response.CustomError{
@@ -61,58 +106,183 @@ func main() {
 				return ctx.Status(customError.StatusCode).JSON(customError)
 			},
 		},
-	)
+		config.Server,
+	))
+
+	// Metrics
+	metricsRegistry := metrics.NewRegistry()
+	metrics.RegisterRuntimeMetrics(metricsRegistry)
+	businessMetrics := metrics.NewBusinessMetrics(metricsRegistry)
 
+	app.Use(middlewares.NewRequestLogger(slog.Default()))
+	app.Use(middlewares.NewTracing())
+	app.Use(middlewares.NewHttpMetrics(metricsRegistry))
+	app.Use(middlewares.NewTimeout(config.Server.RequestTimeout.Duration()))
 	app.Use(recover.New())
 
-	configureSwaggerUi(app)
+	configureSwaggerUi(app, config.Env)
 
-	mongoClient := mongodb.ConnectMongoDB()
+	// stopped on SIGINT or SIGTERM, which also ends a startup still waiting
+	// for MongoDB
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	mongoClient, err := mongodb.ConnectMongoDB(ctx, config.Mongo, metricsRegistry)
+	if err != nil {
+		slog.Error("Cannot connect to MongoDB", "error", err)
+		return 1
+	}
+
+	lifecycle.Register("mongo", func(ctx context.Context) error {
+		return mongodb.DisconnectMongoDB(ctx, mongoClient)
+	})
+
+	// migrations run in the background, /readyz fails until they are applied
+	migrationBootstrap := health.NewBootstrap("migrations")
+	migrationBootstrap.Add("migrate", newMigrationStep(newMigrationRunner(mongoClient, config.Mongo), config.Mongo.MigrateOnStartup))
 
 	// custom validator initializing
 	customValidator := validation.NewCustomValidator(validator.New())
 
+	// Jwt signing keys
+	keyRing := newKeyRing(config.Jwt, config.Env, lifecycle)
+	jwtService := services.NewJwtService(keyRing, config.Jwt, config.Mfa.ChallengeTime.Duration())
+
 	// User Dependency injection
-	userRepository := repository.NewUserRepository(mongoClient)
-	userService := services.NewUserService()
+	userRepository := repository.NewUserRepository(mongoClient, config.Mongo)
+	loginAttemptRepository := repository.NewLoginAttemptRepository(mongoClient, config.Mongo)
+	userService := services.NewUserService(config.Password)
+	mailService := services.NewMailService(config.Mail)
 	userQueryService := query.NewUserQueryService(userRepository)
-	userCommandHandler := user.NewCommandHandler(userRepository, userService)
-	userController := controller.NewUserController(userQueryService, userCommandHandler, customValidator)
+	userCommandHandler, err := user.NewCommandHandler(userRepository, loginAttemptRepository, userService, mailService, jwtService, config.Login, config.Server.BackendURL, businessMetrics)
+	if err != nil {
+		slog.Error("Cannot create the user command handler", "error", err)
+		return 1
+	}
+
+	// Session Dependency injection
+	sessionRepository := repository.NewSessionRepository(mongoClient, config.Mongo)
+	sessionQueryService := query.NewSessionQueryService(sessionRepository)
 
 	// Jwt Dependency injection
-	jwtRepository := repository.NewJwtRepository(mongoClient)
-	jwtService := services.NewJwtService()
-	jwtQueryService := query.NewJwtQueryService(jwtRepository, userQueryService, jwtService)
-	jwtCommandHandler := jwt.NewCommandHandler(jwtRepository, jwtService, userQueryService)
+	jwtRepository := repository.NewJwtRepository(mongoClient, config.Mongo)
+	jwtQueryService := query.NewJwtQueryService(jwtRepository)
+	jwtCommandHandler := jwt.NewCommandHandler(jwtRepository, sessionRepository, jwtService, userQueryService, sessionQueryService)
 	jwtController := controller.NewJwtController(jwtQueryService, jwtCommandHandler, customValidator)
 
+	userController := controller.NewUserController(userQueryService, userCommandHandler, jwtCommandHandler, customValidator, newPasswordPolicy(config.Password))
+
+	// Mfa Dependency injection
+	totpService := services.NewTotpService(config.Mfa.Issuer)
+	mfaCommandHandler := mfa.NewCommandHandler(userRepository, loginAttemptRepository, totpService, newSecretCipher(config.Mfa), jwtService, config.Mfa, config.Login.AttemptWindow.Duration())
+	mfaController := controller.NewMfaController(mfaCommandHandler, jwtCommandHandler, customValidator)
+
+	// Magic Link Dependency injection
+	magicLinkRepository := repository.NewMagicLinkRepository(mongoClient, config.Mongo)
+	magicLinkCommandHandler := magicLink.NewCommandHandler(magicLinkRepository, userQueryService, userCommandHandler, mailService, jwtService, config.MagicLink, config.Server.BackendURL)
+	magicLinkController := controller.NewMagicLinkController(magicLinkCommandHandler, jwtCommandHandler, customValidator, config.MagicLink, config.Env == "prod")
+
+	// External Identity Dependency injection
+	externalIdentityRepository := repository.NewExternalIdentityRepository(mongoClient, config.Mongo)
+	oidcStateRepository := repository.NewOidcStateRepository(mongoClient, config.Mongo)
+	externalIdentityQueryService := query.NewExternalIdentityQueryService(externalIdentityRepository)
+	externalIdentityCommandHandler := externalIdentity.NewCommandHandler(externalIdentityRepository, oidcStateRepository, userQueryService, userCommandHandler, jwtService, newOidcClients(config), config.Oidc.StateTime.Duration())
+	externalIdentityController := controller.NewExternalIdentityController(externalIdentityQueryService, externalIdentityCommandHandler, jwtCommandHandler, config.Oidc, config.Env == "prod")
+
+	sessionCommandHandler := session.NewCommandHandler(sessionRepository, jwtRepository, sessionQueryService)
+	sessionController := controller.NewSessionController(sessionQueryService, sessionCommandHandler)
+
 	// Business Account Dependency injection
-	businessAccountRepository := repository.NewBusinessAccountRepository(mongoClient)
+	businessAccountRepository := repository.NewBusinessAccountRepository(mongoClient, config.Mongo)
 	businessAccountQueryService := query.NewBusinessAccountQueryService(businessAccountRepository)
-	businessAccountCommandHandler := businessAccount.NewCommandHandler(businessAccountRepository)
+	businessAccountCommandHandler := businessAccount.NewCommandHandler(businessAccountRepository, userCommandHandler)
 	businessAccountController := controller.NewBusinessAccountController(businessAccountQueryService, businessAccountCommandHandler, customValidator)
 
 	// Job Dependency injection
-	jobRepository := repository.NewJobRepository(mongoClient)
+	jobRepository := repository.NewJobRepository(mongoClient, config.Mongo)
 	jobQueryService := query.NewJobQueryService(jobRepository)
-	jobCommandHandler := job.NewCommandHandler(jobRepository, businessAccountQueryService)
+	jobCommandHandler := job.NewCommandHandler(jobRepository, businessAccountQueryService, businessMetrics)
 	jobController := controller.NewJobController(jobQueryService, jobCommandHandler, customValidator)
 
+	// Api Key Dependency injection
+	apiKeyRepository := repository.NewApiKeyRepository(mongoClient, config.Mongo)
+	apiKeyQueryService := query.NewApiKeyQueryService(apiKeyRepository, businessAccountRepository)
+	apiKeyCommandHandler := apiKey.NewCommandHandler(apiKeyRepository, apiKeyQueryService)
+	apiKeyController := controller.NewApiKeyController(apiKeyQueryService, apiKeyCommandHandler, customValidator)
+
 	// Job Apply Dependency injection
-	jobApplyRepository := repository.NewJobApplyRepository(mongoClient)
-	jobApplyQueryService := query.NewJobApplyQueryService(jobApplyRepository)
-	jobApplyCommandHandler := jobApply.NewCommandHandler(jobApplyRepository, jobQueryService, userQueryService)
+	jobApplyRepository := repository.NewJobApplyRepository(mongoClient, config.Mongo)
+	jobApplyQueryService := query.NewJobApplyQueryService(jobApplyRepository, jobQueryService, apiKeyQueryService)
+	jobApplyCommandHandler := jobApply.NewCommandHandler(jobApplyRepository, jobQueryService, userQueryService, businessMetrics)
 	jobApplyController := controller.NewJobApplyController(jobApplyQueryService, jobApplyCommandHandler, customValidator)
 
+	// Report Dependency injection
+	reportRepository := repository.NewReportRepository(mongoClient, config.Mongo)
+	reportCommandHandler := report.NewCommandHandler(reportRepository, userRepository, jobRepository)
+	reportController := controller.NewReportController(reportCommandHandler, customValidator)
+
+	// Admin Dependency injection
+	adminActionRepository := repository.NewAdminActionRepository(mongoClient, config.Mongo)
+	userStatusQueryService := query.NewUserStatusQueryService(userRepository)
+	adminQueryService := query.NewAdminQueryService(userRepository, reportRepository, adminActionRepository)
+	adminCommandHandler := admin.NewCommandHandler(userRepository, jobRepository, reportRepository, adminActionRepository, userStatusQueryService)
+	adminController := controller.NewAdminController(adminQueryService, adminCommandHandler, customValidator)
+
+	jwtMiddleware := middlewares.NewJwtMiddleware(jwtService, userStatusQueryService, sessionQueryService)
+	authMiddleware := middlewares.NewAuthMiddleware(jwtMiddleware, apiKeyQueryService, userStatusQueryService)
+	rateLimiter := middlewares.NewRateLimiter(newRateLimitStore(mongoClient, config), newRateLimitPolicies(config.RateLimit))
+
+	jwksController := controller.NewJwksController(keyRing)
+	metricsController := controller.NewMetricsController(metricsRegistry)
+
+	// Health Dependency injection
+	readiness := health.NewReadiness(config.Server.ReadinessTimeout.Duration(), mongodb.NewHealthChecker(mongoClient), migrationBootstrap)
+	healthController := controller.NewHealthController(readiness)
+
+	lifecycle.Go("migrationBootstrap", func(ctx context.Context) {
+		migrationBootstrap.Run(ctx, 5*time.Second)
+	})
+
 	// Router initializing
-	web.InitRouter(app, userController, jwtController, businessAccountController, jobController, jobApplyController)
+	web.InitRouter(app, jwtMiddleware, authMiddleware, rateLimiter, userController, jwtController, businessAccountController, jobController, jobApplyController, reportController, adminController, sessionController, jwksController, mfaController, magicLinkController, externalIdentityController, apiKeyController, healthController)
+
+	// served on its own port so that the load balancer never exposes it
+	metricsApp := fiber.New(fiber.Config{DisableStartupMessage: true})
+	metricsApp.Use(recover.New())
+	web.InitMetricsRouter(metricsApp, metricsController)
 
 	// Start server
-	server.NewServer(app).StartHttpServer(mongoClient)
+	if err := server.NewServer(app, metricsApp, config.Server, readiness).StartHttpServer(ctx, lifecycle); err != nil {
+		// a forced shutdown may have lost work, the orchestrator has to know
+		slog.Error("Server did not stop cleanly", "error", err)
+		return 1
+	}
+
+	return 0
+}
+
+// loadConfig parses the configuration flags along with the flags of the
+// command in flagSet. It exits with status 2 when the configuration is
+// invalid, before anything was started.
+func loadConfig(flagSet *flag.FlagSet, args []string) *configuration.Config {
+	config, err := configuration.LoadFlags(flagSet, args)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+
+	if err != nil {
+		slog.Error("Cannot load configuration", "error", err)
+		os.Exit(2)
+	}
+
+	slog.SetDefault(logger.New(os.Stdout, config.Log.Format, config.Log.Level))
+	slog.Info("Configuration loaded", "config", config)
+
+	return config
 }
 
-func configureSwaggerUi(app *fiber.App) {
-	if configuration.Env != "prod" {
+func configureSwaggerUi(app *fiber.App, env string) {
+	if env != "prod" {
 		// Swagger injection
 		app.Get("/swagger/*", swagger.HandlerDefault)
 
@@ -122,3 +292,108 @@ func configureSwaggerUi(app *fiber.App) {
 		})
 	}
 }
+
+// newKeyRing loads the signing keys the replicas share. Only rotate-keys
+// writes keys, a local instance generates its first one itself while prod
+// fails to start without a key rather than signing with one of its own.
+func newKeyRing(jwtConfig configuration.JwtConfig, env string, lifecycle *server.Lifecycle) services.IKeyRing {
+	reloadInterval := jwtConfig.KeyReloadInterval.Duration()
+
+	keyRing, err := services.NewKeyRing(jwtConfig.KeysDir, keyRetention(jwtConfig), keyActivationDelay(jwtConfig), env != "prod")
+	if err != nil {
+		panic(fmt.Sprintf("cannot load jwt signing keys: %v", err))
+	}
+
+	lifecycle.Go("keyReload", func(ctx context.Context) {
+		keyRing.StartReload(ctx, reloadInterval)
+	})
+
+	return keyRing
+}
+
+// keyRetention is how long a retired key keeps verifying. Refresh tokens are
+// signed by the ring as well, so it is one reload interval more than a refresh
+// token lives, a margin for replicas whose clocks are behind.
+func keyRetention(jwtConfig configuration.JwtConfig) time.Duration {
+	return jwtConfig.RefreshTokenTime.Duration() + jwtConfig.KeyReloadInterval.Duration()
+}
+
+// keyActivationDelay is how long a rotated key is only published. Every
+// replica has reloaded it by then and every verifier has refetched the key
+// set, none of them rejects a token signed with it.
+func keyActivationDelay(jwtConfig configuration.JwtConfig) time.Duration {
+	return jwtConfig.KeyReloadInterval.Duration() + services.JWKSMaxAge
+}
+
+func newSecretCipher(mfaConfig configuration.MfaConfig) services.ISecretCipher {
+	secretCipher, err := services.NewSecretCipher(mfaConfig.EncryptionKey)
+	if err != nil {
+		panic(fmt.Sprintf("invalid MFA_ENCRYPTION_KEY: %v", err))
+	}
+
+	return secretCipher
+}
+
+func newPasswordPolicy(passwordConfig configuration.PasswordConfig) validation.IPasswordPolicy {
+	return validation.NewPasswordPolicy(validation.PasswordPolicy{
+		MinLength:            passwordConfig.MinLength,
+		RequireUppercase:     passwordConfig.RequireUppercase,
+		RequireLowercase:     passwordConfig.RequireLowercase,
+		RequireDigit:         passwordConfig.RequireDigit,
+		RequireSymbol:        passwordConfig.RequireSymbol,
+		ForbidPersonalInfo:   passwordConfig.ForbidPersonalInfo,
+		BreachedPasswordsDir: passwordConfig.BreachedDir,
+	})
+}
+
+func newRateLimitStore(mongoClient *mongo.Client, config *configuration.Config) services.IRateLimitStore {
+	if config.RateLimit.Store == "memory" {
+		return services.NewMemoryRateLimitStore()
+	}
+
+	return repository.NewRateLimitRepository(mongoClient, config.Mongo)
+}
+
+func newTracer(tracingConfig configuration.TracingConfig) *tracing.Tracer {
+	var processor *tracing.BatchProcessor
+
+	switch tracingConfig.Exporter {
+	case "stdout":
+		processor = tracing.NewBatchProcessor(tracing.NewStdoutExporter(os.Stdout))
+	case "otlp":
+		processor = tracing.NewBatchProcessor(tracing.NewOtlpHttpExporter(tracingConfig.OtlpEndpoint, nil))
+	}
+
+	return tracing.NewTracer(tracingConfig.ServiceName, tracingConfig.SampleRatio, processor)
+}
+
+func newRateLimitPolicies(rateLimitConfig configuration.RateLimitConfig) map[string]middlewares.RateLimitPolicy {
+	policies := make(map[string]middlewares.RateLimitPolicy)
+
+	for name, policy := range rateLimitConfig.Policies {
+		policies[name] = middlewares.RateLimitPolicy{
+			Capacity: policy.Capacity,
+			Period:   policy.Period.Duration(),
+			KeyBy:    policy.KeyBy,
+		}
+	}
+
+	return policies
+}
+
+func newOidcClients(config *configuration.Config) map[string]services.IOidcClient {
+	clients := make(map[string]services.IOidcClient)
+
+	for name, provider := range config.Oidc.Providers {
+		clients[name] = services.NewOidcClient(services.OidcConfig{
+			IssuerURL:    provider.IssuerURL,
+			ClientID:     provider.ClientID,
+			ClientSecret: provider.ClientSecret,
+			RedirectURL:  fmt.Sprintf("%s/api/v1/alpha/auth/oidc/%s/callback", config.Server.BackendURL, name),
+			Scopes:       provider.Scopes,
+			ClockSkew:    config.Jwt.ClockSkew.Duration(),
+		}, nil)
+	}
+
+	return clients
+}
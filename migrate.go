@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"alpha.com/configuration"
+	"alpha.com/internal/alpha.com/application/repository"
+	"alpha.com/internal/alpha.com/pkg/migration"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// runMigrate runs "alpha migrate up|down|status".
+func runMigrate(args []string) int {
+	flagSet := newFlagSet("migrate", "up|down|status ", "up applies every pending migration, down reverts the last applied ones\n"+
+		"and status lists the migrations and whether they are applied.")
+	steps := flagSet.Int("steps", 1, "number of migrations down reverts")
+
+	if len(args) == 0 || (args[0] != "up" && args[0] != "down" && args[0] != "status") {
+		flagSet.Usage()
+		return 2
+	}
+
+	action := args[0]
+	config := loadConfig(flagSet, args[1:])
+
+	ctx, stop := commandContext()
+	defer stop()
+
+	mongoClient, disconnect, err := connectMongo(ctx, config.Mongo)
+	if err != nil {
+		slog.Error("Cannot connect to MongoDB", "error", err)
+		return 1
+	}
+	defer disconnect()
+
+	runner := newMigrationRunner(mongoClient, config.Mongo)
+
+	switch action {
+	case "up":
+		applied, err := runner.Up(ctx)
+		if err != nil {
+			slog.Error("Migrations failed", "applied", applied, "error", err)
+			return 1
+		}
+		slog.Info("Migrations applied", "applied", applied)
+	case "down":
+		reverted, err := runner.Down(ctx, *steps)
+		if err != nil {
+			slog.Error("Migrations could not be reverted", "reverted", reverted, "error", err)
+			return 1
+		}
+		slog.Info("Migrations reverted", "reverted", reverted)
+	case "status":
+		statuses, err := runner.Status(ctx)
+		if err != nil {
+			slog.Error("Migration status could not be read", "error", err)
+			return 1
+		}
+		printMigrationStatus(statuses)
+	}
+
+	return 0
+}
+
+func printMigrationStatus(statuses []migration.Status) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "VERSION\tNAME\tSTATE\tAPPLIED AT")
+
+	for _, status := range statuses {
+		appliedAt := "-"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%s\n", status.Version, status.Name, status.State, appliedAt)
+	}
+
+	writer.Flush()
+}
+
+func newMigrationRunner(mongoClient *mongo.Client, mongoConfig configuration.MongoConfig) *migration.Runner {
+	return migration.NewRunner(
+		mongoClient.Database(mongoConfig.Database),
+		mongoConfig.Collections.Migrations,
+		repository.Migrations(mongoConfig.Collections),
+	)
+}
+
+// newMigrationStep is the startup step of the server, it migrates or, when
+// migrations are run separately, waits for them to be applied.
+func newMigrationStep(runner *migration.Runner, migrateOnStartup bool) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if migrateOnStartup {
+			_, err := runner.Up(ctx)
+			return err
+		}
+
+		pending, err := runner.Pending(ctx)
+		if err != nil {
+			return err
+		}
+
+		if pending > 0 {
+			return fmt.Errorf("%d migrations are pending, run alpha migrate up", pending)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"strings"
+	"time"
+
+	"alpha.com/internal/alpha.com/application/handler/businessAccount"
+	"alpha.com/internal/alpha.com/application/handler/job"
+	"alpha.com/internal/alpha.com/application/handler/jobApply"
+	"alpha.com/internal/alpha.com/application/handler/user"
+	"alpha.com/internal/alpha.com/application/query"
+	"alpha.com/internal/alpha.com/application/repository"
+	"alpha.com/internal/alpha.com/domain"
+	"alpha.com/internal/alpha.com/pkg/metrics"
+	"alpha.com/internal/alpha.com/pkg/utils"
+)
+
+var (
+	seedFirstNames = []string{"Emma", "Liam", "Olivia", "Noah", "Ava", "Lucas", "Mia", "Ethan", "Zeynep", "Mehmet",
+		"Elif", "Can", "Sofia", "Mateo", "Hannah", "Jonas", "Chloe", "Hugo", "Aylin", "Emir", "Nora", "Leo", "Ines", "Arda"}
+	seedLastNames = []string{"Smith", "Johnson", "Brown", "Garcia", "Miller", "Yilmaz", "Kaya", "Demir", "Schmidt", "Muller",
+		"Rossi", "Bianchi", "Dubois", "Martin", "Lopez", "Novak", "Jensen", "Sahin", "Celik", "Wilson"}
+	seedCompanyWords  = []string{"Blue", "North", "Bright", "Stone", "River", "Swift", "Green", "Summit", "Harbor", "Atlas", "Pixel", "Cedar"}
+	seedCompanyTrades = []string{"Logistics", "Studio", "Labs", "Builders", "Analytics", "Foods", "Health", "Consulting", "Media", "Robotics"}
+	seedJobs          = []struct {
+		name     string
+		category string
+		minPrice float32
+		maxPrice float32
+	}{
+		{"Backend Developer", "software", 3000, 7000},
+		{"Frontend Developer", "software", 2500, 6000},
+		{"Mobile Developer", "software", 2800, 6500},
+		{"Data Analyst", "data", 2500, 5500},
+		{"UX Designer", "design", 2200, 5000},
+		{"Graphic Designer", "design", 1500, 3500},
+		{"Content Writer", "marketing", 800, 2500},
+		{"Social Media Manager", "marketing", 1200, 3000},
+		{"Accountant", "finance", 2000, 4500},
+		{"Customer Support Agent", "support", 1000, 2200},
+		{"Warehouse Operator", "logistics", 900, 1800},
+		{"Delivery Driver", "logistics", 900, 2000},
+	}
+)
+
+// runSeed runs "alpha seed". Employers, their business accounts and jobs, and
+// candidates applying to those jobs are made through the command handlers, the
+// way sign-up and the job endpoints would make them.
+func runSeed(args []string) int {
+	flagSet := newFlagSet("seed", "", "Fills the database with fake employers, business accounts, jobs, candidates and\n"+
+		"applications. Every seeded account has an @example.com email and the same password.")
+	businesses := flagSet.Int("businesses", 5, "number of employers, each with a business account")
+	jobs := flagSet.Int("jobs", 20, "number of jobs, spread over the business accounts")
+	candidates := flagSet.Int("users", 30, "number of candidates")
+	applications := flagSet.Int("applications", 60, "number of applications, spread over candidates and jobs")
+	password := flagSet.String("password", "Seed-Password-1", "password of every seeded account")
+	seed := flagSet.Int64("seed", 0, "seed of the fake data, 0 picks one")
+	force := flagSet.Bool("force", false, "seed even when ENV is prod")
+
+	config := loadConfig(flagSet, args)
+
+	if config.Env == "prod" && !*force {
+		slog.Error("Refusing to seed a prod environment without -force")
+		return 2
+	}
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+
+	ctx, stop := commandContext()
+	defer stop()
+
+	mongoClient, disconnect, err := connectMongo(ctx, config.Mongo)
+	if err != nil {
+		slog.Error("Cannot connect to MongoDB", "error", err)
+		return 1
+	}
+	defer disconnect()
+
+	businessMetrics := metrics.NewBusinessMetrics(metrics.NewRegistry())
+
+	userRepository := repository.NewUserRepository(mongoClient, config.Mongo)
+	userQueryService := query.NewUserQueryService(userRepository)
+	userCommandHandler, err := newCliUserCommandHandler(mongoClient, userRepository, config)
+	if err != nil {
+		slog.Error("Cannot create the user command handler", "error", err)
+		return 1
+	}
+
+	businessAccountRepository := repository.NewBusinessAccountRepository(mongoClient, config.Mongo)
+	businessAccountQueryService := query.NewBusinessAccountQueryService(businessAccountRepository)
+	businessAccountCommandHandler := businessAccount.NewCommandHandler(businessAccountRepository, userCommandHandler)
+
+	jobRepository := repository.NewJobRepository(mongoClient, config.Mongo)
+	jobQueryService := query.NewJobQueryService(jobRepository)
+	jobCommandHandler := job.NewCommandHandler(jobRepository, businessAccountQueryService, businessMetrics)
+
+	jobApplyRepository := repository.NewJobApplyRepository(mongoClient, config.Mongo)
+	jobApplyCommandHandler := jobApply.NewCommandHandler(jobApplyRepository, jobQueryService, userQueryService, businessMetrics)
+
+	seeder := &seeder{
+		random:             rand.New(rand.NewSource(*seed)),
+		password:           *password,
+		userCommandHandler: userCommandHandler,
+	}
+
+	slog.Info("Seeding", "seed", *seed)
+
+	// employers and their business accounts
+	employerIDs := make(map[string]bool)
+	for i := 0; i < *businesses && ctx.Err() == nil; i++ {
+		userID, err := seeder.user(ctx, domain.RoleEmployer)
+		if err != nil {
+			slog.Error("Employer could not be seeded", "error", err)
+			return 1
+		}
+
+		name := seeder.companyName()
+		command := businessAccount.Command{
+			Name:        name,
+			Description: fmt.Sprintf("%s is a %s company founded in %d.", name, strings.ToLower(seeder.pick(seedCompanyTrades)), 1990+seeder.random.Intn(34)),
+		}
+
+		if err := businessAccountCommandHandler.Save(ctx, command, userID); err != nil {
+			slog.Error("Business account could not be seeded", "error", err)
+			return 1
+		}
+
+		employerIDs[userID] = true
+	}
+
+	// the handlers do not return ids, the seeded documents are found by owner
+	allBusinessAccounts, err := businessAccountQueryService.GetAllBusinessAccounts(ctx)
+	if err != nil {
+		slog.Error("Business accounts could not be read", "error", err)
+		return 1
+	}
+
+	var seededBusinessAccounts []*domain.BusinessAccount
+	for _, account := range allBusinessAccounts {
+		if employerIDs[account.UserID.Hex()] {
+			seededBusinessAccounts = append(seededBusinessAccounts, account)
+		}
+	}
+
+	// jobs
+	for i := 0; i < *jobs && len(seededBusinessAccounts) > 0 && ctx.Err() == nil; i++ {
+		account := seededBusinessAccounts[seeder.random.Intn(len(seededBusinessAccounts))]
+		seedJob := seedJobs[seeder.random.Intn(len(seedJobs))]
+
+		command := job.Command{
+			BusinessAccountID: account.Id.Hex(),
+			Name:              seedJob.name,
+			Description:       fmt.Sprintf("%s is looking for a %s to join the team.", account.Name, strings.ToLower(seedJob.name)),
+			Price:             float32(int(seedJob.minPrice + seeder.random.Float32()*(seedJob.maxPrice-seedJob.minPrice))),
+			Category:          seedJob.category,
+		}
+
+		if err := jobCommandHandler.Save(ctx, command, account.UserID.Hex()); err != nil {
+			slog.Error("Job could not be seeded", "error", err)
+			return 1
+		}
+	}
+
+	seededBusinessAccountIDs := make(map[string]bool, len(seededBusinessAccounts))
+	for _, account := range seededBusinessAccounts {
+		seededBusinessAccountIDs[account.Id.Hex()] = true
+	}
+
+	allJobs, err := jobQueryService.GetAllJobs(ctx)
+	if err != nil {
+		slog.Error("Jobs could not be read", "error", err)
+		return 1
+	}
+
+	var seededJobs []*domain.Job
+	for _, j := range allJobs {
+		if seededBusinessAccountIDs[j.BusinessAccountID.Hex()] {
+			seededJobs = append(seededJobs, j)
+		}
+	}
+
+	// candidates and their applications
+	var candidateIDs []string
+	for i := 0; i < *candidates && ctx.Err() == nil; i++ {
+		userID, err := seeder.user(ctx, domain.RoleCandidate)
+		if err != nil {
+			slog.Error("Candidate could not be seeded", "error", err)
+			return 1
+		}
+
+		candidateIDs = append(candidateIDs, userID)
+	}
+
+	seededApplications := 0
+	applied := make(map[string]bool)
+	for i := 0; i < *applications && len(candidateIDs) > 0 && len(seededJobs) > 0 && ctx.Err() == nil; i++ {
+		candidateID := candidateIDs[seeder.random.Intn(len(candidateIDs))]
+		j := seededJobs[seeder.random.Intn(len(seededJobs))]
+
+		if applied[candidateID+j.Id.Hex()] {
+			continue
+		}
+
+		// the handler applies as the signed-in user, like behind the jwt middleware
+		candidateCtx := context.WithValue(ctx, "user", &utils.UserContext{UserID: candidateID, Roles: []string{string(domain.RoleCandidate)}})
+
+		if err := jobApplyCommandHandler.Save(candidateCtx, jobApply.Command{JobID: j.Id.Hex(), BusinessAccountID: j.BusinessAccountID.Hex()}); err != nil {
+			slog.Error("Application could not be seeded", "error", err)
+			return 1
+		}
+
+		applied[candidateID+j.Id.Hex()] = true
+		seededApplications++
+	}
+
+	if err := ctx.Err(); err != nil {
+		slog.Error("Seeding interrupted", "error", err)
+		return 1
+	}
+
+	slog.Info("Seeded", "employers", len(employerIDs), "business_accounts", len(seededBusinessAccounts), "jobs", len(seededJobs),
+		"candidates", len(candidateIDs), "applications", seededApplications)
+	fmt.Printf("seeded accounts sign in with the password: %s\n", *password)
+
+	return 0
+}
+
+type seeder struct {
+	random             *rand.Rand
+	password           string
+	userCommandHandler user.ICommandHandler
+}
+
+// user signs up a fake user with role, candidates are made by sign-up already.
+func (s *seeder) user(ctx context.Context, role domain.Role) (string, error) {
+	firstName := s.pick(seedFirstNames)
+	lastName := s.pick(seedLastNames)
+
+	command := user.Command{
+		FirstName: firstName,
+		LastName:  lastName,
+		// the suffix keeps emails unique across runs with different seeds
+		Email:    fmt.Sprintf("%s.%s.%06d@example.com", strings.ToLower(firstName), strings.ToLower(lastName), s.random.Intn(1000000)),
+		Password: s.password,
+		Age:      int32(18 + s.random.Intn(45)),
+	}
+
+	userID, err := s.userCommandHandler.Save(ctx, command)
+	if err != nil {
+		return "", err
+	}
+
+	if role != domain.RoleCandidate {
+		if err := s.userCommandHandler.GrantRole(ctx, userID, role); err != nil {
+			return "", err
+		}
+	}
+
+	return userID, nil
+}
+
+func (s *seeder) companyName() string {
+	return s.pick(seedCompanyWords) + " " + s.pick(seedCompanyTrades)
+}
+
+func (s *seeder) pick(values []string) string {
+	return values[s.random.Intn(len(values))]
+}